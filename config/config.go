@@ -14,11 +14,30 @@ type Config struct {
 	DBName       string `mapstructure:"DB_NAME"`
 	MigrationURL string `mapstructure:"MIGRATION_URL"`
 
-	GRPCServer string `mapstructure:"GRPC_SERVER"`
+	GRPCServer     string `mapstructure:"GRPC_SERVER"`
+	TrustedProxies string `mapstructure:"TRUSTED_PROXIES"`
 
+	TokenMaker           string        `mapstructure:"TOKEN_MAKER"`
 	TokenSymmetricKey    string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
 	AccessTokenDuration  time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
 	RefreshTokenDuration time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	SessionDuration      time.Duration `mapstructure:"SESSION_DURATION"`
+
+	MaxSessionsPerUser   int           `mapstructure:"MAX_SESSIONS_PER_USER"`
+	SessionTouchInterval time.Duration `mapstructure:"SESSION_TOUCH_INTERVAL"`
+
+	LoginRatePerSecond float64 `mapstructure:"LOGIN_RATE_PER_SECOND"`
+	LoginRateBurst     int     `mapstructure:"LOGIN_RATE_BURST"`
+
+	LockoutThreshold   int           `mapstructure:"LOCKOUT_THRESHOLD"`
+	LockoutDuration    time.Duration `mapstructure:"LOCKOUT_DURATION"`
+	LockoutMaxDuration time.Duration `mapstructure:"LOCKOUT_MAX_DURATION"`
+
+	PasswordMaxAge  time.Duration `mapstructure:"PASSWORD_MAX_AGE"`
+	PasswordCharset string        `mapstructure:"PASSWORD_CHARSET"`
+
+	AdminName     string `mapstructure:"ADMIN_NAME"`
+	AdminPassword string `mapstructure:"ADMIN_PASSWORD"`
 }
 
 func LoadConfig(path string) (config Config, err error) {
@@ -0,0 +1,90 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"github.com/azusaanson/invest-api/domain"
+)
+
+// ErrCanonicalNameCollision means existing users have names that only differ
+// in case or encoding. They must be renamed by hand before the backfill can
+// finish; the error lists the ids of each group.
+var ErrCanonicalNameCollision = errors.New("backfill: user names collide after canonicalization")
+
+// BackfillCanonicalNames sets canonical_name for users that do not have one
+// yet, soft deleted ones included, using the same UserName.Canonical as
+// lookups. It is a no-op once every user has one, so it can run on every
+// boot. On a collision nothing is written.
+func BackfillCanonicalNames(ctx context.Context, conn *gorm.DB) (int, error) {
+	var filled int
+
+	err := conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var pending []User
+		if err := tx.Unscoped().
+			Select("id", "name").
+			Where("canonical_name IS NULL").
+			Order("id").
+			Find(&pending).Error; err != nil {
+			return errors.WithStack(err)
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+
+		var existing []User
+		if err := tx.Unscoped().
+			Select("id", "canonical_name").
+			Where("canonical_name IS NOT NULL").
+			Find(&existing).Error; err != nil {
+			return errors.WithStack(err)
+		}
+
+		owners := make(map[string][]uint64, len(existing)+len(pending))
+		for _, user := range existing {
+			owners[user.CanonicalName] = append(owners[user.CanonicalName], user.ID)
+		}
+		for _, user := range pending {
+			canonical := domain.UserName(user.Name).Canonical()
+			owners[canonical] = append(owners[canonical], user.ID)
+		}
+
+		if collisions := describeCollisions(owners); collisions != "" {
+			return errors.Wrap(ErrCanonicalNameCollision, collisions)
+		}
+
+		for _, user := range pending {
+			if err := tx.Unscoped().
+				Model(&User{}).
+				Where("id = ?", user.ID).
+				Update("canonical_name", domain.UserName(user.Name).Canonical()).Error; err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		filled = len(pending)
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return filled, nil
+}
+
+func describeCollisions(owners map[string][]uint64) string {
+	var groups []string
+	for canonical, ids := range owners {
+		if len(ids) > 1 {
+			groups = append(groups, fmt.Sprintf("%q: user ids %v", canonical, ids))
+		}
+	}
+	sort.Strings(groups)
+
+	return strings.Join(groups, "; ")
+}
@@ -0,0 +1,104 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDescribeCollisions(t *testing.T) {
+	tests := []struct {
+		name   string
+		owners map[string][]uint64
+		want   string
+	}{
+		{name: "none", owners: map[string][]uint64{"alice": {1}, "bob": {2}}, want: ""},
+		{name: "one group", owners: map[string][]uint64{"alice": {1, 3}, "bob": {2}}, want: `"alice": user ids [1 3]`},
+		{name: "sorted groups", owners: map[string][]uint64{"bob": {2, 4}, "alice": {1, 3}}, want: `"alice": user ids [1 3]; "bob": user ids [2 4]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describeCollisions(tt.owners); got != tt.want {
+				t.Fatalf("describeCollisions() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackfillCanonicalNames(t *testing.T) {
+	store := requireStore(t)
+	ctx := context.Background()
+
+	canonicalName := func(t *testing.T, id interface{}) *string {
+		t.Helper()
+
+		var canonical *string
+		if err := testConn.Unscoped().Model(&User{}).Select("canonical_name").Where("id = ?", id).Row().Scan(&canonical); err != nil {
+			t.Fatalf("select canonical_name = %v", err)
+		}
+
+		return canonical
+	}
+	clearCanonicalName := func(t *testing.T, id interface{}, name string) {
+		t.Helper()
+
+		if err := testConn.Unscoped().Model(&User{}).Where("id = ?", id).
+			Updates(map[string]interface{}{"name": name, "canonical_name": nil}).Error; err != nil {
+			t.Fatalf("clear canonical_name = %v", err)
+		}
+	}
+
+	t.Run("fills missing names", func(t *testing.T) {
+		user := createTestUser(t, store)
+		mixedCase := strings.ToUpper(string(user.Name()))
+		clearCanonicalName(t, user.ID(), mixedCase)
+
+		filled, err := BackfillCanonicalNames(ctx, testConn)
+		if err != nil {
+			t.Fatalf("BackfillCanonicalNames() = %v", err)
+		}
+		if filled < 1 {
+			t.Fatalf("BackfillCanonicalNames() = %d, want at least 1", filled)
+		}
+		if got := canonicalName(t, user.ID()); got == nil || *got != user.Name().Canonical() {
+			t.Fatalf("canonical_name = %v, want %q", got, user.Name().Canonical())
+		}
+
+		again, err := BackfillCanonicalNames(ctx, testConn)
+		if err != nil || again != 0 {
+			t.Fatalf("second BackfillCanonicalNames() = (%d, %v), want (0, nil)", again, err)
+		}
+	})
+
+	t.Run("collision writes nothing", func(t *testing.T) {
+		user := createTestUser(t, store)
+		other := createTestUser(t, store)
+		third := createTestUser(t, store)
+		t.Cleanup(func() {
+			testConn.Unscoped().Where("id = ?", other.ID()).Delete(&User{})
+		})
+
+		// other collides with user; third is fine but must not be filled
+		// either, since the backfill runs in one transaction.
+		clearCanonicalName(t, other.ID(), strings.ToUpper(string(user.Name())))
+		clearCanonicalName(t, third.ID(), string(third.Name()))
+
+		_, err := BackfillCanonicalNames(ctx, testConn)
+		if !errors.Is(err, ErrCanonicalNameCollision) {
+			t.Fatalf("BackfillCanonicalNames() = %v, want %v", err, ErrCanonicalNameCollision)
+		}
+		if got := canonicalName(t, other.ID()); got != nil {
+			t.Fatalf("canonical_name of the colliding user = %q, want NULL", *got)
+		}
+		if got := canonicalName(t, third.ID()); got != nil {
+			t.Fatalf("canonical_name of the other pending user = %q, want NULL", *got)
+		}
+
+		testConn.Unscoped().Where("id = ?", other.ID()).Delete(&User{})
+		if _, err := BackfillCanonicalNames(ctx, testConn); err != nil {
+			t.Fatalf("BackfillCanonicalNames() after removing the collision = %v", err)
+		}
+	})
+}
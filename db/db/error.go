@@ -3,10 +3,40 @@ package db
 import (
 	"fmt"
 
+	"github.com/go-sql-driver/mysql"
+	"github.com/pkg/errors"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
+// Getters return these when no row matches, wrapped with a stack trace; check
+// them with errors.Is.
+var (
+	ErrUserNotFound    = errors.New("not found: user")
+	ErrSessionNotFound = errors.New("not found: session")
+	ErrInvestNotFound  = errors.New("not found: invest")
+)
+
+// ErrUserNameTaken means another user already has a name with the same
+// canonical form.
+var ErrUserNameTaken = errors.New("duplicate: user name")
+
+// mysqlErrDupEntry is ER_DUP_ENTRY, raised on a unique index violation.
+const mysqlErrDupEntry = 1062
+
+func isDuplicateKeyError(err error) bool {
+	var mysqlErr *mysql.MySQLError
+
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDupEntry
+}
+
+// ErrSameUser means an operation between two users got the same user twice.
+var ErrSameUser = errors.New("same user")
+
+// ErrConcurrentModification means the record changed since it was read; the
+// caller should re-read it and retry.
+var ErrConcurrentModification = errors.New("concurrent modification")
+
 /*
 DEADLINE_EXCEEDED = 4
 UNIMPLEMENTED = 12
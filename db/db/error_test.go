@@ -0,0 +1,31 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestIsDuplicateKeyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "duplicate entry", err: &mysql.MySQLError{Number: mysqlErrDupEntry}, want: true},
+		{name: "wrapped duplicate entry", err: fmt.Errorf("create user: %w", &mysql.MySQLError{Number: mysqlErrDupEntry}), want: true},
+		{name: "other mysql error", err: &mysql.MySQLError{Number: 1452}, want: false},
+		{name: "other error", err: errors.New("duplicate entry"), want: false},
+		{name: "nil", err: nil, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isDuplicateKeyError(tt.err); got != tt.want {
+				t.Fatalf("isDuplicateKeyError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
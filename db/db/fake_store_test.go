@@ -0,0 +1,88 @@
+package db
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/azusaanson/invest-api/domain"
+)
+
+// fakeUserQueries keeps users in memory. Methods the tests do not need are
+// left to the embedded nil interface and panic if called.
+type fakeUserQueries struct {
+	UserQueries
+
+	mu      sync.Mutex
+	users   map[domain.UserID]*domain.User
+	nextID  domain.UserID
+	creates int
+}
+
+func newFakeUserQueries() *fakeUserQueries {
+	return &fakeUserQueries{users: map[domain.UserID]*domain.User{}, nextID: 1}
+}
+
+func (f *fakeUserQueries) GetUserByID(
+	_ context.Context,
+	userID domain.UserID,
+	_ ...QueryOption,
+) (*domain.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	user, ok := f.users[userID]
+	if !ok {
+		return nil, errors.WithStack(ErrUserNotFound)
+	}
+
+	return user, nil
+}
+
+func (f *fakeUserQueries) GetUserByName(
+	_ context.Context,
+	name domain.UserName,
+	_ ...QueryOption,
+) (*domain.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, user := range f.users {
+		if user.Name().EqualFold(name) {
+			return user, nil
+		}
+	}
+
+	return nil, errors.WithStack(ErrUserNotFound)
+}
+
+func (f *fakeUserQueries) CreateUser(_ context.Context, user *domain.User) (domain.UserID, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, existing := range f.users {
+		if existing.Name().EqualFold(user.Name()) {
+			return 0, errors.WithStack(ErrUserNameTaken)
+		}
+	}
+
+	id := f.nextID
+	f.nextID++
+
+	saved, err := domain.NewUserFromSource(
+		uint64(id),
+		string(user.Name()),
+		string(user.HashedPassword()),
+		string(user.Role()),
+		user.PasswordChangedAt(),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	f.users[id] = saved
+	f.creates++
+
+	return id, nil
+}
@@ -0,0 +1,29 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+var ErrDBUnavailable = errors.New("db: unavailable")
+
+// PingDB runs SELECT 1 within the deadline of ctx, for readiness probes.
+func PingDB(ctx context.Context, conn *sql.DB) error {
+	var one int
+	if err := conn.QueryRowContext(ctx, "SELECT 1").Scan(&one); err != nil {
+		return errors.Wrap(ErrDBUnavailable, err.Error())
+	}
+
+	return nil
+}
+
+func (s *Store) Ping(ctx context.Context) error {
+	conn, err := s.conn.DB()
+	if err != nil {
+		return errors.Wrap(ErrDBUnavailable, err.Error())
+	}
+
+	return PingDB(ctx, conn)
+}
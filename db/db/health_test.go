@@ -0,0 +1,69 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func TestPingDB(t *testing.T) {
+	newConn := func(t *testing.T) *sql.DB {
+		t.Helper()
+
+		// sql.Open only validates the DSN; nothing is dialed until a query.
+		conn, err := sql.Open("mysql", "invest:invest@tcp(127.0.0.1:1)/invest?timeout=2s")
+		if err != nil {
+			t.Fatalf("sql.Open() = %v", err)
+		}
+		t.Cleanup(func() { conn.Close() })
+
+		return conn
+	}
+
+	tests := []struct {
+		name string
+		conn func(t *testing.T) *sql.DB
+		ctx  func() context.Context
+	}{
+		{
+			name: "closed db",
+			conn: func(t *testing.T) *sql.DB {
+				conn := newConn(t)
+				conn.Close()
+				return conn
+			},
+			ctx: context.Background,
+		},
+		{
+			name: "cancelled context",
+			conn: newConn,
+			ctx: func() context.Context {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx
+			},
+		},
+		{
+			name: "unreachable db",
+			conn: newConn,
+			ctx:  context.Background,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := PingDB(tt.ctx(), tt.conn(t)); !errors.Is(err, ErrDBUnavailable) {
+				t.Fatalf("PingDB() = %v, want %v", err, ErrDBUnavailable)
+			}
+		})
+	}
+}
+
+func TestStorePing(t *testing.T) {
+	store := requireStore(t)
+
+	if err := store.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping() = %v, want nil", err)
+	}
+}
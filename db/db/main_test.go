@@ -0,0 +1,99 @@
+package db
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"testing"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+
+	"github.com/azusaanson/invest-api/config"
+	"github.com/azusaanson/invest-api/domain"
+)
+
+// Tests against the store need the MySQL from config/app.env with the
+// migrations applied (make mysql migrate_up). They are skipped without it,
+// unless TEST_REQUIRE_MYSQL is set as in CI, where they fail instead.
+var (
+	testConn    *gorm.DB
+	testStore   StoreInterface
+	testConnErr error
+)
+
+func TestMain(m *testing.M) {
+	testConn, testConnErr = openTestConn()
+	if testConnErr == nil {
+		testStore = NewStore(testConn)
+	}
+
+	os.Exit(m.Run())
+}
+
+func openTestConn() (*gorm.DB, error) {
+	config, err := config.LoadConfig("../../config")
+	if err != nil {
+		return nil, err
+	}
+
+	dbSource := config.DBUser + ":" + config.DBPassword + "@tcp(" + config.DBHost + ":" + config.DBPort + ")/" + config.DBName
+
+	return gorm.Open(mysql.Open(dbSource+"?charset=utf8&parseTime=True&loc=Local&timeout=2s"), &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{
+			SingularTable: true,
+		},
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+}
+
+func requireStore(t *testing.T) StoreInterface {
+	t.Helper()
+
+	if testConnErr != nil {
+		if os.Getenv("TEST_REQUIRE_MYSQL") != "" {
+			t.Fatalf("mysql is not available: %v", testConnErr)
+		}
+		t.Skipf("mysql is not available: %v", testConnErr)
+	}
+
+	return testStore
+}
+
+// createTestUser stores a user with a random name, so tests do not depend on
+// each other or on what is already in the database.
+func createTestUser(t *testing.T, store StoreInterface) *domain.User {
+	t.Helper()
+
+	user, err := domain.NewUserForCreate(randomTestUserName(t), "Tx7!qLmZ", string(domain.RoleUser), domain.DefaultPasswordPolicy)
+	if err != nil {
+		t.Fatalf("NewUserForCreate() = %v", err)
+	}
+
+	userID, err := store.CreateUser(context.Background(), user)
+	if err != nil {
+		t.Fatalf("CreateUser() = %v", err)
+	}
+
+	created, err := store.GetUserByID(context.Background(), userID)
+	if err != nil {
+		t.Fatalf("GetUserByID() = %v", err)
+	}
+
+	return created
+}
+
+func randomTestUserName(t *testing.T) string {
+	t.Helper()
+
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatalf("rand.Read() = %v", err)
+	}
+
+	return fmt.Sprintf("test_%s", hex.EncodeToString(b))
+}
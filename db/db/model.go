@@ -2,36 +2,55 @@ package db
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
+// BaseModel soft deletes through DeletedAt: gorm turns Delete into an update
+// of deleted_at and leaves deleted rows out of queries unless IncludeDeleted
+// is passed.
 type BaseModel struct {
 	ID        uint64
 	UpdatedAt time.Time
 	CreatedAt time.Time
+	DeletedAt gorm.DeletedAt
+}
+
+func (m BaseModel) IsDeleted() bool {
+	return m.DeletedAt.Valid
 }
 
 type Session struct {
 	BaseModel
-	UUID         string
-	UserID       uint64
-	RefreshToken string
-	UserAgent    string
-	ClientIp     string
-	IsBlocked    bool
-	ExpiresAt    time.Time
+	UUID             string
+	UserID           uint64
+	RefreshTokenHash string
+	UserAgent        string
+	ClientIp         string
+	IsBlocked        bool
+	IsRotated        bool
+	ExpiresAt        time.Time
+	LastSeenAt       time.Time
 }
 
 type User struct {
 	BaseModel
-	Name     string
-	Password string
-	Role     string
+	Name          string
+	CanonicalName string
+	Password      string
+	Role          string
+
+	PasswordChangedAt time.Time
 }
 
+// Invest is the only record with a version, as UpdateInvest is the only
+// update that checks it.
 type Invest struct {
 	BaseModel
+	Version    uint64
 	UserID     uint64
-	Amount     float64
+	Amount     string
+	Currency   string
 	Type       string
 	InvestedAt time.Time
 }
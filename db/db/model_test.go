@@ -0,0 +1,27 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestBaseModelIsDeleted(t *testing.T) {
+	tests := []struct {
+		name      string
+		deletedAt gorm.DeletedAt
+		want      bool
+	}{
+		{name: "not deleted", deletedAt: gorm.DeletedAt{}, want: false},
+		{name: "deleted", deletedAt: gorm.DeletedAt{Time: time.Now(), Valid: true}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (BaseModel{DeletedAt: tt.deletedAt}).IsDeleted(); got != tt.want {
+				t.Fatalf("IsDeleted() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
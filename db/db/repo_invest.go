@@ -0,0 +1,409 @@
+package db
+
+import (
+	"context"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/azusaanson/invest-api/domain"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"gorm.io/gorm"
+)
+
+type InvestQueries interface {
+	GetInvestByID(ctx context.Context, investID domain.InvestID, opts ...QueryOption) (*domain.Invest, error)
+	ListInvestsByUserID(ctx context.Context, userID domain.UserID, filter InvestFilter, page Pagination) ([]*domain.Invest, int, error)
+	ListInvestsByUserIDCursor(ctx context.Context, userID domain.UserID, filter InvestFilter, cursor Cursor, limit int) ([]*domain.Invest, Cursor, error)
+	CreateInvest(ctx context.Context, invest *domain.Invest) (domain.InvestID, error)
+	CreateInvestBatch(ctx context.Context, userID domain.UserID, invests []*domain.Invest) ([]domain.InvestID, error)
+	UpdateInvest(ctx context.Context, invest *domain.Invest) error
+	ReassignInvests(ctx context.Context, fromUser domain.UserID, toUser domain.UserID) (int, error)
+	DeleteInvest(ctx context.Context, investID domain.InvestID) error
+	RestoreInvest(ctx context.Context, investID domain.InvestID) error
+}
+
+// InvestFilter narrows a listing. Nil fields are not filtered on; the date
+// range is inclusive on both ends.
+type InvestFilter struct {
+	Type           *domain.InvestType
+	From           *time.Time
+	To             *time.Time
+	IncludeDeleted bool
+}
+
+const (
+	PaginationDefaultLimit = 20
+	PaginationMaxLimit     = 100
+)
+
+type Pagination struct {
+	limit  int
+	offset int
+}
+
+func (p Pagination) Limit() int  { return p.limit }
+func (p Pagination) Offset() int { return p.offset }
+
+// NewPagination falls back to the default for a non-positive limit, caps it
+// at PaginationMaxLimit and clamps a negative offset to zero.
+func NewPagination(limit int, offset int) Pagination {
+	if limit <= 0 {
+		limit = PaginationDefaultLimit
+	}
+	if limit > PaginationMaxLimit {
+		limit = PaginationMaxLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	return Pagination{limit: limit, offset: offset}
+}
+
+var ErrCursorInvalid = errors.New("cursor: invalid")
+
+// Cursor is an opaque position in a listing ordered by (invested_at, id). The
+// empty cursor is the start of the listing and is also returned once the
+// listing is exhausted.
+type Cursor string
+
+func newCursor(investedAt time.Time, id uint64) Cursor {
+	raw := strconv.FormatInt(investedAt.UnixNano(), 10) + ":" + strconv.FormatUint(id, 10)
+
+	return Cursor(base64.RawURLEncoding.EncodeToString([]byte(raw)))
+}
+
+func (c Cursor) decode() (time.Time, uint64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(string(c))
+	if err != nil {
+		return time.Time{}, 0, errors.Wrap(ErrCursorInvalid, err.Error())
+	}
+
+	rawInvestedAt, rawID, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return time.Time{}, 0, errors.WithStack(ErrCursorInvalid)
+	}
+
+	investedAt, err := strconv.ParseInt(rawInvestedAt, 10, 64)
+	if err != nil {
+		return time.Time{}, 0, errors.Wrap(ErrCursorInvalid, err.Error())
+	}
+
+	id, err := strconv.ParseUint(rawID, 10, 64)
+	if err != nil {
+		return time.Time{}, 0, errors.Wrap(ErrCursorInvalid, err.Error())
+	}
+
+	return time.Unix(0, investedAt), id, nil
+}
+
+func (s *Store) ListInvestsByUserID(
+	ctx context.Context,
+	userID domain.UserID,
+	filter InvestFilter,
+	page Pagination,
+) ([]*domain.Invest, int, error) {
+	var total int64
+	err := filter.apply(s.db(ctx).Model(&Invest{}).Where("user_id = ?", userID)).
+		Count(&total).Error
+	if err != nil {
+		return nil, 0, errors.WithStack(err)
+	}
+
+	records := []*Invest{}
+	err = filter.apply(s.db(ctx).Model(&Invest{}).Where("user_id = ?", userID)).
+		Order("invested_at DESC, id DESC").
+		Limit(page.Limit()).
+		Offset(page.Offset()).
+		Find(&records).Error
+	if err != nil {
+		return nil, 0, errors.WithStack(err)
+	}
+
+	invests := make([]*domain.Invest, 0, len(records))
+	for _, record := range records {
+		invest, err := toInvestDomain(record)
+		if err != nil {
+			return nil, 0, errorWithStatus(codes.DataLoss, err)
+		}
+		invests = append(invests, invest)
+	}
+
+	return invests, int(total), nil
+}
+
+// ListInvestsByUserIDCursor pages by keyset rather than offset, so rows
+// inserted while paging neither shift nor repeat the rows already visited.
+func (s *Store) ListInvestsByUserIDCursor(
+	ctx context.Context,
+	userID domain.UserID,
+	filter InvestFilter,
+	cursor Cursor,
+	limit int,
+) ([]*domain.Invest, Cursor, error) {
+	limit = NewPagination(limit, 0).Limit()
+
+	query := filter.apply(s.db(ctx).Model(&Invest{}).Where("user_id = ?", userID))
+	if cursor != "" {
+		investedAt, id, err := cursor.decode()
+		if err != nil {
+			return nil, "", errors.WithStack(err)
+		}
+
+		query = query.Where(
+			"invested_at > ? OR (invested_at = ? AND id > ?)",
+			investedAt, investedAt, id,
+		)
+	}
+
+	records := []*Invest{}
+	err := query.
+		Order("invested_at ASC, id ASC").
+		Limit(limit + 1).
+		Find(&records).Error
+	if err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+
+	var next Cursor
+	if len(records) > limit {
+		records = records[:limit]
+		last := records[len(records)-1]
+		next = newCursor(last.InvestedAt, last.ID)
+	}
+
+	invests := make([]*domain.Invest, 0, len(records))
+	for _, record := range records {
+		invest, err := toInvestDomain(record)
+		if err != nil {
+			return nil, "", errorWithStatus(codes.DataLoss, err)
+		}
+		invests = append(invests, invest)
+	}
+
+	return invests, next, nil
+}
+
+func (s *Store) GetInvestByID(
+	ctx context.Context,
+	investID domain.InvestID,
+	opts ...QueryOption,
+) (*domain.Invest, error) {
+	record := &Invest{}
+
+	err := applyQueryOptions(s.db(ctx).Model(&Invest{}), opts).
+		Where("id = ?", investID).
+		First(record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.WithStack(ErrInvestNotFound)
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	invest, err := toInvestDomain(record)
+	if err != nil {
+		return nil, errorWithStatus(codes.DataLoss, err)
+	}
+	return invest, nil
+}
+
+func (s *Store) CreateInvest(
+	ctx context.Context,
+	invest *domain.Invest,
+) (domain.InvestID, error) {
+	record := toInvestRecord(invest)
+
+	if err := s.db(ctx).Create(record).Error; err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	investID, err := domain.NewInvestID(record.ID)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	return investID, nil
+}
+
+// InvestBatchSize keeps each multi-row INSERT well under the MySQL
+// placeholder limit.
+const InvestBatchSize = 500
+
+var ErrInvestBatchUserMismatch = errors.New("invest batch: invest does not belong to the user")
+
+// CreateInvestBatch inserts the invests in chunks within one transaction, so
+// a failure in any chunk leaves nothing inserted. The returned ids are in the
+// order of invests.
+func (s *Store) CreateInvestBatch(
+	ctx context.Context,
+	userID domain.UserID,
+	invests []*domain.Invest,
+) ([]domain.InvestID, error) {
+	records := make([]*Invest, 0, len(invests))
+	for _, invest := range invests {
+		if invest.UserID() != userID {
+			return nil, errors.WithStack(ErrInvestBatchUserMismatch)
+		}
+		records = append(records, toInvestRecord(invest))
+	}
+
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	err := s.db(ctx).Transaction(func(tx *gorm.DB) error {
+		return tx.CreateInBatches(records, InvestBatchSize).Error
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	investIDs := make([]domain.InvestID, 0, len(records))
+	for _, record := range records {
+		investID, err := domain.NewInvestID(record.ID)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		investIDs = append(investIDs, investID)
+	}
+
+	return investIDs, nil
+}
+
+// UpdateInvest applies only when the stored version still equals the version
+// the invest was read with, and bumps it.
+func (s *Store) UpdateInvest(
+	ctx context.Context,
+	invest *domain.Invest,
+) error {
+	result := s.db(ctx).
+		Model(&Invest{}).
+		Where("id = ? AND version = ?", invest.ID(), invest.Version()).
+		Updates(map[string]interface{}{
+			"amount":      invest.Amount().String(),
+			"currency":    invest.Currency(),
+			"type":        invest.Type(),
+			"invested_at": time.Time(invest.InvestedAt()),
+			"version":     gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return errors.WithStack(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.WithStack(ErrConcurrentModification)
+	}
+
+	return nil
+}
+
+// ReassignInvests moves every invest of fromUser to toUser in one statement,
+// soft deleted ones included so they can still be restored afterwards. It
+// bumps their versions, so stale copies fail to update.
+func (s *Store) ReassignInvests(
+	ctx context.Context,
+	fromUser domain.UserID,
+	toUser domain.UserID,
+) (int, error) {
+	if fromUser == toUser {
+		return 0, errors.WithStack(ErrSameUser)
+	}
+
+	var affected int64
+	err := s.db(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.
+			Unscoped().
+			Model(&Invest{}).
+			Where("user_id = ?", fromUser).
+			Updates(map[string]interface{}{
+				"user_id": toUser,
+				"version": gorm.Expr("version + 1"),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		affected = result.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	return int(affected), nil
+}
+
+// DeleteInvest soft deletes the invest; RestoreInvest undoes it.
+func (s *Store) DeleteInvest(
+	ctx context.Context,
+	investID domain.InvestID,
+) error {
+	err := s.db(ctx).
+		Where("id = ?", investID).
+		Delete(&Invest{}).Error
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+func (s *Store) RestoreInvest(
+	ctx context.Context,
+	investID domain.InvestID,
+) error {
+	err := s.db(ctx).
+		Unscoped().
+		Model(&Invest{}).
+		Where("id = ?", investID).
+		Update("deleted_at", nil).Error
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+func (f InvestFilter) apply(query *gorm.DB) *gorm.DB {
+	if f.IncludeDeleted {
+		query = query.Unscoped()
+	}
+	if f.Type != nil {
+		query = query.Where("type = ?", *f.Type)
+	}
+	if f.From != nil {
+		query = query.Where("invested_at >= ?", *f.From)
+	}
+	if f.To != nil {
+		query = query.Where("invested_at <= ?", *f.To)
+	}
+
+	return query
+}
+
+func toInvestRecord(invest *domain.Invest) *Invest {
+	return &Invest{
+		BaseModel:  BaseModel{ID: uint64(invest.ID())},
+		Version:    uint64(invest.Version()),
+		UserID:     uint64(invest.UserID()),
+		Amount:     invest.Amount().String(),
+		Currency:   string(invest.Currency()),
+		Type:       string(invest.Type()),
+		InvestedAt: time.Time(invest.InvestedAt()),
+	}
+}
+
+func toInvestDomain(record *Invest) (*domain.Invest, error) {
+	return domain.NewInvestFromSource(
+		record.ID,
+		record.UserID,
+		record.Amount,
+		record.Currency,
+		record.Type,
+		record.InvestedAt,
+		record.Version,
+	)
+}
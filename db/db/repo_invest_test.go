@@ -0,0 +1,474 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/azusaanson/invest-api/domain"
+)
+
+func createTestInvest(
+	t *testing.T,
+	store StoreInterface,
+	userID domain.UserID,
+	investType domain.InvestType,
+	investedAt time.Time,
+) domain.InvestID {
+	t.Helper()
+
+	newInvestedAt, err := domain.NewInvestedAt(investedAt)
+	if err != nil {
+		t.Fatalf("NewInvestedAt() = %v", err)
+	}
+
+	invest, err := domain.NewInvest(userID, domain.NewAmountFromMinorUnits(100_00, domain.CurrencyUSD), investType, newInvestedAt)
+	if err != nil {
+		t.Fatalf("NewInvest() = %v", err)
+	}
+
+	investID, err := store.CreateInvest(context.Background(), invest)
+	if err != nil {
+		t.Fatalf("CreateInvest() = %v", err)
+	}
+
+	return investID
+}
+
+func TestNewPagination(t *testing.T) {
+	tests := []struct {
+		name       string
+		limit      int
+		offset     int
+		wantLimit  int
+		wantOffset int
+	}{
+		{name: "as given", limit: 10, offset: 30, wantLimit: 10, wantOffset: 30},
+		{name: "zero limit", limit: 0, offset: 0, wantLimit: PaginationDefaultLimit, wantOffset: 0},
+		{name: "negative limit", limit: -5, offset: 0, wantLimit: PaginationDefaultLimit, wantOffset: 0},
+		{name: "at the cap", limit: PaginationMaxLimit, offset: 0, wantLimit: PaginationMaxLimit, wantOffset: 0},
+		{name: "above the cap", limit: PaginationMaxLimit + 1, offset: 0, wantLimit: PaginationMaxLimit, wantOffset: 0},
+		{name: "negative offset", limit: 10, offset: -1, wantLimit: 10, wantOffset: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewPagination(tt.limit, tt.offset)
+			if got.Limit() != tt.wantLimit || got.Offset() != tt.wantOffset {
+				t.Fatalf("NewPagination(%d, %d) = %d, %d, want %d, %d", tt.limit, tt.offset, got.Limit(), got.Offset(), tt.wantLimit, tt.wantOffset)
+			}
+		})
+	}
+}
+
+func TestListInvestsByUserID(t *testing.T) {
+	store := requireStore(t)
+	ctx := context.Background()
+	user := createTestUser(t, store)
+
+	day := func(n int) time.Time {
+		return time.Date(2023, time.January, n, 12, 0, 0, 0, time.Local)
+	}
+	stock, bond := domain.InvestTypeStock, domain.InvestTypeBond
+
+	jan1 := createTestInvest(t, store, user.ID(), stock, day(1))
+	jan5 := createTestInvest(t, store, user.ID(), stock, day(5))
+	createTestInvest(t, store, user.ID(), bond, day(6))
+	jan10 := createTestInvest(t, store, user.ID(), stock, day(10))
+	createTestInvest(t, store, user.ID(), stock, day(20))
+
+	from, to := day(1), day(10)
+
+	tests := []struct {
+		name      string
+		filter    InvestFilter
+		page      Pagination
+		wantIDs   []domain.InvestID
+		wantTotal int
+	}{
+		{
+			name:      "type and date range",
+			filter:    InvestFilter{Type: &stock, From: &from, To: &to},
+			page:      NewPagination(10, 0),
+			wantIDs:   []domain.InvestID{jan10, jan5, jan1},
+			wantTotal: 3,
+		},
+		{
+			name:      "second page",
+			filter:    InvestFilter{Type: &stock, From: &from, To: &to},
+			page:      NewPagination(2, 2),
+			wantIDs:   []domain.InvestID{jan1},
+			wantTotal: 3,
+		},
+		{
+			name:      "no filter",
+			filter:    InvestFilter{},
+			page:      NewPagination(1, 0),
+			wantIDs:   nil,
+			wantTotal: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invests, total, err := store.ListInvestsByUserID(ctx, user.ID(), tt.filter, tt.page)
+			if err != nil {
+				t.Fatalf("ListInvestsByUserID() = %v", err)
+			}
+			if total != tt.wantTotal {
+				t.Fatalf("ListInvestsByUserID() total = %d, want %d", total, tt.wantTotal)
+			}
+			if tt.wantIDs == nil {
+				if len(invests) != tt.page.Limit() {
+					t.Fatalf("ListInvestsByUserID() returned %d invests, want %d", len(invests), tt.page.Limit())
+				}
+				return
+			}
+
+			if len(invests) != len(tt.wantIDs) {
+				t.Fatalf("ListInvestsByUserID() returned %d invests, want %d", len(invests), len(tt.wantIDs))
+			}
+			for i, invest := range invests {
+				if invest.ID() != tt.wantIDs[i] {
+					t.Fatalf("ListInvestsByUserID()[%d] = %d, want %d", i, invest.ID(), tt.wantIDs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCursorDecode(t *testing.T) {
+	investedAt := time.Date(2023, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		cursor  Cursor
+		wantErr error
+	}{
+		{name: "round trip", cursor: newCursor(investedAt, 42), wantErr: nil},
+		{name: "not base64", cursor: "!!!", wantErr: ErrCursorInvalid},
+		{name: "no separator", cursor: Cursor("MTIz"), wantErr: ErrCursorInvalid},
+		{name: "bad id", cursor: Cursor("MTIzOmFiYw"), wantErr: ErrCursorInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotInvestedAt, gotID, err := tt.cursor.decode()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("decode() = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (!gotInvestedAt.Equal(investedAt) || gotID != 42) {
+				t.Fatalf("decode() = %v, %d, want %v, 42", gotInvestedAt, gotID, investedAt)
+			}
+		})
+	}
+}
+
+func TestListInvestsByUserIDCursor(t *testing.T) {
+	store := requireStore(t)
+	ctx := context.Background()
+	user := createTestUser(t, store)
+
+	day := func(n int) time.Time {
+		return time.Date(2023, time.February, n, 12, 0, 0, 0, time.Local)
+	}
+
+	want := map[domain.InvestID]bool{}
+	for _, n := range []int{1, 2, 3, 3, 4} {
+		want[createTestInvest(t, store, user.ID(), domain.InvestTypeStock, day(n))] = true
+	}
+
+	visited := map[domain.InvestID]int{}
+	var cursor Cursor
+	for pages := 0; ; pages++ {
+		if pages > len(want) {
+			t.Fatal("paging did not terminate")
+		}
+
+		invests, next, err := store.ListInvestsByUserIDCursor(ctx, user.ID(), InvestFilter{}, cursor, 2)
+		if err != nil {
+			t.Fatalf("ListInvestsByUserIDCursor() = %v", err)
+		}
+		for _, invest := range invests {
+			visited[invest.ID()]++
+		}
+
+		// A row inserted before the current position must not shift the
+		// remaining pages.
+		if pages == 0 {
+			createTestInvest(t, store, user.ID(), domain.InvestTypeStock, day(1).Add(-time.Hour))
+		}
+
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	for id := range want {
+		if visited[id] != 1 {
+			t.Fatalf("invest %d visited %d times, want once", id, visited[id])
+		}
+	}
+	if len(visited) != len(want) {
+		t.Fatalf("visited %d invests, want %d", len(visited), len(want))
+	}
+}
+
+func TestDeleteInvest(t *testing.T) {
+	store := requireStore(t)
+	ctx := context.Background()
+	user := createTestUser(t, store)
+	investID := createTestInvest(t, store, user.ID(), domain.InvestTypeStock, time.Now().Add(-time.Hour))
+
+	if err := store.DeleteInvest(ctx, investID); err != nil {
+		t.Fatalf("DeleteInvest() = %v", err)
+	}
+
+	if _, err := store.GetInvestByID(ctx, investID); !errors.Is(err, ErrInvestNotFound) {
+		t.Fatalf("GetInvestByID() = %v, want %v", err, ErrInvestNotFound)
+	}
+	if _, err := store.GetInvestByID(ctx, investID, IncludeDeleted()); err != nil {
+		t.Fatalf("GetInvestByID(IncludeDeleted()) = %v", err)
+	}
+	for _, filter := range []InvestFilter{{}, {IncludeDeleted: true}} {
+		_, total, err := store.ListInvestsByUserID(ctx, user.ID(), filter, NewPagination(10, 0))
+		if err != nil {
+			t.Fatalf("ListInvestsByUserID() = %v", err)
+		}
+		if want := map[bool]int{false: 0, true: 1}[filter.IncludeDeleted]; total != want {
+			t.Fatalf("ListInvestsByUserID(IncludeDeleted: %v) total = %d, want %d", filter.IncludeDeleted, total, want)
+		}
+	}
+
+	if err := store.RestoreInvest(ctx, investID); err != nil {
+		t.Fatalf("RestoreInvest() = %v", err)
+	}
+	if _, err := store.GetInvestByID(ctx, investID); err != nil {
+		t.Fatalf("GetInvestByID() after RestoreInvest() = %v", err)
+	}
+}
+
+func TestUpdateInvestConcurrent(t *testing.T) {
+	store := requireStore(t)
+	ctx := context.Background()
+	user := createTestUser(t, store)
+	investID := createTestInvest(t, store, user.ID(), domain.InvestTypeStock, time.Now().Add(-time.Hour))
+
+	const writers = 2
+	loaded := make([]*domain.Invest, 0, writers)
+	for i := 0; i < writers; i++ {
+		invest, err := store.GetInvestByID(ctx, investID)
+		if err != nil {
+			t.Fatalf("GetInvestByID() = %v", err)
+		}
+		withdrawn, err := invest.Withdraw(domain.NewAmountFromMinorUnits(int64(i+1)*10_00, domain.CurrencyUSD))
+		if err != nil {
+			t.Fatalf("Withdraw() = %v", err)
+		}
+		loaded = append(loaded, withdrawn)
+	}
+
+	errs := make(chan error, writers)
+	for _, invest := range loaded {
+		go func(invest *domain.Invest) {
+			errs <- store.UpdateInvest(ctx, invest)
+		}(invest)
+	}
+
+	succeeded, conflicted := 0, 0
+	for i := 0; i < writers; i++ {
+		switch err := <-errs; {
+		case err == nil:
+			succeeded++
+		case errors.Is(err, ErrConcurrentModification):
+			conflicted++
+		default:
+			t.Fatalf("UpdateInvest() = %v", err)
+		}
+	}
+	if succeeded != 1 || conflicted != 1 {
+		t.Fatalf("UpdateInvest() succeeded %d and conflicted %d times, want 1 and 1", succeeded, conflicted)
+	}
+
+	stored, err := store.GetInvestByID(ctx, investID)
+	if err != nil {
+		t.Fatalf("GetInvestByID() = %v", err)
+	}
+	if stored.Version() != loaded[0].Version()+1 {
+		t.Fatalf("Version() = %d, want %d", stored.Version(), loaded[0].Version()+1)
+	}
+}
+
+func TestCreateInvestBatch(t *testing.T) {
+	store := requireStore(t)
+	ctx := context.Background()
+
+	investedAt, err := domain.NewInvestedAt(time.Date(2023, time.January, 1, 12, 0, 0, 0, time.Local))
+	if err != nil {
+		t.Fatalf("NewInvestedAt() = %v", err)
+	}
+
+	newInvests := func(t *testing.T, userID domain.UserID, n int) []*domain.Invest {
+		t.Helper()
+
+		invests := make([]*domain.Invest, 0, n)
+		for i := 0; i < n; i++ {
+			invest, err := domain.NewInvest(userID, domain.NewAmountFromMinorUnits(100_00, domain.CurrencyUSD), domain.InvestTypeStock, investedAt)
+			if err != nil {
+				t.Fatalf("NewInvest() = %v", err)
+			}
+			invests = append(invests, invest)
+		}
+
+		return invests
+	}
+
+	tests := []struct {
+		name      string
+		corrupt   func(t *testing.T, userID domain.UserID, invests []*domain.Invest)
+		wantErr   bool
+		wantTotal int
+	}{
+		{
+			name:      "all valid",
+			corrupt:   func(*testing.T, domain.UserID, []*domain.Invest) {},
+			wantTotal: 1000,
+		},
+		{
+			name: "out of range amount in the last chunk",
+			corrupt: func(t *testing.T, userID domain.UserID, invests []*domain.Invest) {
+				// Unbounded by the domain, but overflows the decimal(18,3) column
+				// after the first chunk has already been written.
+				amount := domain.NewAmountFromMinorUnits(100_000_000_000_000_000, domain.CurrencyUSD)
+				invest, err := domain.NewInvestWithLimits(userID, amount, domain.InvestTypeStock, investedAt, domain.InvestAmountLimits{})
+				if err != nil {
+					t.Fatalf("NewInvestWithLimits() = %v", err)
+				}
+				invests[len(invests)-1] = invest
+			},
+			wantErr:   true,
+			wantTotal: 0,
+		},
+		{
+			name: "invest of another user",
+			corrupt: func(t *testing.T, _ domain.UserID, invests []*domain.Invest) {
+				other := createTestUser(t, store)
+				invests[len(invests)/2] = newInvests(t, other.ID(), 1)[0]
+			},
+			wantErr:   true,
+			wantTotal: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := createTestUser(t, store)
+			invests := newInvests(t, user.ID(), 1000)
+			tt.corrupt(t, user.ID(), invests)
+
+			investIDs, err := store.CreateInvestBatch(ctx, user.ID(), invests)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CreateInvestBatch() = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && len(investIDs) != tt.wantTotal {
+				t.Fatalf("CreateInvestBatch() returned %d ids, want %d", len(investIDs), tt.wantTotal)
+			}
+
+			_, total, err := store.ListInvestsByUserID(ctx, user.ID(), InvestFilter{}, NewPagination(1, 0))
+			if err != nil {
+				t.Fatalf("ListInvestsByUserID() = %v", err)
+			}
+			if total != tt.wantTotal {
+				t.Fatalf("stored invests = %d, want %d", total, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestReassignInvests(t *testing.T) {
+	store := requireStore(t)
+	ctx := context.Background()
+	from := createTestUser(t, store)
+	to := createTestUser(t, store)
+	bystander := createTestUser(t, store)
+	investedAt := time.Now().Add(-time.Hour)
+
+	moved := []domain.InvestID{
+		createTestInvest(t, store, from.ID(), domain.InvestTypeStock, investedAt),
+		createTestInvest(t, store, from.ID(), domain.InvestTypeBond, investedAt),
+		createTestInvest(t, store, from.ID(), domain.InvestTypeCash, investedAt),
+	}
+	if err := store.DeleteInvest(ctx, moved[2]); err != nil {
+		t.Fatalf("DeleteInvest() = %v", err)
+	}
+	createTestInvest(t, store, to.ID(), domain.InvestTypeStock, investedAt)
+	createTestInvest(t, store, bystander.ID(), domain.InvestTypeStock, investedAt)
+
+	versions := make(map[domain.InvestID]domain.Version, len(moved))
+	for _, investID := range moved {
+		invest, err := store.GetInvestByID(ctx, investID, IncludeDeleted())
+		if err != nil {
+			t.Fatalf("GetInvestByID() = %v", err)
+		}
+		versions[investID] = invest.Version()
+	}
+
+	got, err := store.ReassignInvests(ctx, from.ID(), to.ID())
+	if err != nil {
+		t.Fatalf("ReassignInvests() = %v", err)
+	}
+	if got != len(moved) {
+		t.Fatalf("ReassignInvests() = %d, want %d", got, len(moved))
+	}
+
+	tests := []struct {
+		name   string
+		userID domain.UserID
+		want   int
+	}{
+		{name: "source", userID: from.ID(), want: 0},
+		{name: "target", userID: to.ID(), want: 4},
+		{name: "other user", userID: bystander.ID(), want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, total, err := store.ListInvestsByUserID(ctx, tt.userID, InvestFilter{IncludeDeleted: true}, NewPagination(10, 0))
+			if err != nil {
+				t.Fatalf("ListInvestsByUserID() = %v", err)
+			}
+			if total != tt.want {
+				t.Fatalf("ListInvestsByUserID() total = %d, want %d", total, tt.want)
+			}
+		})
+	}
+
+	for _, investID := range moved {
+		invest, err := store.GetInvestByID(ctx, investID, IncludeDeleted())
+		if err != nil {
+			t.Fatalf("GetInvestByID() = %v", err)
+		}
+		if invest.UserID() != to.ID() || invest.Version() != versions[investID]+1 {
+			t.Fatalf("GetInvestByID() UserID(), Version() = %d, %d, want %d, %d", invest.UserID(), invest.Version(), to.ID(), versions[investID]+1)
+		}
+	}
+
+	if got, err := store.ReassignInvests(ctx, from.ID(), to.ID()); err != nil || got != 0 {
+		t.Fatalf("second ReassignInvests() = %d, %v, want 0, nil", got, err)
+	}
+}
+
+func TestReassignInvestsSameUser(t *testing.T) {
+	// ErrSameUser is returned before any query, so no database is needed.
+	store := newUnreachableStore(t)
+
+	got, err := store.ReassignInvests(context.Background(), 1, 1)
+	if !errors.Is(err, ErrSameUser) {
+		t.Fatalf("ReassignInvests() = %v, want %v", err, ErrSameUser)
+	}
+	if got != 0 {
+		t.Fatalf("ReassignInvests() = %d, want 0", got)
+	}
+}
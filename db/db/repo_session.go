@@ -6,29 +6,222 @@ import (
 
 	"github.com/azusaanson/invest-api/domain"
 	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type SessionQueries interface {
+	GetSessionByRefreshToken(ctx context.Context, refreshToken domain.RefreshToken) (*domain.Session, error)
+	ListActiveSessionsByUserID(ctx context.Context, userID domain.UserID, now time.Time) ([]*domain.Session, error)
 	CreateSession(ctx context.Context, session *domain.Session) error
+	CreateSessionWithLimit(ctx context.Context, session *domain.Session, limit *domain.SessionLimit) error
+	RotateSession(ctx context.Context, old *domain.Session, session *domain.Session) error
+	RevokeAllSessions(ctx context.Context, userID domain.UserID) (int, error)
+	TouchSession(ctx context.Context, sessionID domain.SessionID, now time.Time) (bool, error)
+}
+
+func (s *Store) GetSessionByRefreshToken(
+	ctx context.Context,
+	refreshToken domain.RefreshToken,
+) (*domain.Session, error) {
+	record := &Session{}
+
+	err := s.db(ctx).Model(&Session{}).
+		Where("refresh_token_hash = ?", refreshToken.Hash()).
+		First(record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.WithStack(ErrSessionNotFound)
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	session, err := toSessionDomain(record)
+	if err != nil {
+		return nil, errorWithStatus(codes.DataLoss, err)
+	}
+	return session, nil
+}
+
+// ListActiveSessionsByUserID returns the sessions that are neither blocked,
+// rotated nor expired at now, newest first.
+func (s *Store) ListActiveSessionsByUserID(
+	ctx context.Context,
+	userID domain.UserID,
+	now time.Time,
+) ([]*domain.Session, error) {
+	records := []*Session{}
+
+	err := s.db(ctx).
+		Where("user_id = ? AND is_blocked = ? AND is_rotated = ? AND expires_at > ?", userID, false, false, now).
+		Order("created_at DESC").
+		Find(&records).Error
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	sessions := make([]*domain.Session, 0, len(records))
+	for _, record := range records {
+		session, err := toSessionDomain(record)
+		if err != nil {
+			return nil, errorWithStatus(codes.DataLoss, err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, nil
 }
 
 func (s *Store) CreateSession(
 	ctx context.Context,
 	session *domain.Session,
 ) error {
-	record := &Session{
-		UUID:         session.UUID().ToString(),
-		UserID:       uint64(session.UserID()),
-		RefreshToken: string(session.RefreshToken()),
-		UserAgent:    string(session.UserAgent()),
-		ClientIp:     string(session.ClientIp()),
-		IsBlocked:    bool(session.IsBlocked()),
-		ExpiresAt:    time.Time(session.ExpiresAt()),
-	}
-
-	if err := s.conn.Create(record).Error; err != nil {
+	if err := s.db(ctx).Create(toSessionRecord(session)).Error; err != nil {
 		return errors.WithStack(err)
 	}
 
 	return nil
 }
+
+// CreateSessionWithLimit blocks the sessions chosen by the limit's eviction
+// policy and stores the new session in one transaction.
+func (s *Store) CreateSessionWithLimit(
+	ctx context.Context,
+	session *domain.Session,
+	limit *domain.SessionLimit,
+) error {
+	return s.db(ctx).Transaction(func(tx *gorm.DB) error {
+		records := []*Session{}
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND is_blocked = ? AND is_rotated = ? AND expires_at > ?", session.UserID(), false, false, time.Now()).
+			Find(&records).Error
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		active := make([]*domain.Session, 0, len(records))
+		for _, record := range records {
+			activeSession, err := toSessionDomain(record)
+			if err != nil {
+				return errorWithStatus(codes.DataLoss, err)
+			}
+			active = append(active, activeSession)
+		}
+
+		evicted := limit.SessionsToEvict(active, time.Now())
+		if len(evicted) > 0 {
+			uuids := make([]string, 0, len(evicted))
+			for _, evictedSession := range evicted {
+				uuids = append(uuids, evictedSession.ID().String())
+			}
+
+			err := tx.Model(&Session{}).
+				Where("uuid IN ?", uuids).
+				Update("is_blocked", true).Error
+			if err != nil {
+				return errors.WithStack(err)
+			}
+		}
+
+		if err := tx.Create(toSessionRecord(session)).Error; err != nil {
+			return errors.WithStack(err)
+		}
+
+		return nil
+	})
+}
+
+// RotateSession marks old as rotated and stores its replacement in one
+// transaction. It returns domain.ErrRefreshTokenReuse when old was already
+// rotated by a concurrent request.
+func (s *Store) RotateSession(
+	ctx context.Context,
+	old *domain.Session,
+	session *domain.Session,
+) error {
+	return s.db(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&Session{}).
+			Where("uuid = ? AND is_rotated = ?", old.ID().String(), false).
+			Update("is_rotated", true)
+		if result.Error != nil {
+			return errors.WithStack(result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return errors.WithStack(domain.ErrRefreshTokenReuse)
+		}
+
+		if err := tx.Create(toSessionRecord(session)).Error; err != nil {
+			return errors.WithStack(err)
+		}
+
+		return nil
+	})
+}
+
+// RevokeAllSessions blocks every active session of the user in a single
+// statement and returns how many were blocked, so repeated or concurrent
+// calls never double count.
+func (s *Store) RevokeAllSessions(
+	ctx context.Context,
+	userID domain.UserID,
+) (int, error) {
+	result := s.db(ctx).
+		Model(&Session{}).
+		Where("user_id = ? AND is_blocked = ? AND expires_at > ?", userID, false, time.Now()).
+		Update("is_blocked", true)
+	if result.Error != nil {
+		return 0, errors.WithStack(result.Error)
+	}
+
+	return int(result.RowsAffected), nil
+}
+
+// TouchSession records that the session was used at now. To keep every
+// request from writing, the row is only updated when the stored value is older
+// than the store's session touch interval; the result reports whether it was.
+func (s *Store) TouchSession(
+	ctx context.Context,
+	sessionID domain.SessionID,
+	now time.Time,
+) (bool, error) {
+	result := s.db(ctx).
+		Model(&Session{}).
+		Where("uuid = ? AND last_seen_at < ?", sessionID.String(), now.Add(-s.sessionTouchInterval)).
+		Update("last_seen_at", now)
+	if result.Error != nil {
+		return false, errors.WithStack(result.Error)
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func toSessionRecord(session *domain.Session) *Session {
+	return &Session{
+		BaseModel:        BaseModel{CreatedAt: time.Time(session.CreatedAt())},
+		UUID:             session.ID().String(),
+		UserID:           uint64(session.UserID()),
+		RefreshTokenHash: string(session.RefreshTokenHash()),
+		UserAgent:        string(session.UserAgent()),
+		ClientIp:         string(session.ClientIp()),
+		IsBlocked:        bool(session.IsBlocked()),
+		IsRotated:        bool(session.IsRotated()),
+		ExpiresAt:        time.Time(session.ExpiresAt()),
+		LastSeenAt:       time.Time(session.LastSeenAt()),
+	}
+}
+
+func toSessionDomain(record *Session) (*domain.Session, error) {
+	return domain.NewSessionFromSource(
+		record.UUID,
+		record.UserID,
+		record.RefreshTokenHash,
+		record.UserAgent,
+		record.ClientIp,
+		record.IsBlocked,
+		record.IsRotated,
+		record.ExpiresAt,
+		record.CreatedAt,
+		record.LastSeenAt,
+	)
+}
@@ -0,0 +1,324 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/azusaanson/invest-api/domain"
+)
+
+func createTestSession(
+	t *testing.T,
+	store StoreInterface,
+	userID domain.UserID,
+	expiresAt time.Time,
+) (*domain.Session, domain.RefreshToken) {
+	t.Helper()
+
+	sessionID, err := domain.NewSessionID()
+	if err != nil {
+		t.Fatalf("NewSessionID() = %v", err)
+	}
+	refreshToken, err := domain.GenerateRefreshToken()
+	if err != nil {
+		t.Fatalf("GenerateRefreshToken() = %v", err)
+	}
+	metaData, err := domain.NewUserMetadata("Mozilla/5.0", "")
+	if err != nil {
+		t.Fatalf("NewUserMetadata() = %v", err)
+	}
+
+	session, err := domain.NewSession(sessionID, userID, refreshToken, domain.ExpiresAt(expiresAt), metaData)
+	if err != nil {
+		t.Fatalf("NewSession() = %v", err)
+	}
+	if err := store.CreateSession(context.Background(), session); err != nil {
+		t.Fatalf("CreateSession() = %v", err)
+	}
+
+	return session, refreshToken
+}
+
+func TestRotateSession(t *testing.T) {
+	store := requireStore(t)
+	ctx := context.Background()
+	user := createTestUser(t, store)
+	_, refreshToken := createTestSession(t, store, user.ID(), time.Now().Add(time.Hour))
+
+	// Two requests read the same session before either rotates it.
+	first, err := store.GetSessionByRefreshToken(ctx, refreshToken)
+	if err != nil {
+		t.Fatalf("GetSessionByRefreshToken() = %v", err)
+	}
+	second, err := store.GetSessionByRefreshToken(ctx, refreshToken)
+	if err != nil {
+		t.Fatalf("GetSessionByRefreshToken() = %v", err)
+	}
+
+	next, nextRefreshToken, err := domain.RotateSession(first, time.Now(), time.Hour)
+	if err != nil {
+		t.Fatalf("domain.RotateSession() = %v", err)
+	}
+	if err := store.RotateSession(ctx, first, next); err != nil {
+		t.Fatalf("RotateSession() = %v", err)
+	}
+
+	replayed, _, err := domain.RotateSession(second, time.Now(), time.Hour)
+	if err != nil {
+		t.Fatalf("domain.RotateSession() = %v", err)
+	}
+	if err := store.RotateSession(ctx, second, replayed); !errors.Is(err, domain.ErrRefreshTokenReuse) {
+		t.Fatalf("replayed RotateSession() = %v, want %v", err, domain.ErrRefreshTokenReuse)
+	}
+
+	stored, err := store.GetSessionByRefreshToken(ctx, refreshToken)
+	if err != nil {
+		t.Fatalf("GetSessionByRefreshToken() = %v", err)
+	}
+	if !stored.IsRotated() {
+		t.Fatal("old session is not stored as rotated")
+	}
+	if _, err := store.GetSessionByRefreshToken(ctx, nextRefreshToken); err != nil {
+		t.Fatalf("GetSessionByRefreshToken(new token) = %v", err)
+	}
+}
+
+func TestRevokeAllSessions(t *testing.T) {
+	store := requireStore(t)
+	ctx := context.Background()
+	user := createTestUser(t, store)
+	other := createTestUser(t, store)
+
+	for i := 0; i < 3; i++ {
+		createTestSession(t, store, user.ID(), time.Now().Add(time.Hour))
+	}
+	createTestSession(t, store, other.ID(), time.Now().Add(time.Hour))
+
+	tests := []struct {
+		name   string
+		userID domain.UserID
+		want   int
+	}{
+		{name: "blocks every active session", userID: user.ID(), want: 3},
+		{name: "is idempotent", userID: user.ID(), want: 0},
+	}
+
+	for _, tt := range tests {
+		got, err := store.RevokeAllSessions(ctx, tt.userID)
+		if err != nil {
+			t.Fatalf("%s: RevokeAllSessions() = %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Fatalf("%s: RevokeAllSessions() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+
+	active, err := store.ListActiveSessionsByUserID(ctx, user.ID(), time.Now())
+	if err != nil {
+		t.Fatalf("ListActiveSessionsByUserID() = %v", err)
+	}
+	if len(active) != 0 {
+		t.Fatalf("active sessions = %d, want 0", len(active))
+	}
+
+	otherActive, err := store.ListActiveSessionsByUserID(ctx, other.ID(), time.Now())
+	if err != nil {
+		t.Fatalf("ListActiveSessionsByUserID() = %v", err)
+	}
+	if len(otherActive) != 1 {
+		t.Fatalf("other user's active sessions = %d, want 1", len(otherActive))
+	}
+}
+
+func TestRevokeAllSessionsConcurrent(t *testing.T) {
+	store := requireStore(t)
+	ctx := context.Background()
+	user := createTestUser(t, store)
+
+	for i := 0; i < 3; i++ {
+		createTestSession(t, store, user.ID(), time.Now().Add(time.Hour))
+	}
+
+	const callers = 4
+	counts := make(chan int, callers)
+	errs := make(chan error, callers)
+	for i := 0; i < callers; i++ {
+		go func() {
+			n, err := store.RevokeAllSessions(ctx, user.ID())
+			counts <- n
+			errs <- err
+		}()
+	}
+
+	total := 0
+	for i := 0; i < callers; i++ {
+		total += <-counts
+		if err := <-errs; err != nil {
+			t.Fatalf("RevokeAllSessions() = %v", err)
+		}
+	}
+	if total != 3 {
+		t.Fatalf("revoked in total = %d, want 3", total)
+	}
+}
+
+func TestCreateSessionWithLimit(t *testing.T) {
+	store := requireStore(t)
+	ctx := context.Background()
+	user := createTestUser(t, store)
+
+	limit, err := domain.NewSessionLimit(5, domain.EvictOldestSessionPolicy{})
+	if err != nil {
+		t.Fatalf("NewSessionLimit() = %v", err)
+	}
+
+	// Created one minute apart, so the oldest does not depend on how precisely
+	// the database stores timestamps.
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	sessions := make([]*domain.Session, 0, 6)
+	for i := 0; i < 6; i++ {
+		sessionID, err := domain.NewSessionID()
+		if err != nil {
+			t.Fatalf("NewSessionID() = %v", err)
+		}
+		createdAt := start.Add(time.Duration(i) * time.Minute)
+
+		session, err := domain.NewSessionFromSource(
+			sessionID.String(),
+			uint64(user.ID()),
+			domain.RefreshToken(sessionID.String()).Hash(),
+			"Mozilla/5.0",
+			"",
+			false,
+			false,
+			time.Now().Add(time.Hour),
+			createdAt,
+			createdAt,
+		)
+		if err != nil {
+			t.Fatalf("NewSessionFromSource() = %v", err)
+		}
+		sessions = append(sessions, session)
+
+		if err := store.CreateSessionWithLimit(ctx, session, limit); err != nil {
+			t.Fatalf("CreateSessionWithLimit() #%d = %v", i+1, err)
+		}
+	}
+
+	active, err := store.ListActiveSessionsByUserID(ctx, user.ID(), time.Now())
+	if err != nil {
+		t.Fatalf("ListActiveSessionsByUserID() = %v", err)
+	}
+	if len(active) != 5 {
+		t.Fatalf("active sessions = %d, want 5", len(active))
+	}
+	for _, session := range active {
+		if session.ID() == sessions[0].ID() {
+			t.Fatal("the oldest session is still active")
+		}
+	}
+}
+
+func TestListActiveSessionsByUserID(t *testing.T) {
+	store := requireStore(t)
+	ctx := context.Background()
+	user := createTestUser(t, store)
+	now := time.Now()
+
+	shortLived, _ := createTestSession(t, store, user.ID(), now.Add(time.Hour))
+	longLived, _ := createTestSession(t, store, user.ID(), now.Add(3*time.Hour))
+	rotated, _ := createTestSession(t, store, user.ID(), now.Add(3*time.Hour))
+
+	next, _, err := domain.RotateSession(rotated, now, 3*time.Hour)
+	if err != nil {
+		t.Fatalf("domain.RotateSession() = %v", err)
+	}
+	if err := store.RotateSession(ctx, rotated, next); err != nil {
+		t.Fatalf("RotateSession() = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		now     time.Time
+		wantIDs []domain.SessionID
+	}{
+		{name: "rotated is excluded", now: now, wantIDs: []domain.SessionID{shortLived.ID(), longLived.ID(), next.ID()}},
+		{name: "expired is excluded", now: now.Add(2 * time.Hour), wantIDs: []domain.SessionID{longLived.ID(), next.ID()}},
+		{name: "all expired", now: now.Add(4 * time.Hour), wantIDs: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessions, err := store.ListActiveSessionsByUserID(ctx, user.ID(), tt.now)
+			if err != nil {
+				t.Fatalf("ListActiveSessionsByUserID() = %v", err)
+			}
+
+			got := map[domain.SessionID]bool{}
+			for _, session := range sessions {
+				got[session.ID()] = true
+			}
+			if len(got) != len(tt.wantIDs) {
+				t.Fatalf("ListActiveSessionsByUserID() returned %d sessions, want %d", len(got), len(tt.wantIDs))
+			}
+			for _, id := range tt.wantIDs {
+				if !got[id] {
+					t.Fatalf("ListActiveSessionsByUserID() is missing session %s", id)
+				}
+			}
+		})
+	}
+}
+
+func TestTouchSession(t *testing.T) {
+	requireStore(t)
+	store := NewStore(testConn, WithSessionTouchInterval(time.Minute))
+	ctx := context.Background()
+	user := createTestUser(t, store)
+	session, refreshToken := createTestSession(t, store, user.ID(), time.Now().Add(time.Hour))
+	createdAt := time.Time(session.LastSeenAt())
+
+	unknownID, err := domain.NewSessionID()
+	if err != nil {
+		t.Fatalf("NewSessionID() = %v", err)
+	}
+
+	// Each step touches the same session; offsets are from its creation and
+	// leave room for the stored timestamp losing its fraction of a second.
+	tests := []struct {
+		name         string
+		sessionID    domain.SessionID
+		offset       time.Duration
+		wantWritten  bool
+		wantLastSeen time.Duration
+	}{
+		{name: "within the interval of creation", sessionID: session.ID(), offset: 30 * time.Second, wantWritten: false, wantLastSeen: 0},
+		{name: "past the interval", sessionID: session.ID(), offset: 2 * time.Minute, wantWritten: true, wantLastSeen: 2 * time.Minute},
+		{name: "rapid second touch", sessionID: session.ID(), offset: 2*time.Minute + 10*time.Second, wantWritten: false, wantLastSeen: 2 * time.Minute},
+		{name: "past the interval again", sessionID: session.ID(), offset: 3*time.Minute + 10*time.Second, wantWritten: true, wantLastSeen: 3*time.Minute + 10*time.Second},
+		{name: "unknown session", sessionID: unknownID, offset: time.Hour, wantWritten: false, wantLastSeen: 3*time.Minute + 10*time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			written, err := store.TouchSession(ctx, tt.sessionID, createdAt.Add(tt.offset))
+			if err != nil {
+				t.Fatalf("TouchSession() = %v", err)
+			}
+			if written != tt.wantWritten {
+				t.Fatalf("TouchSession() = %v, want %v", written, tt.wantWritten)
+			}
+
+			stored, err := store.GetSessionByRefreshToken(ctx, refreshToken)
+			if err != nil {
+				t.Fatalf("GetSessionByRefreshToken() = %v", err)
+			}
+			want := createdAt.Add(tt.wantLastSeen)
+			if diff := time.Time(stored.LastSeenAt()).Sub(want); diff < -time.Second || time.Second < diff {
+				t.Fatalf("LastSeenAt() = %v, want %v", time.Time(stored.LastSeenAt()), want)
+			}
+		})
+	}
+}
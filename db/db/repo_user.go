@@ -11,30 +11,57 @@ import (
 )
 
 type UserQueries interface {
-	GetUserByName(ctx context.Context, name domain.UserName) (*domain.User, error)
-	CreateUser(ctx context.Context, user *domain.User) error
+	GetUserByID(ctx context.Context, userID domain.UserID, opts ...QueryOption) (*domain.User, error)
+	GetUserByName(ctx context.Context, name domain.UserName, opts ...QueryOption) (*domain.User, error)
+	CreateUser(ctx context.Context, user *domain.User) (domain.UserID, error)
 	UpdateUser(ctx context.Context, user *domain.User) error
+	BulkUpdateRole(ctx context.Context, userIDs []domain.UserID, newRole domain.UserRole) (int, error)
 	DeleteUser(ctx context.Context, userID domain.UserID) error
+	RestoreUser(ctx context.Context, userID domain.UserID) error
 }
 
-func (s *Store) GetUserByName(
+func (s *Store) GetUserByID(
 	ctx context.Context,
-	name domain.UserName,
+	userID domain.UserID,
+	opts ...QueryOption,
 ) (*domain.User, error) {
 	record := &User{}
 
-	err := s.conn.Model(&User{}).
-		Where("name = ?", name).
+	err := applyQueryOptions(s.db(ctx).Model(&User{}), opts).
+		Where("id = ?", userID).
 		First(record).Error
-	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.WithStack(ErrUserNotFound)
+	}
+	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
-	if record.ID == 0 {
-		return nil, nil
+	user, err := toUserDomain(record)
+	if err != nil {
+		return nil, errorWithStatus(codes.DataLoss, err)
 	}
+	return user, nil
+}
 
-	user, err := domain.NewUserFromSource(record.ID, record.Name, record.Password, record.Role)
+func (s *Store) GetUserByName(
+	ctx context.Context,
+	name domain.UserName,
+	opts ...QueryOption,
+) (*domain.User, error) {
+	record := &User{}
+
+	err := applyQueryOptions(s.db(ctx).Model(&User{}), opts).
+		Where("canonical_name = ?", name.Canonical()).
+		First(record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.WithStack(ErrUserNotFound)
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	user, err := toUserDomain(record)
 	if err != nil {
 		return nil, errorWithStatus(codes.DataLoss, err)
 	}
@@ -44,44 +71,83 @@ func (s *Store) GetUserByName(
 func (s *Store) CreateUser(
 	ctx context.Context,
 	user *domain.User,
-) error {
-	record := &User{
-		Name:     string(user.Name()),
-		Password: string(user.HashedPassword()),
-		Role:     string(user.Role()),
+) (domain.UserID, error) {
+	record := toUserRecord(user)
+
+	if err := s.db(ctx).Create(record).Error; err != nil {
+		if isDuplicateKeyError(err) {
+			return 0, errors.Wrap(ErrUserNameTaken, err.Error())
+		}
+		return 0, errors.WithStack(err)
 	}
 
-	if err := s.conn.Create(record).Error; err != nil {
-		return errors.WithStack(err)
+	userID, err := domain.NewUserID(record.ID)
+	if err != nil {
+		return 0, errors.WithStack(err)
 	}
 
-	return nil
+	return userID, nil
 }
 
 func (s *Store) UpdateUser(
 	ctx context.Context,
 	user *domain.User,
 ) error {
-	err := s.conn.
+	err := s.db(ctx).
 		Model(&User{}).
 		Where("id = ?", user.ID()).
 		Updates(map[string]interface{}{
-			"name":     user.Name(),
-			"password": user.HashedPassword(),
-			"role":     user.Role(),
+			"name":           user.Name(),
+			"canonical_name": user.Name().Canonical(),
+			"password":       string(user.HashedPassword()),
+			"role":           user.Role(),
+
+			"password_changed_at": user.PasswordChangedAt(),
 		}).Error
 	if err != nil {
+		if isDuplicateKeyError(err) {
+			return errors.Wrap(ErrUserNameTaken, err.Error())
+		}
 		return errors.WithStack(err)
 	}
 
 	return nil
 }
 
+// BulkUpdateRole sets the role of all users in userIDs in one statement.
+// Unknown ids and users that already have the role are skipped and not
+// counted.
+func (s *Store) BulkUpdateRole(
+	ctx context.Context,
+	userIDs []domain.UserID,
+	newRole domain.UserRole,
+) (int, error) {
+	role, err := domain.NewUserRole(string(newRole))
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	if len(userIDs) == 0 {
+		return 0, nil
+	}
+
+	result := s.db(ctx).
+		Model(&User{}).
+		Where("id IN ? AND role <> ?", userIDs, role).
+		Update("role", role)
+	if result.Error != nil {
+		return 0, errors.WithStack(result.Error)
+	}
+
+	return int(result.RowsAffected), nil
+}
+
+// DeleteUser soft deletes the user; RestoreUser undoes it.
 func (s *Store) DeleteUser(
 	ctx context.Context,
 	userID domain.UserID,
 ) error {
-	err := s.conn.
+	err := s.db(ctx).
 		Where("id = ?", userID).
 		Delete(&User{}).Error
 	if err != nil {
@@ -90,3 +156,41 @@ func (s *Store) DeleteUser(
 
 	return nil
 }
+
+func (s *Store) RestoreUser(
+	ctx context.Context,
+	userID domain.UserID,
+) error {
+	err := s.db(ctx).
+		Unscoped().
+		Model(&User{}).
+		Where("id = ?", userID).
+		Update("deleted_at", nil).Error
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+func toUserRecord(user *domain.User) *User {
+	return &User{
+		BaseModel:     BaseModel{ID: uint64(user.ID())},
+		Name:          string(user.Name()),
+		CanonicalName: user.Name().Canonical(),
+		Password:      string(user.HashedPassword()),
+		Role:          string(user.Role()),
+
+		PasswordChangedAt: user.PasswordChangedAt(),
+	}
+}
+
+func toUserDomain(record *User) (*domain.User, error) {
+	return domain.NewUserFromSource(
+		record.ID,
+		record.Name,
+		record.Password,
+		record.Role,
+		record.PasswordChangedAt,
+	)
+}
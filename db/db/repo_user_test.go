@@ -0,0 +1,209 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/azusaanson/invest-api/domain"
+)
+
+func TestUserRecordMapping(t *testing.T) {
+	passwordChangedAt := time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)
+	user, err := domain.NewUserFromSource(7, "Alice", string(domain.DummyHash), string(domain.RoleAdmin), passwordChangedAt)
+	if err != nil {
+		t.Fatalf("NewUserFromSource() = %v", err)
+	}
+
+	record := toUserRecord(user)
+	if record.ID != 7 || record.Name != "Alice" || record.CanonicalName != "alice" || record.Role != "admin" {
+		t.Fatalf("toUserRecord() = %+v", record)
+	}
+
+	got, err := toUserDomain(record)
+	if err != nil {
+		t.Fatalf("toUserDomain() = %v", err)
+	}
+	if got.ID() != user.ID() || got.Name() != user.Name() || got.Role() != user.Role() ||
+		string(got.HashedPassword()) != string(user.HashedPassword()) || !got.PasswordChangedAt().Equal(passwordChangedAt) {
+		t.Fatalf("toUserDomain(toUserRecord()) = %+v, want %+v", got.ToDTO(), user.ToDTO())
+	}
+}
+
+func TestUserQueriesNotFound(t *testing.T) {
+	tests := []struct {
+		name string
+		repo func(t *testing.T) UserQueries
+	}{
+		{name: "fake", repo: func(t *testing.T) UserQueries { return newFakeUserQueries() }},
+		{name: "store", repo: func(t *testing.T) UserQueries { return requireStore(t) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := tt.repo(t)
+			ctx := context.Background()
+
+			// Ids are never reused and names are random, so neither exists.
+			if _, err := repo.GetUserByID(ctx, domain.UserID(1<<62)); !errors.Is(err, ErrUserNotFound) {
+				t.Fatalf("GetUserByID() = %v, want %v", err, ErrUserNotFound)
+			}
+			if _, err := repo.GetUserByName(ctx, "missing_8f3a2c1d"); !errors.Is(err, ErrUserNotFound) {
+				t.Fatalf("GetUserByName() = %v, want %v", err, ErrUserNotFound)
+			}
+		})
+	}
+}
+
+func TestDeleteUser(t *testing.T) {
+	store := requireStore(t)
+	ctx := context.Background()
+	user := createTestUser(t, store)
+
+	if err := store.DeleteUser(ctx, user.ID()); err != nil {
+		t.Fatalf("DeleteUser() = %v", err)
+	}
+
+	if _, err := store.GetUserByID(ctx, user.ID()); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("GetUserByID() = %v, want %v", err, ErrUserNotFound)
+	}
+	if _, err := store.GetUserByName(ctx, user.Name()); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("GetUserByName() = %v, want %v", err, ErrUserNotFound)
+	}
+	if _, err := store.GetUserByID(ctx, user.ID(), IncludeDeleted()); err != nil {
+		t.Fatalf("GetUserByID(IncludeDeleted()) = %v", err)
+	}
+
+	if err := store.RestoreUser(ctx, user.ID()); err != nil {
+		t.Fatalf("RestoreUser() = %v", err)
+	}
+	if _, err := store.GetUserByID(ctx, user.ID()); err != nil {
+		t.Fatalf("GetUserByID() after RestoreUser() = %v", err)
+	}
+}
+
+func TestBulkUpdateRole(t *testing.T) {
+	store := requireStore(t)
+	ctx := context.Background()
+
+	// Ids far above anything the tests insert stand in for unknown users.
+	const unknown, alsoUnknown domain.UserID = 1 << 40, 1<<40 + 1
+
+	tests := []struct {
+		name      string
+		ids       func(users []*domain.User) []domain.UserID
+		role      domain.UserRole
+		wantCount int
+		wantErr   error
+		wantRoles []domain.UserRole
+	}{
+		{
+			name: "valid and unknown ids",
+			ids: func(users []*domain.User) []domain.UserID {
+				return []domain.UserID{users[0].ID(), unknown, users[1].ID(), alsoUnknown}
+			},
+			role:      domain.RoleAdmin,
+			wantCount: 2,
+			wantRoles: []domain.UserRole{domain.RoleAdmin, domain.RoleAdmin, domain.RoleUser},
+		},
+		{
+			name: "already have the role",
+			ids: func(users []*domain.User) []domain.UserID {
+				return []domain.UserID{users[0].ID(), users[1].ID(), users[2].ID()}
+			},
+			role:      domain.RoleUser,
+			wantCount: 0,
+			wantRoles: []domain.UserRole{domain.RoleUser, domain.RoleUser, domain.RoleUser},
+		},
+		{
+			name:      "only unknown ids",
+			ids:       func([]*domain.User) []domain.UserID { return []domain.UserID{unknown, alsoUnknown} },
+			role:      domain.RoleAdmin,
+			wantCount: 0,
+			wantRoles: []domain.UserRole{domain.RoleUser, domain.RoleUser, domain.RoleUser},
+		},
+		{
+			name:      "no ids",
+			ids:       func([]*domain.User) []domain.UserID { return nil },
+			role:      domain.RoleAdmin,
+			wantCount: 0,
+			wantRoles: []domain.UserRole{domain.RoleUser, domain.RoleUser, domain.RoleUser},
+		},
+		{
+			name:      "invalid role",
+			ids:       func(users []*domain.User) []domain.UserID { return []domain.UserID{users[0].ID()} },
+			role:      domain.UserRole("root"),
+			wantCount: 0,
+			wantErr:   domain.ErrUserRoleInvalid,
+			wantRoles: []domain.UserRole{domain.RoleUser, domain.RoleUser, domain.RoleUser},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := []*domain.User{createTestUser(t, store), createTestUser(t, store), createTestUser(t, store)}
+
+			count, err := store.BulkUpdateRole(ctx, tt.ids(users), tt.role)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("BulkUpdateRole() = %v, want %v", err, tt.wantErr)
+			}
+			if count != tt.wantCount {
+				t.Fatalf("BulkUpdateRole() count = %d, want %d", count, tt.wantCount)
+			}
+
+			for i, user := range users {
+				got, err := store.GetUserByID(ctx, user.ID())
+				if err != nil {
+					t.Fatalf("GetUserByID() = %v", err)
+				}
+				if got.Role() != tt.wantRoles[i] {
+					t.Fatalf("users[%d].Role() = %q, want %q", i, got.Role(), tt.wantRoles[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCreateUserDuplicateName(t *testing.T) {
+	store := requireStore(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		rename func(name string) string
+	}{
+		{name: "same name", rename: func(name string) string { return name }},
+		// Only the case differs, which the canonical name index must catch.
+		{name: "upper case", rename: strings.ToUpper},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := createTestUser(t, store)
+
+			duplicate, err := domain.NewUserForCreate(
+				tt.rename(string(user.Name())),
+				"Tx7!qLmZ",
+				string(domain.RoleUser),
+				domain.DefaultPasswordPolicy,
+			)
+			if err != nil {
+				t.Fatalf("NewUserForCreate() = %v", err)
+			}
+
+			if _, err := store.CreateUser(ctx, duplicate); !errors.Is(err, ErrUserNameTaken) {
+				t.Fatalf("CreateUser() = %v, want %v", err, ErrUserNameTaken)
+			}
+
+			got, err := store.GetUserByName(ctx, duplicate.Name())
+			if err != nil {
+				t.Fatalf("GetUserByName() = %v", err)
+			}
+			if got.ID() != user.ID() {
+				t.Fatalf("GetUserByName() = user %d, want %d", got.ID(), user.ID())
+			}
+		})
+	}
+}
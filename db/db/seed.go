@@ -0,0 +1,68 @@
+package db
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/azusaanson/invest-api/domain"
+)
+
+// ErrSeedUserNotAdmin means the admin name is already used by a user without
+// the admin role. EnsureAdmin never promotes an existing account.
+var ErrSeedUserNotAdmin = errors.New("seed: user exists but is not an admin")
+
+// EnsureAdmin creates an admin called name unless it already exists, so it is
+// safe to run on every boot. Reserved names such as "admin" are allowed, as
+// they are reserved for exactly this account. The password is only checked
+// against policy when the admin is created; an existing admin keeps the
+// password it has.
+func EnsureAdmin(
+	ctx context.Context,
+	repo UserQueries,
+	name string,
+	password string,
+	policy domain.PasswordPolicy,
+) (*domain.User, error) {
+	userName, err := domain.NewUserNameWithReserved(name, nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	user, err := repo.GetUserByName(ctx, userName)
+	if err == nil {
+		return existingAdmin(user)
+	}
+	if !errors.Is(err, ErrUserNotFound) {
+		return nil, err
+	}
+
+	newUser, err := domain.NewUserForCreateWithReserved(name, password, string(domain.RoleAdmin), nil, policy)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	userID, err := repo.CreateUser(ctx, newUser)
+	if errors.Is(err, ErrUserNameTaken) {
+		// another instance seeded the admin concurrently
+		user, err := repo.GetUserByName(ctx, userName)
+		if err != nil {
+			return nil, err
+		}
+
+		return existingAdmin(user)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.GetUserByID(ctx, userID)
+}
+
+func existingAdmin(user *domain.User) (*domain.User, error) {
+	if user.Role() != domain.RoleAdmin {
+		return nil, errors.WithStack(ErrSeedUserNotAdmin)
+	}
+
+	return user, nil
+}
@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/azusaanson/invest-api/domain"
+)
+
+const seedAdminPassword = "Sx7!qLmZ"
+
+func TestEnsureAdmin(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeUserQueries()
+
+	created, err := EnsureAdmin(ctx, repo, "admin", seedAdminPassword, domain.DefaultPasswordPolicy)
+	if err != nil {
+		t.Fatalf("first EnsureAdmin() = %v", err)
+	}
+	if created.ID() == 0 || created.Role() != domain.RoleAdmin || created.Name() != "admin" {
+		t.Fatalf("first EnsureAdmin() = %+v, want a saved admin called admin", created.ToDTO())
+	}
+
+	again, err := EnsureAdmin(ctx, repo, "Admin", "ignored on the second call", domain.DefaultPasswordPolicy)
+	if err != nil {
+		t.Fatalf("second EnsureAdmin() = %v", err)
+	}
+	if again.ID() != created.ID() {
+		t.Fatalf("second EnsureAdmin() returned user %d, want %d", again.ID(), created.ID())
+	}
+	if repo.creates != 1 {
+		t.Fatalf("users created = %d, want 1", repo.creates)
+	}
+	if err := again.HashedPassword().Verify(seedAdminPassword); err != nil {
+		t.Fatalf("admin password changed by the second call: %v", err)
+	}
+}
+
+func TestEnsureAdminErrors(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		existing string
+		password string
+		wantErr  error
+	}{
+		{name: "weak password", password: "short", wantErr: domain.ErrPasswordTooShort},
+		{name: "name taken by a user", existing: "root", password: seedAdminPassword, wantErr: ErrSeedUserNotAdmin},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeUserQueries()
+			if tt.existing != "" {
+				user, err := domain.NewUserForCreateWithReserved(tt.existing, seedAdminPassword, string(domain.RoleUser), nil, domain.DefaultPasswordPolicy)
+				if err != nil {
+					t.Fatalf("NewUserForCreateWithReserved() = %v", err)
+				}
+				if _, err := repo.CreateUser(ctx, user); err != nil {
+					t.Fatalf("CreateUser() = %v", err)
+				}
+			}
+
+			name := tt.existing
+			if name == "" {
+				name = "admin"
+			}
+
+			if _, err := EnsureAdmin(ctx, repo, name, tt.password, domain.DefaultPasswordPolicy); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("EnsureAdmin() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -2,35 +2,91 @@ package db
 
 import (
 	"context"
+	"time"
 
+	"github.com/pkg/errors"
 	"gorm.io/gorm"
 )
 
+// DefaultSessionTouchInterval is how far a session's last seen time may lag
+// before TouchSession writes it again.
+const DefaultSessionTouchInterval = time.Minute
+
 type Store struct {
-	conn *gorm.DB
+	conn                 *gorm.DB
+	sessionTouchInterval time.Duration
+}
+
+type StoreOption func(*Store)
+
+func WithSessionTouchInterval(interval time.Duration) StoreOption {
+	return func(s *Store) {
+		s.sessionTouchInterval = interval
+	}
 }
 
 type StoreInterface interface {
-	ExecTx(ctx context.Context, fn func(context.Context) error) error
+	ExecTx(ctx context.Context, fn func(txRepo Repositories) error) error
+	Ping(ctx context.Context) error
+	Repositories
+}
+
+// Repositories groups the queries that can run inside a transaction.
+type Repositories interface {
 	UserQueries
 	SessionQueries
+	InvestQueries
 }
 
-func NewStore(conn *gorm.DB) StoreInterface {
-	return &Store{conn: conn}
+func NewStore(conn *gorm.DB, opts ...StoreOption) StoreInterface {
+	store := &Store{conn: conn, sessionTouchInterval: DefaultSessionTouchInterval}
+	for _, opt := range opts {
+		opt(store)
+	}
+
+	return store
 }
 
-func (s *Store) ExecTx(ctx context.Context, fn func(context.Context) error) error {
-	tx := s.conn.Begin()
-	if tx.Error != nil {
-		return tx.Error
+// db scopes the connection to ctx, so cancelling the request or hitting its
+// deadline aborts the query. Every query method starts from it.
+func (s *Store) db(ctx context.Context) *gorm.DB {
+	return s.conn.WithContext(ctx)
+}
+
+func (s *Store) ExecTx(ctx context.Context, fn func(txRepo Repositories) error) error {
+	err := s.db(ctx).Transaction(func(tx *gorm.DB) error {
+		txStore := *s
+		txStore.conn = tx
+
+		return fn(&txStore)
+	})
+	if err != nil {
+		return errors.WithStack(err)
 	}
 
-	defer tx.Rollback()
+	return nil
+}
+
+// WithTx runs fn with repositories scoped to a single transaction. The
+// transaction is committed when fn returns nil and rolled back when it returns
+// an error or panics. Pass the options the store was built with, so the
+// repositories behave the same inside the transaction as outside it.
+func WithTx(ctx context.Context, conn *gorm.DB, fn func(txRepo Repositories) error, opts ...StoreOption) error {
+	return NewStore(conn, opts...).ExecTx(ctx, fn)
+}
+
+type QueryOption func(*gorm.DB) *gorm.DB
+
+func IncludeDeleted() QueryOption {
+	return func(query *gorm.DB) *gorm.DB {
+		return query.Unscoped()
+	}
+}
 
-	if err := fn(ctx); err != nil {
-		return err
+func applyQueryOptions(query *gorm.DB, opts []QueryOption) *gorm.DB {
+	for _, opt := range opts {
+		query = opt(query)
 	}
 
-	return tx.Commit().Error
+	return query
 }
@@ -0,0 +1,214 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+
+	"github.com/azusaanson/invest-api/domain"
+)
+
+func TestWithTx(t *testing.T) {
+	requireStore(t)
+	errAbort := errors.New("abort")
+
+	tests := []struct {
+		name       string
+		fail       func() error
+		wantErr    error
+		wantPanic  bool
+		wantStored bool
+	}{
+		{name: "commit", fail: func() error { return nil }, wantErr: nil, wantStored: true},
+		{name: "error rolls back", fail: func() error { return errAbort }, wantErr: errAbort, wantStored: false},
+		{name: "panic rolls back", fail: func() error { panic("abort") }, wantPanic: true, wantStored: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			var userID domain.UserID
+
+			run := func() (err error, panicked bool) {
+				defer func() {
+					if recover() != nil {
+						panicked = true
+					}
+				}()
+
+				return WithTx(ctx, testConn, func(txRepo Repositories) error {
+					user, err := domain.NewUserForCreate(randomTestUserName(t), "Tx7!qLmZ", string(domain.RoleUser), domain.DefaultPasswordPolicy)
+					if err != nil {
+						return err
+					}
+					if userID, err = txRepo.CreateUser(ctx, user); err != nil {
+						return err
+					}
+
+					// Half done: the user exists inside the transaction only.
+					if _, err := txRepo.GetUserByID(ctx, userID); err != nil {
+						return err
+					}
+
+					return tt.fail()
+				}), false
+			}
+
+			err, panicked := run()
+			if panicked != tt.wantPanic {
+				t.Fatalf("WithTx() panicked = %v, want %v", panicked, tt.wantPanic)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("WithTx() = %v, want %v", err, tt.wantErr)
+			}
+
+			_, err = testStore.GetUserByID(ctx, userID)
+			if stored := err == nil; stored != tt.wantStored {
+				t.Fatalf("user stored = %v (%v), want %v", stored, err, tt.wantStored)
+			}
+		})
+	}
+}
+
+func TestWithTxStoreOptions(t *testing.T) {
+	store := requireStore(t)
+	ctx := context.Background()
+	user := createTestUser(t, store)
+
+	tests := []struct {
+		name        string
+		opts        []StoreOption
+		wantWritten bool
+	}{
+		{name: "default interval", opts: nil, wantWritten: true},
+		{name: "interval passed in", opts: []StoreOption{WithSessionTouchInterval(time.Hour)}, wantWritten: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session, _ := createTestSession(t, store, user.ID(), time.Now().Add(2*time.Hour))
+			touchAt := time.Time(session.LastSeenAt()).Add(2 * DefaultSessionTouchInterval)
+
+			var written bool
+			err := WithTx(ctx, testConn, func(txRepo Repositories) error {
+				var err error
+				written, err = txRepo.TouchSession(ctx, session.ID(), touchAt)
+				return err
+			}, tt.opts...)
+			if err != nil {
+				t.Fatalf("WithTx() = %v", err)
+			}
+			if written != tt.wantWritten {
+				t.Fatalf("TouchSession() in WithTx() = %v, want %v", written, tt.wantWritten)
+			}
+		})
+	}
+}
+
+// newUnreachableStore points at a port nothing listens on, without the ping
+// and version query gorm would otherwise run on open. Any call that reaches
+// the network fails with a connection error rather than the context's.
+func newUnreachableStore(t *testing.T) StoreInterface {
+	t.Helper()
+
+	conn, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "invest:invest@tcp(127.0.0.1:1)/invest?parseTime=True&timeout=2s",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		NamingStrategy:       schema.NamingStrategy{SingularTable: true},
+		Logger:               logger.Default.LogMode(logger.Silent),
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open() = %v", err)
+	}
+
+	return NewStore(conn)
+}
+
+func TestCancelledContext(t *testing.T) {
+	store := newUnreachableStore(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	user, err := domain.NewUserForCreate("alice", "Tx7!qLmZ", string(domain.RoleUser), domain.DefaultPasswordPolicy)
+	if err != nil {
+		t.Fatalf("NewUserForCreate() = %v", err)
+	}
+	investedAt, err := domain.NewInvestedAt(time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("NewInvestedAt() = %v", err)
+	}
+	invest, err := domain.NewInvest(1, domain.NewAmountFromMinorUnits(100_00, domain.CurrencyUSD), domain.InvestTypeStock, investedAt)
+	if err != nil {
+		t.Fatalf("NewInvest() = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{name: "GetUserByID", call: func() error { _, err := store.GetUserByID(ctx, 1); return err }},
+		{name: "GetUserByName", call: func() error { _, err := store.GetUserByName(ctx, "alice"); return err }},
+		{name: "CreateUser", call: func() error { _, err := store.CreateUser(ctx, user); return err }},
+		{name: "BulkUpdateRole", call: func() error { _, err := store.BulkUpdateRole(ctx, []domain.UserID{1}, domain.RoleAdmin); return err }},
+		{name: "ListInvestsByUserID", call: func() error {
+			_, _, err := store.ListInvestsByUserID(ctx, 1, InvestFilter{}, NewPagination(10, 0))
+			return err
+		}},
+		{name: "CreateInvest", call: func() error { _, err := store.CreateInvest(ctx, invest); return err }},
+		{name: "ListActiveSessionsByUserID", call: func() error { _, err := store.ListActiveSessionsByUserID(ctx, 1, time.Now()); return err }},
+		{name: "RevokeAllSessions", call: func() error { _, err := store.RevokeAllSessions(ctx, 1); return err }},
+		{name: "ExecTx", call: func() error { return store.ExecTx(ctx, func(Repositories) error { return nil }) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			err := tt.call()
+			if !errors.Is(err, context.Canceled) {
+				t.Fatalf("%s() = %v, want %v", tt.name, err, context.Canceled)
+			}
+			if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+				t.Fatalf("%s() took %v, want it to return without waiting on the db", tt.name, elapsed)
+			}
+		})
+	}
+}
+
+func TestGettersNotFound(t *testing.T) {
+	store := requireStore(t)
+	ctx := context.Background()
+
+	refreshToken, err := domain.GenerateRefreshToken()
+	if err != nil {
+		t.Fatalf("GenerateRefreshToken() = %v", err)
+	}
+
+	// Ids are never reused and tokens are random, so nothing matches.
+	tests := []struct {
+		name    string
+		get     func() error
+		wantErr error
+	}{
+		{name: "GetUserByID", get: func() error { _, err := store.GetUserByID(ctx, 1<<62); return err }, wantErr: ErrUserNotFound},
+		{name: "GetUserByName", get: func() error { _, err := store.GetUserByName(ctx, "missing_8f3a2c1d"); return err }, wantErr: ErrUserNotFound},
+		{name: "GetSessionByRefreshToken", get: func() error { _, err := store.GetSessionByRefreshToken(ctx, refreshToken); return err }, wantErr: ErrSessionNotFound},
+		{name: "GetInvestByID", get: func() error { _, err := store.GetInvestByID(ctx, 1<<62); return err }, wantErr: ErrInvestNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.get(); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("%s() = %v, want %v", tt.name, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,4 @@
+-- A hash cannot be turned back into the raw token, so rolling back logs
+-- every user out.
+DELETE FROM `session`;
+ALTER TABLE `session` CHANGE `refresh_token_hash` `refresh_token` varchar(255) NOT NULL;
@@ -0,0 +1,4 @@
+-- Hash the raw tokens in place so existing sessions survive the deploy.
+-- SHA2(..., 256) is lowercase hex, the same as domain.RefreshToken.Hash.
+UPDATE `session` SET `refresh_token` = SHA2(`refresh_token`, 256);
+ALTER TABLE `session` CHANGE `refresh_token` `refresh_token_hash` varchar(255) NOT NULL;
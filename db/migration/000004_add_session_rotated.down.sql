@@ -0,0 +1,2 @@
+DROP INDEX `session_refresh_token_hash_idx` ON `session`;
+ALTER TABLE `session` DROP COLUMN `is_rotated`;
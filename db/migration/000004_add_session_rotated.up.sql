@@ -0,0 +1,2 @@
+ALTER TABLE `session` ADD `is_rotated` boolean NOT NULL DEFAULT false AFTER `is_blocked`;
+CREATE UNIQUE INDEX `session_refresh_token_hash_idx` ON `session` (`refresh_token_hash`);
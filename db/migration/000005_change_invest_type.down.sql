@@ -0,0 +1,2 @@
+UPDATE `invest` SET `type` = 'CEX' WHERE `type` = 'crypto';
+ALTER TABLE `invest` MODIFY `type` varchar(255) NOT NULL COMMENT 'CEX, DEX';
@@ -0,0 +1,2 @@
+UPDATE `invest` SET `type` = 'crypto' WHERE `type` IN ('CEX', 'DEX');
+ALTER TABLE `invest` MODIFY `type` varchar(255) NOT NULL COMMENT 'stock, bond, crypto, etf, cash';
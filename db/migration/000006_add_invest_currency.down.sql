@@ -0,0 +1,2 @@
+ALTER TABLE `invest` MODIFY `amount` decimal(15,2) COMMENT 'HKD';
+ALTER TABLE `invest` DROP COLUMN `currency`;
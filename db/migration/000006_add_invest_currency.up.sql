@@ -0,0 +1,2 @@
+ALTER TABLE `invest` ADD `currency` char(3) NOT NULL DEFAULT 'HKD' COMMENT 'ISO 4217' AFTER `amount`;
+ALTER TABLE `invest` MODIFY `amount` decimal(18,3) COMMENT 'in currency';
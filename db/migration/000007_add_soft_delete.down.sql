@@ -0,0 +1,3 @@
+ALTER TABLE `session` DROP COLUMN `deleted_at`;
+ALTER TABLE `invest` DROP COLUMN `deleted_at`;
+ALTER TABLE `user` DROP COLUMN `deleted_at`;
@@ -0,0 +1,3 @@
+ALTER TABLE `user` ADD `deleted_at` timestamp NULL DEFAULT NULL;
+ALTER TABLE `invest` ADD `deleted_at` timestamp NULL DEFAULT NULL;
+ALTER TABLE `session` ADD `deleted_at` timestamp NULL DEFAULT NULL;
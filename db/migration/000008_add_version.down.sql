@@ -0,0 +1 @@
+ALTER TABLE `invest` DROP COLUMN `version`;
@@ -0,0 +1 @@
+ALTER TABLE `invest` ADD `version` bigint unsigned NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+DROP INDEX `user_canonical_name_idx` ON `user`;
+
+ALTER TABLE `user` DROP COLUMN `canonical_name`;
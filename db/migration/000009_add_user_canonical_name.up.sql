@@ -0,0 +1,6 @@
+-- NULL until db.BackfillCanonicalNames fills it with UserName.Canonical(),
+-- which SQL cannot reproduce (case folding and NFC). NULLs do not collide in
+-- a unique index, so the index can be created before the backfill.
+ALTER TABLE `user` ADD `canonical_name` varchar(255) NULL AFTER `name`;
+
+CREATE UNIQUE INDEX `user_canonical_name_idx` ON `user` (`canonical_name`);
@@ -0,0 +1 @@
+ALTER TABLE `user` ADD `password_changed_at` timestamp NOT NULL DEFAULT (now()) AFTER `role`;
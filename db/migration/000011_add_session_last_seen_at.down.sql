@@ -0,0 +1 @@
+ALTER TABLE `session` DROP COLUMN `last_seen_at`;
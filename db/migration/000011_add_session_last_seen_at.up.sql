@@ -0,0 +1,3 @@
+ALTER TABLE `session` ADD `last_seen_at` timestamp NOT NULL DEFAULT (now()) AFTER `expires_at`;
+
+UPDATE `session` SET `last_seen_at` = `created_at`;
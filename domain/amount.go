@@ -0,0 +1,131 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrAmountInvalid    = newFieldError("amount", "invalid", "amount: invalid format")
+	ErrAmountOverflow   = newFieldError("amount", "out_of_range", "amount: out of range")
+	ErrCurrencyMismatch = errors.New("amount: currency mismatch")
+)
+
+// Amount is a fixed-point money value held in the minor units of its currency
+// (e.g. cents for USD, yen for JPY) so that sums do not drift the way float64
+// does.
+type Amount struct {
+	minorUnits int64
+	currency   Currency
+}
+
+func (a Amount) MinorUnits() int64  { return a.minorUnits }
+func (a Amount) Currency() Currency { return a.currency }
+
+func NewAmountFromMinorUnits(v int64, currency Currency) Amount {
+	return Amount{minorUnits: v, currency: currency}
+}
+
+func NewZeroAmount(currency Currency) Amount {
+	return Amount{currency: currency}
+}
+
+func NewAmountFromString(v string, currency Currency) (Amount, error) {
+	decimalPlaces := currency.DecimalPlaces()
+	minorPerMajor := currency.minorPerMajor()
+
+	v = strings.TrimSpace(v)
+
+	negative := strings.HasPrefix(v, "-")
+	v = strings.TrimPrefix(v, "-")
+
+	major, minor, hasMinor := strings.Cut(v, ".")
+	if major == "" || (hasMinor && minor == "") {
+		return Amount{}, errors.WithStack(ErrAmountInvalid)
+	}
+
+	// Digits past the currency's precision are only allowed as padding zeros,
+	// as returned by the decimal column.
+	if len(minor) > decimalPlaces {
+		if strings.Trim(minor[decimalPlaces:], "0") != "" {
+			return Amount{}, errors.WithStack(ErrAmountInvalid)
+		}
+		minor = minor[:decimalPlaces]
+	}
+	minor += strings.Repeat("0", decimalPlaces-len(minor))
+
+	for _, r := range major + minor {
+		if r < '0' || '9' < r {
+			return Amount{}, errors.WithStack(ErrAmountInvalid)
+		}
+	}
+
+	majorUnits, err := strconv.ParseInt(major, 10, 64)
+	if err != nil {
+		return Amount{}, errors.Wrap(ErrAmountOverflow, err.Error())
+	}
+
+	var minorUnits int64
+	if minor != "" {
+		minorUnits, _ = strconv.ParseInt(minor, 10, 64)
+	}
+
+	if majorUnits > (math.MaxInt64-minorUnits)/minorPerMajor {
+		return Amount{}, errors.WithStack(ErrAmountOverflow)
+	}
+
+	total := majorUnits*minorPerMajor + minorUnits
+	if negative {
+		total = -total
+	}
+
+	return Amount{minorUnits: total, currency: currency}, nil
+}
+
+func (a Amount) Add(other Amount) (Amount, error) {
+	if a.currency != other.currency {
+		return Amount{}, errors.WithStack(ErrCurrencyMismatch)
+	}
+
+	return Amount{minorUnits: a.minorUnits + other.minorUnits, currency: a.currency}, nil
+}
+
+func (a Amount) Sub(other Amount) (Amount, error) {
+	if a.currency != other.currency {
+		return Amount{}, errors.WithStack(ErrCurrencyMismatch)
+	}
+
+	return Amount{minorUnits: a.minorUnits - other.minorUnits, currency: a.currency}, nil
+}
+
+func (a Amount) IsPositive() bool {
+	return a.minorUnits > 0
+}
+
+// String formats the value with the currency's decimal places, e.g. "12.50"
+// for USD and "1250" for JPY. The currency code is not included.
+func (a Amount) String() string {
+	sign := ""
+	v := a.minorUnits
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+
+	decimalPlaces := a.currency.DecimalPlaces()
+	if decimalPlaces == 0 {
+		return fmt.Sprintf("%s%d", sign, v)
+	}
+
+	minorPerMajor := a.currency.minorPerMajor()
+	return fmt.Sprintf("%s%d.%0*d", sign, v/minorPerMajor, decimalPlaces, v%minorPerMajor)
+}
+
+// Float64 is meant for display and export only; keep calculations in Amount.
+func (a Amount) Float64() float64 {
+	return float64(a.minorUnits) / float64(a.currency.minorPerMajor())
+}
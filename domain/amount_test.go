@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAmountSumDoesNotDrift(t *testing.T) {
+	const n = 1000
+
+	sum := NewZeroAmount(CurrencyUSD)
+	floatSum := 0.0
+	for i := 0; i < n; i++ {
+		tenCents, err := NewAmountFromString("0.10", CurrencyUSD)
+		if err != nil {
+			t.Fatalf("NewAmountFromString() = %v", err)
+		}
+		if sum, err = sum.Add(tenCents); err != nil {
+			t.Fatalf("Add() = %v", err)
+		}
+		floatSum += 0.1
+	}
+
+	if sum.String() != "100.00" {
+		t.Fatalf("sum = %s, want 100.00", sum)
+	}
+	if sum.Float64() != 100 {
+		t.Fatalf("Float64() = %v, want 100", sum.Float64())
+	}
+	// Documents why Amount exists: the float sum is already off.
+	if floatSum == 100 {
+		t.Fatalf("float sum = %v, expected it to drift", floatSum)
+	}
+}
+
+func TestNewAmountFromString(t *testing.T) {
+	tests := []struct {
+		name     string
+		v        string
+		currency Currency
+		want     int64
+		wantErr  error
+	}{
+		{name: "two decimals", v: "123.45", currency: CurrencyUSD, want: 12345, wantErr: nil},
+		{name: "one decimal", v: "123.4", currency: CurrencyUSD, want: 12340, wantErr: nil},
+		{name: "integer", v: "123", currency: CurrencyUSD, want: 12300, wantErr: nil},
+		{name: "padding zeros", v: "123.4500", currency: CurrencyUSD, want: 12345, wantErr: nil},
+		{name: "negative", v: "-0.05", currency: CurrencyUSD, want: -5, wantErr: nil},
+		{name: "too precise", v: "123.456", currency: CurrencyUSD, want: 0, wantErr: ErrAmountInvalid},
+		{name: "not a number", v: "12a.00", currency: CurrencyUSD, want: 0, wantErr: ErrAmountInvalid},
+		{name: "empty", v: "", currency: CurrencyUSD, want: 0, wantErr: ErrAmountInvalid},
+		{name: "trailing dot", v: "1.", currency: CurrencyUSD, want: 0, wantErr: ErrAmountInvalid},
+		{name: "overflow", v: "92233720368547758.08", currency: CurrencyUSD, want: 0, wantErr: ErrAmountOverflow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewAmountFromString(tt.v, tt.currency)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewAmountFromString(%q) = %v, want %v", tt.v, err, tt.wantErr)
+			}
+			if got.MinorUnits() != tt.want {
+				t.Fatalf("NewAmountFromString(%q) = %d minor units, want %d", tt.v, got.MinorUnits(), tt.want)
+			}
+		})
+	}
+}
+
+func TestAmountSub(t *testing.T) {
+	a := NewAmountFromMinorUnits(1000, CurrencyUSD)
+	b := NewAmountFromMinorUnits(1250, CurrencyUSD)
+
+	got, err := a.Sub(b)
+	if err != nil {
+		t.Fatalf("Sub() = %v", err)
+	}
+	if got.String() != "-2.50" || got.IsPositive() {
+		t.Fatalf("Sub() = %s, want -2.50", got)
+	}
+}
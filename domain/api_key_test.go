@@ -0,0 +1,184 @@
+package domain
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var apiKeyPattern = regexp.MustCompile(`^ivk_live_[0-9a-f]{12}\.[A-Za-z0-9_-]{43}$`)
+
+func TestGenerateAPIKey(t *testing.T) {
+	raw, prefix, hashed, err := GenerateAPIKey()
+	if err != nil {
+		t.Fatalf("GenerateAPIKey() = %v", err)
+	}
+	other, _, _, err := GenerateAPIKey()
+	if err != nil {
+		t.Fatalf("GenerateAPIKey() = %v", err)
+	}
+
+	if !apiKeyPattern.MatchString(raw) {
+		t.Fatalf("GenerateAPIKey() raw = %q, want it to match %s", raw, apiKeyPattern)
+	}
+	if !strings.HasPrefix(raw, prefix+".") {
+		t.Fatalf("GenerateAPIKey() prefix = %q, want the start of %q", prefix, raw)
+	}
+	if len(hashed) != 64 || strings.Contains(raw, hashed) {
+		t.Fatalf("GenerateAPIKey() hashed = %q, want a sha256 hex not in the raw key", hashed)
+	}
+	if other == raw {
+		t.Fatal("GenerateAPIKey() returned the same key twice")
+	}
+}
+
+func TestVerifyAPIKey(t *testing.T) {
+	raw, prefix, hashed, err := GenerateAPIKey()
+	if err != nil {
+		t.Fatalf("GenerateAPIKey() = %v", err)
+	}
+	other, _, _, err := GenerateAPIKey()
+	if err != nil {
+		t.Fatalf("GenerateAPIKey() = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		hashed string
+		raw    string
+		want   bool
+	}{
+		{name: "match", hashed: hashed, raw: raw, want: true},
+		{name: "other key", hashed: hashed, raw: other, want: false},
+		{name: "prefix only", hashed: hashed, raw: prefix, want: false},
+		{name: "missing scheme", hashed: hashed, raw: strings.TrimPrefix(raw, APIKeyScheme), want: false},
+		{name: "secret altered", hashed: hashed, raw: raw + "x", want: false},
+		{name: "empty raw", hashed: hashed, raw: "", want: false},
+		{name: "empty hash", hashed: "", raw: raw, want: false},
+		{name: "hash as key", hashed: hashed, raw: prefix + "." + hashed, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifyAPIKey(tt.hashed, tt.raw); got != tt.want {
+				t.Fatalf("VerifyAPIKey() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitAPIKey(t *testing.T) {
+	tests := []struct {
+		name       string
+		raw        string
+		wantPrefix string
+		wantSecret string
+		wantErr    error
+	}{
+		{name: "valid", raw: "ivk_live_abc.secret", wantPrefix: "ivk_live_abc", wantSecret: "secret", wantErr: nil},
+		{name: "no scheme", raw: "abc.secret", wantErr: ErrAPIKeyInvalid},
+		{name: "no separator", raw: "ivk_live_abc", wantErr: ErrAPIKeyInvalid},
+		{name: "no id", raw: "ivk_live_.secret", wantErr: ErrAPIKeyInvalid},
+		{name: "no secret", raw: "ivk_live_abc.", wantErr: ErrAPIKeyInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefix, secret, err := SplitAPIKey(tt.raw)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SplitAPIKey(%q) = %v, want %v", tt.raw, err, tt.wantErr)
+			}
+			if prefix != tt.wantPrefix || secret != tt.wantSecret {
+				t.Fatalf("SplitAPIKey(%q) = %q, %q, want %q, %q", tt.raw, prefix, secret, tt.wantPrefix, tt.wantSecret)
+			}
+		})
+	}
+}
+
+func TestNewAPIKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		role    string
+		wantErr error
+	}{
+		{name: "user", role: string(RoleUser), wantErr: nil},
+		{name: "admin", role: string(RoleAdmin), wantErr: nil},
+		{name: "unknown role", role: "owner", wantErr: ErrUserRoleInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, raw, err := NewAPIKey(tt.role)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewAPIKey(%q) = %v, want %v", tt.role, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if key.Role() != UserRole(tt.role) {
+				t.Fatalf("Role() = %q, want %q", key.Role(), tt.role)
+			}
+			if !key.Verify(raw) {
+				t.Fatal("Verify(raw) = false, want true")
+			}
+		})
+	}
+}
+
+func TestAPIKeyVerify(t *testing.T) {
+	key, raw, err := NewAPIKey(string(RoleUser))
+	if err != nil {
+		t.Fatalf("NewAPIKey() = %v", err)
+	}
+	_, secret, err := SplitAPIKey(raw)
+	if err != nil {
+		t.Fatalf("SplitAPIKey() = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		raw  string
+		want bool
+	}{
+		{name: "match", raw: raw, want: true},
+		{name: "secret under another prefix", raw: APIKeyScheme + "000000000000." + secret, want: false},
+		{name: "malformed", raw: secret, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := key.Verify(tt.raw); got != tt.want {
+				t.Fatalf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewAPIKeyFromSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		prefix  string
+		hashed  string
+		role    string
+		wantErr error
+	}{
+		{name: "valid", prefix: "ivk_live_abc", hashed: "hash", role: string(RoleAdmin), wantErr: nil},
+		{name: "scheme only", prefix: APIKeyScheme, hashed: "hash", role: string(RoleAdmin), wantErr: ErrAPIKeyPrefixInvalid},
+		{name: "other scheme", prefix: "key_abc", hashed: "hash", role: string(RoleAdmin), wantErr: ErrAPIKeyPrefixInvalid},
+		{name: "empty hash", prefix: "ivk_live_abc", hashed: "", role: string(RoleAdmin), wantErr: ErrAPIKeyHashEmpty},
+		{name: "unknown role", prefix: "ivk_live_abc", hashed: "hash", role: "owner", wantErr: ErrUserRoleInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := NewAPIKeyFromSource(tt.prefix, tt.hashed, tt.role)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewAPIKeyFromSource() = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (key.Prefix() != tt.prefix || key.Hashed() != tt.hashed) {
+				t.Fatalf("NewAPIKeyFromSource() = %q, %q, want %q, %q", key.Prefix(), key.Hashed(), tt.prefix, tt.hashed)
+			}
+		})
+	}
+}
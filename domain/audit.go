@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type AuditAction string
+
+const (
+	AuditActionLogin          AuditAction = "login"
+	AuditActionLogout         AuditAction = "logout"
+	AuditActionPasswordChange AuditAction = "password_change"
+	AuditActionRoleChange     AuditAction = "role_change"
+)
+
+type AuditOutcome string
+
+const (
+	AuditOutcomeSuccess AuditOutcome = "success"
+	AuditOutcomeFailure AuditOutcome = "failure"
+)
+
+// AuditEvent records a security relevant action. UserID is zero when the
+// action failed before a user was identified, e.g. a login with an unknown
+// name.
+type AuditEvent struct {
+	Action    AuditAction
+	UserID    UserID
+	Timestamp time.Time
+	ClientIp  ClientIp
+	UserAgent UserAgent
+	Outcome   AuditOutcome
+	Reason    string
+}
+
+func NewAuditEvent(
+	action AuditAction,
+	userID UserID,
+	now time.Time,
+	meta *UserMetaData,
+	outcome AuditOutcome,
+	reason string,
+) AuditEvent {
+	event := AuditEvent{
+		Action:    action,
+		UserID:    userID,
+		Timestamp: now,
+		Outcome:   outcome,
+		Reason:    reason,
+	}
+	if meta != nil {
+		event.ClientIp = meta.ClientIp()
+		event.UserAgent = meta.UserAgent()
+	}
+
+	return event
+}
+
+// AuditLogger must not fail the action being audited, so Log returns
+// nothing; implementations deal with their own errors.
+type AuditLogger interface {
+	Log(ctx context.Context, event AuditEvent)
+}
+
+type NopAuditLogger struct{}
+
+func (NopAuditLogger) Log(context.Context, AuditEvent) {}
+
+// MemoryAuditLogger keeps every event, for tests and local debugging.
+type MemoryAuditLogger struct {
+	mu     sync.Mutex
+	events []AuditEvent
+}
+
+func NewMemoryAuditLogger() *MemoryAuditLogger {
+	return &MemoryAuditLogger{}
+}
+
+func (l *MemoryAuditLogger) Log(_ context.Context, event AuditEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.events = append(l.events, event)
+}
+
+func (l *MemoryAuditLogger) Events() []AuditEvent {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	events := make([]AuditEvent, len(l.events))
+	copy(events, l.events)
+
+	return events
+}
@@ -5,16 +5,35 @@ import (
 	"time"
 
 	"github.com/aead/chacha20poly1305"
-	"github.com/google/uuid"
 	"github.com/o1egl/paseto"
 	"github.com/pkg/errors"
 )
 
 type TokenMaker interface {
-	CreateToken(userID UserID, duration time.Duration) (Token, *Payload, error)
+	CreateToken(user *User, duration time.Duration) (Token, *Payload, error)
 	VerifyToken(token Token) (*Payload, error)
 }
 
+const (
+	TokenMakerPaseto = "paseto"
+	TokenMakerJWT    = "jwt"
+)
+
+var ErrTokenMakerUnknown = errors.New("token maker: unknown type")
+
+// NewTokenMaker lets the backend be picked by configuration. Both makers
+// issue the same Payload, so callers do not depend on the token format.
+func NewTokenMaker(kind string, symmetricKey SymmetricKey) (TokenMaker, error) {
+	switch kind {
+	case TokenMakerPaseto:
+		return NewPasetoMaker(symmetricKey)
+	case TokenMakerJWT:
+		return NewJWTMaker(symmetricKey)
+	}
+
+	return nil, errors.WithStack(ErrTokenMakerUnknown)
+}
+
 type PasetoMaker struct {
 	paseto       *paseto.V2
 	symmetricKey SymmetricKey
@@ -33,8 +52,8 @@ func NewPasetoMaker(symmetricKey SymmetricKey) (TokenMaker, error) {
 	return maker, nil
 }
 
-func (maker *PasetoMaker) CreateToken(userID UserID, duration time.Duration) (Token, *Payload, error) {
-	payload, err := NewPayload(userID, duration)
+func (maker *PasetoMaker) CreateToken(user *User, duration time.Duration) (Token, *Payload, error) {
+	payload, err := NewPayload(user.ID(), user.Role(), duration)
 	if err != nil {
 		return "", nil, errors.WithStack(err)
 	}
@@ -68,11 +87,16 @@ func (maker *PasetoMaker) VerifyToken(token Token) (*Payload, error) {
 	return payload, nil
 }
 
+// Payload carries the role and its resolved permissions as scopes so requests
+// can be authorized without a db lookup. A permission removed from a role
+// stays in issued tokens until they expire or are revoked.
 type Payload struct {
-	ID        SessionUUID `json:"id"`
-	UserID    UserID      `json:"user_id"`
-	IssuedAt  time.Time   `json:"issued_at"`
-	ExpiresAt ExpiresAt   `json:"expired_at"`
+	ID        SessionID `json:"id"`
+	UserID    UserID    `json:"user_id"`
+	Role      UserRole  `json:"role"`
+	Scopes    []string  `json:"scopes"`
+	IssuedAt  time.Time `json:"issued_at"`
+	ExpiresAt ExpiresAt `json:"expired_at"`
 }
 
 var (
@@ -80,34 +104,61 @@ var (
 	ErrExpiredToken = errors.New("token has expired")
 )
 
-func NewPayload(userID UserID, duration time.Duration) (*Payload, error) {
-	tokenID, err := uuid.NewRandom()
+func NewPayload(userID UserID, role UserRole, duration time.Duration) (*Payload, error) {
+	return NewPayloadWithClock(userID, role, duration, SystemClock{})
+}
+
+// NewPayloadWithClock issues the payload at clock.Now() and expires it
+// duration later.
+func NewPayloadWithClock(userID UserID, role UserRole, duration time.Duration, clock Clock) (*Payload, error) {
+	sessionID, err := NewSessionID()
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	sessionID, err := NewSessionUUID(tokenID)
+
+	issuedAt := clock.Now()
+	expiresAt, err := NewExpiresAt(issuedAt.Add(duration))
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
-	expiresAt, err := NewExpiresAt(time.Now().Add(duration))
+	permissions := role.Permissions()
+	scopes := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		scopes = append(scopes, string(permission))
+	}
 
 	payload := &Payload{
 		ID:        sessionID,
 		UserID:    userID,
-		IssuedAt:  time.Now(),
+		Role:      role,
+		Scopes:    scopes,
+		IssuedAt:  issuedAt,
 		ExpiresAt: expiresAt,
 	}
 	return payload, nil
 }
 
 func (payload *Payload) Valid() error {
-	if time.Now().After(time.Time(payload.ExpiresAt)) {
+	return payload.ValidWithClock(SystemClock{})
+}
+
+func (payload *Payload) ValidWithClock(clock Clock) error {
+	if clock.Now().After(time.Time(payload.ExpiresAt)) {
 		return errors.WithStack(ErrExpiredToken)
 	}
 	return nil
 }
 
+func (payload *Payload) HasScope(scope string) bool {
+	for _, s := range payload.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
 type SymmetricKey []byte
 
 func NewSymmetricKeyFromString(v string) (SymmetricKey, error) {
@@ -116,6 +167,12 @@ func NewSymmetricKeyFromString(v string) (SymmetricKey, error) {
 
 type Token string
 
+var ErrTokenEmpty = newFieldError("token", "empty", "token: must not be empty")
+
 func NewToken(v string) (Token, error) {
+	if v == "" {
+		return "", errors.WithStack(ErrTokenEmpty)
+	}
+
 	return Token(v), nil
 }
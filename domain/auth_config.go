@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrAuthConfigTTLNegative      = errors.New("auth config: ttl must not be negative")
+	ErrAuthConfigAccessTTLTooLong = errors.New("auth config: access token ttl must be shorter than refresh token ttl")
+)
+
+// AuthConfig holds the lifetimes of what login and session rotation issue.
+// SessionTTL is the lifetime of the session created at login; each rotation
+// then issues a session that lives RefreshTokenTTL.
+type AuthConfig struct {
+	accessTokenTTL  time.Duration
+	refreshTokenTTL time.Duration
+	sessionTTL      time.Duration
+}
+
+func (c AuthConfig) AccessTokenTTL() time.Duration  { return c.accessTokenTTL }
+func (c AuthConfig) RefreshTokenTTL() time.Duration { return c.refreshTokenTTL }
+func (c AuthConfig) SessionTTL() time.Duration      { return c.sessionTTL }
+
+// NewAuthConfig defaults a zero sessionTTL to refreshTokenTTL.
+func NewAuthConfig(accessTokenTTL, refreshTokenTTL, sessionTTL time.Duration) (AuthConfig, error) {
+	if accessTokenTTL < 0 || refreshTokenTTL < 0 || sessionTTL < 0 {
+		return AuthConfig{}, errors.WithStack(ErrAuthConfigTTLNegative)
+	}
+
+	if accessTokenTTL >= refreshTokenTTL {
+		return AuthConfig{}, errors.WithStack(ErrAuthConfigAccessTTLTooLong)
+	}
+
+	if sessionTTL == 0 {
+		sessionTTL = refreshTokenTTL
+	}
+
+	return AuthConfig{
+		accessTokenTTL:  accessTokenTTL,
+		refreshTokenTTL: refreshTokenTTL,
+		sessionTTL:      sessionTTL,
+	}, nil
+}
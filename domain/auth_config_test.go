@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewAuthConfig(t *testing.T) {
+	tests := []struct {
+		name           string
+		access         time.Duration
+		refresh        time.Duration
+		session        time.Duration
+		wantSessionTTL time.Duration
+		wantErr        error
+	}{
+		{name: "valid", access: 15 * time.Minute, refresh: 24 * time.Hour, session: 12 * time.Hour, wantSessionTTL: 12 * time.Hour, wantErr: nil},
+		{name: "zero session defaults to refresh", access: 15 * time.Minute, refresh: 24 * time.Hour, session: 0, wantSessionTTL: 24 * time.Hour, wantErr: nil},
+		{name: "access longer than refresh", access: 48 * time.Hour, refresh: 24 * time.Hour, session: 0, wantErr: ErrAuthConfigAccessTTLTooLong},
+		{name: "access equal to refresh", access: 24 * time.Hour, refresh: 24 * time.Hour, session: 0, wantErr: ErrAuthConfigAccessTTLTooLong},
+		{name: "negative access", access: -time.Minute, refresh: 24 * time.Hour, session: 0, wantErr: ErrAuthConfigTTLNegative},
+		{name: "negative refresh", access: 15 * time.Minute, refresh: -time.Hour, session: 0, wantErr: ErrAuthConfigTTLNegative},
+		{name: "negative session", access: 15 * time.Minute, refresh: 24 * time.Hour, session: -time.Hour, wantErr: ErrAuthConfigTTLNegative},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := NewAuthConfig(tt.access, tt.refresh, tt.session)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewAuthConfig(%v, %v, %v) = %v, want %v", tt.access, tt.refresh, tt.session, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if config.AccessTokenTTL() != tt.access || config.RefreshTokenTTL() != tt.refresh || config.SessionTTL() != tt.wantSessionTTL {
+				t.Fatalf("NewAuthConfig() = %v %v %v, want %v %v %v",
+					config.AccessTokenTTL(), config.RefreshTokenTTL(), config.SessionTTL(), tt.access, tt.refresh, tt.wantSessionTTL)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewPasetoMakerKeySize(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{name: "31 bytes", key: testSymmetricKey[:31], wantErr: true},
+		{name: "32 bytes", key: testSymmetricKey, wantErr: false},
+		{name: "33 bytes", key: testSymmetricKey + "x", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewPasetoMaker(SymmetricKey(tt.key)); (err != nil) != tt.wantErr {
+				t.Fatalf("NewPasetoMaker() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPasetoMaker(t *testing.T) {
+	maker, err := NewPasetoMaker(SymmetricKey(testSymmetricKey))
+	if err != nil {
+		t.Fatalf("NewPasetoMaker() = %v", err)
+	}
+	otherMaker, err := NewPasetoMaker(SymmetricKey(strings.Repeat("x", 32)))
+	if err != nil {
+		t.Fatalf("NewPasetoMaker() = %v", err)
+	}
+	user := newTestUser(t, RoleUser)
+
+	valid, payload, err := maker.CreateToken(user, time.Minute)
+	if err != nil {
+		t.Fatalf("CreateToken() = %v", err)
+	}
+	expired, _, err := maker.CreateToken(user, -time.Minute)
+	if err != nil {
+		t.Fatalf("CreateToken() = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		maker   TokenMaker
+		token   Token
+		wantErr error
+	}{
+		{name: "valid", maker: maker, token: valid, wantErr: nil},
+		{name: "expired", maker: maker, token: expired, wantErr: ErrExpiredToken},
+		{name: "wrong key", maker: otherMaker, token: valid, wantErr: ErrInvalidToken},
+		{name: "malformed", maker: maker, token: "v2.local.not-a-token", wantErr: ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.maker.VerifyToken(tt.token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("VerifyToken() = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if got.ID != payload.ID || got.UserID != user.ID() || got.Role != user.Role() {
+				t.Fatalf("VerifyToken() = %+v, want %+v", got, payload)
+			}
+		})
+	}
+}
+
+func TestNewTokenMaker(t *testing.T) {
+	tests := []struct {
+		kind    string
+		wantErr error
+	}{
+		{kind: TokenMakerPaseto, wantErr: nil},
+		{kind: TokenMakerJWT, wantErr: nil},
+		{kind: "macaroon", wantErr: ErrTokenMakerUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			if _, err := NewTokenMaker(tt.kind, SymmetricKey(testSymmetricKey)); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewTokenMaker(%q) = %v, want %v", tt.kind, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTokenScopes(t *testing.T) {
+	jwtMaker, err := NewJWTMaker(SymmetricKey(testSymmetricKey))
+	if err != nil {
+		t.Fatalf("NewJWTMaker() = %v", err)
+	}
+	pasetoMaker, err := NewPasetoMaker(SymmetricKey(testSymmetricKey))
+	if err != nil {
+		t.Fatalf("NewPasetoMaker() = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		maker TokenMaker
+		role  UserRole
+		scope Permission
+		want  bool
+	}{
+		{name: "jwt admin", maker: jwtMaker, role: RoleAdmin, scope: PermissionDeleteUser, want: true},
+		{name: "jwt user", maker: jwtMaker, role: RoleUser, scope: PermissionDeleteUser, want: false},
+		{name: "jwt user own invests", maker: jwtMaker, role: RoleUser, scope: PermissionCreateInvest, want: true},
+		{name: "paseto admin", maker: pasetoMaker, role: RoleAdmin, scope: PermissionDeleteUser, want: true},
+		{name: "paseto user", maker: pasetoMaker, role: RoleUser, scope: PermissionDeleteUser, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, _, err := tt.maker.CreateToken(newTestUser(t, tt.role), time.Minute)
+			if err != nil {
+				t.Fatalf("CreateToken() = %v", err)
+			}
+
+			// Scopes must survive the round trip, as that is all a gateway sees.
+			payload, err := tt.maker.VerifyToken(token)
+			if err != nil {
+				t.Fatalf("VerifyToken() = %v", err)
+			}
+			if got := payload.HasScope(string(tt.scope)); got != tt.want {
+				t.Fatalf("HasScope(%s) = %v, want %v (scopes %v)", tt.scope, got, tt.want, payload.Scopes)
+			}
+		})
+	}
+}
+
+func TestNewPayloadWithClock(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration time.Duration
+		advance  time.Duration
+		wantErr  error
+	}{
+		{name: "fresh", duration: time.Minute, advance: 0, wantErr: nil},
+		{name: "at expiry", duration: time.Minute, advance: time.Minute, wantErr: nil},
+		{name: "past expiry", duration: time.Minute, advance: time.Minute + time.Second, wantErr: ErrExpiredToken},
+		{name: "issued expired", duration: -time.Minute, advance: 0, wantErr: ErrExpiredToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newFakeClock()
+			issuedAt := clock.Now()
+
+			payload, err := NewPayloadWithClock(1, RoleUser, tt.duration, clock)
+			if err != nil {
+				t.Fatalf("NewPayloadWithClock() = %v", err)
+			}
+			if !payload.IssuedAt.Equal(issuedAt) || !time.Time(payload.ExpiresAt).Equal(issuedAt.Add(tt.duration)) {
+				t.Fatalf("IssuedAt, ExpiresAt = %v, %v, want %v, %v", payload.IssuedAt, payload.ExpiresAt, issuedAt, issuedAt.Add(tt.duration))
+			}
+
+			clock.Advance(tt.advance)
+			if err := payload.ValidWithClock(clock); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ValidWithClock() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
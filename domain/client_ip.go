@@ -0,0 +1,143 @@
+package domain
+
+import (
+	"net/netip"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ClientIp is stored in its canonical form, with IPv4-mapped IPv6 addresses
+// unmapped to IPv4. The empty value means the address is unknown.
+type ClientIp string
+
+var ErrClientIpInvalid = newFieldError("client_ip", "invalid", "client ip: invalid address")
+
+// NewClientIp accepts a bare address or an address with a port, such as the
+// remote address of a connection.
+func NewClientIp(v string) (ClientIp, error) {
+	if v == "" {
+		return ClientIp(""), nil
+	}
+
+	addr, err := netip.ParseAddr(v)
+	if err != nil {
+		addrPort, portErr := netip.ParseAddrPort(v)
+		if portErr != nil {
+			return ClientIp(""), errors.Wrap(ErrClientIpInvalid, err.Error())
+		}
+		addr = addrPort.Addr()
+	}
+
+	return ClientIp(addr.Unmap().String()), nil
+}
+
+func (c ClientIp) addr() (netip.Addr, bool) {
+	addr, err := netip.ParseAddr(string(c))
+	if err != nil {
+		return netip.Addr{}, false
+	}
+
+	return addr, true
+}
+
+func (c ClientIp) Is4() bool {
+	addr, ok := c.addr()
+	return ok && addr.Is4()
+}
+
+func (c ClientIp) Is6() bool {
+	addr, ok := c.addr()
+	return ok && addr.Is6()
+}
+
+// IsPrivate reports private, loopback, link-local and unspecified addresses,
+// which usually mean a misconfigured proxy rather than a real client.
+func (c ClientIp) IsPrivate() bool {
+	addr, ok := c.addr()
+	if !ok {
+		return false
+	}
+
+	return addr.IsPrivate() ||
+		addr.IsLoopback() ||
+		addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() ||
+		addr.IsUnspecified()
+}
+
+func (c ClientIp) IsPublic() bool {
+	_, ok := c.addr()
+	return ok && !c.IsPrivate()
+}
+
+const (
+	anonymizedIPv4Bits = 24
+	anonymizedIPv6Bits = 48
+)
+
+// Anonymize zeroes the last octet of an IPv4 address and the last 80 bits of
+// an IPv6 address, which is what analytics may keep. An unknown address stays
+// unknown.
+func (c ClientIp) Anonymize() ClientIp {
+	addr, ok := c.addr()
+	if !ok {
+		return c
+	}
+
+	bits := anonymizedIPv6Bits
+	if addr.Is4() {
+		bits = anonymizedIPv4Bits
+	}
+
+	prefix, err := addr.WithZone("").Prefix(bits)
+	if err != nil {
+		return ClientIp("")
+	}
+
+	return ClientIp(prefix.Addr().String())
+}
+
+var ErrClientIpNotFound = errors.New("client ip: no untrusted address in forwarded chain")
+
+// ClientIpFromForwardedFor walks the X-Forwarded-For chain from the right,
+// starting at the direct remote address, and returns the first address that
+// is not a trusted proxy. Entries left of it may be forged by the client and
+// are ignored.
+func ClientIpFromForwardedFor(xff string, trustedProxies []netip.Prefix, remoteAddr string) (ClientIp, error) {
+	chain := []string{}
+	for _, entry := range strings.Split(xff, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			chain = append(chain, entry)
+		}
+	}
+	chain = append(chain, remoteAddr)
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		clientIp, err := NewClientIp(chain[i])
+		if err != nil {
+			return ClientIp(""), errors.WithStack(err)
+		}
+
+		addr, ok := clientIp.addr()
+		if !ok {
+			return ClientIp(""), errors.WithStack(ErrClientIpNotFound)
+		}
+
+		if !isTrustedProxy(addr, trustedProxies) {
+			return clientIp, nil
+		}
+	}
+
+	return ClientIp(""), errors.WithStack(ErrClientIpNotFound)
+}
+
+func isTrustedProxy(addr netip.Addr, trustedProxies []netip.Prefix) bool {
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
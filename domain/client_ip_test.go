@@ -0,0 +1,141 @@
+package domain
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+func TestNewClientIp(t *testing.T) {
+	tests := []struct {
+		name    string
+		v       string
+		want    ClientIp
+		wantIs4 bool
+		wantIs6 bool
+		wantErr error
+	}{
+		{name: "ipv4", v: "203.0.113.42", want: "203.0.113.42", wantIs4: true, wantErr: nil},
+		{name: "ipv4 with port", v: "203.0.113.42:443", want: "203.0.113.42", wantIs4: true, wantErr: nil},
+		{name: "ipv6", v: "2001:db8::1", want: "2001:db8::1", wantIs6: true, wantErr: nil},
+		{name: "ipv6 with port", v: "[2001:db8::1]:443", want: "2001:db8::1", wantIs6: true, wantErr: nil},
+		{name: "ipv4 mapped ipv6", v: "::ffff:203.0.113.42", want: "203.0.113.42", wantIs4: true, wantErr: nil},
+		{name: "unknown", v: "", want: "", wantErr: nil},
+		{name: "junk", v: "not-an-ip", want: "", wantErr: ErrClientIpInvalid},
+		{name: "out of range", v: "256.0.0.1", want: "", wantErr: ErrClientIpInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewClientIp(tt.v)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewClientIp(%q) = %v, want %v", tt.v, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("NewClientIp(%q) = %q, want %q", tt.v, got, tt.want)
+			}
+			if got.Is4() != tt.wantIs4 || got.Is6() != tt.wantIs6 {
+				t.Fatalf("Is4() = %v, Is6() = %v, want %v, %v", got.Is4(), got.Is6(), tt.wantIs4, tt.wantIs6)
+			}
+		})
+	}
+}
+
+func TestClientIpIsPrivate(t *testing.T) {
+	tests := []struct {
+		name        string
+		ip          ClientIp
+		wantPrivate bool
+		wantPublic  bool
+	}{
+		{name: "10/8", ip: "10.1.2.3", wantPrivate: true, wantPublic: false},
+		{name: "172.16/12", ip: "172.16.0.1", wantPrivate: true, wantPublic: false},
+		{name: "192.168/16", ip: "192.168.1.1", wantPrivate: true, wantPublic: false},
+		{name: "ipv4 loopback", ip: "127.0.0.1", wantPrivate: true, wantPublic: false},
+		{name: "ipv6 loopback", ip: "::1", wantPrivate: true, wantPublic: false},
+		{name: "ipv4 link local", ip: "169.254.1.1", wantPrivate: true, wantPublic: false},
+		{name: "ipv6 link local", ip: "fe80::1", wantPrivate: true, wantPublic: false},
+		{name: "ipv6 unique local", ip: "fd00::1", wantPrivate: true, wantPublic: false},
+		{name: "unspecified", ip: "0.0.0.0", wantPrivate: true, wantPublic: false},
+		{name: "public ipv4", ip: "203.0.113.42", wantPrivate: false, wantPublic: true},
+		{name: "public ipv6", ip: "2001:db8::1", wantPrivate: false, wantPublic: true},
+		{name: "unknown", ip: "", wantPrivate: false, wantPublic: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.ip.IsPrivate(); got != tt.wantPrivate {
+				t.Fatalf("%s.IsPrivate() = %v, want %v", tt.ip, got, tt.wantPrivate)
+			}
+			if got := tt.ip.IsPublic(); got != tt.wantPublic {
+				t.Fatalf("%s.IsPublic() = %v, want %v", tt.ip, got, tt.wantPublic)
+			}
+		})
+	}
+}
+
+func TestClientIpFromForwardedFor(t *testing.T) {
+	trustedProxies := []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("::1/128"),
+	}
+
+	tests := []struct {
+		name       string
+		xff        string
+		remoteAddr string
+		want       ClientIp
+		wantErr    error
+	}{
+		{name: "direct client", xff: "", remoteAddr: "203.0.113.42:51000", want: "203.0.113.42", wantErr: nil},
+		{name: "one proxy", xff: "203.0.113.42", remoteAddr: "10.0.0.1:51000", want: "203.0.113.42", wantErr: nil},
+		{name: "proxy chain", xff: "203.0.113.42, 10.0.0.2", remoteAddr: "10.0.0.1:51000", want: "203.0.113.42", wantErr: nil},
+		{name: "spoofed leading entry is ignored", xff: "198.51.100.7, 203.0.113.42", remoteAddr: "10.0.0.1:51000", want: "203.0.113.42", wantErr: nil},
+		{name: "untrusted remote ignores the header", xff: "198.51.100.7", remoteAddr: "203.0.113.42:51000", want: "203.0.113.42", wantErr: nil},
+		{name: "ipv6 proxy", xff: "2001:db8::1", remoteAddr: "[::1]:51000", want: "2001:db8::1", wantErr: nil},
+		{name: "only trusted proxies", xff: "10.0.0.3", remoteAddr: "10.0.0.1:51000", want: "", wantErr: ErrClientIpNotFound},
+		{name: "junk before an untrusted entry", xff: "not-an-ip", remoteAddr: "10.0.0.1:51000", want: "", wantErr: ErrClientIpInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ClientIpFromForwardedFor(tt.xff, trustedProxies, tt.remoteAddr)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ClientIpFromForwardedFor() = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("ClientIpFromForwardedFor() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientIpAnonymize(t *testing.T) {
+	tests := []struct {
+		name string
+		ip   ClientIp
+		want ClientIp
+	}{
+		{name: "ipv4", ip: "203.0.113.42", want: "203.0.113.0"},
+		{name: "ipv4 already anonymized", ip: "203.0.113.0", want: "203.0.113.0"},
+		{name: "ipv6", ip: "2001:db8:85a3:8d3:1319:8a2e:370:7348", want: "2001:db8:85a3::"},
+		{name: "ipv6 short form", ip: "2001:db8::1", want: "2001:db8::"},
+		{name: "ipv6 with zone", ip: "fe80::1%eth0", want: "fe80::"},
+		{name: "unknown", ip: "", want: ""},
+		{name: "invalid", ip: "not-an-ip", want: "not-an-ip"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.ip.Anonymize()
+			if got != tt.want {
+				t.Fatalf("%s.Anonymize() = %q, want %q", tt.ip, got, tt.want)
+			}
+			if _, ok := tt.ip.addr(); ok {
+				if _, err := NewClientIp(string(got)); err != nil {
+					t.Fatalf("NewClientIp(%q) = %v, want a valid address", got, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrNoLots              = errors.New("weighted average cost: total quantity must be positive")
+	ErrLotQuantityNegative = errors.New("weighted average cost: lot quantity must not be negative")
+)
+
+// Lot is one purchase of a holding: Quantity units bought at Amount each.
+type Lot struct {
+	Amount   Amount
+	Quantity int64
+}
+
+// WeightedAverageCost returns sum(Amount * Quantity) / sum(Quantity),
+// rounded half away from zero to the currency's minor unit. All lots must
+// share one currency.
+func WeightedAverageCost(lots []Lot) (Amount, error) {
+	if len(lots) == 0 {
+		return Amount{}, errors.WithStack(ErrNoLots)
+	}
+
+	currency := lots[0].Amount.Currency()
+
+	var cost, quantity int64
+	for _, lot := range lots {
+		if lot.Amount.Currency() != currency {
+			return Amount{}, errors.WithStack(ErrCurrencyMismatch)
+		}
+
+		if lot.Quantity < 0 {
+			return Amount{}, errors.WithStack(ErrLotQuantityNegative)
+		}
+
+		lotCost, ok := mulInt64(lot.Amount.MinorUnits(), lot.Quantity)
+		if !ok || (lotCost > 0 && cost > math.MaxInt64-lotCost) || (lotCost < 0 && cost < math.MinInt64-lotCost) {
+			return Amount{}, errors.WithStack(ErrAmountOverflow)
+		}
+		cost += lotCost
+
+		if quantity > math.MaxInt64-lot.Quantity {
+			return Amount{}, errors.WithStack(ErrAmountOverflow)
+		}
+		quantity += lot.Quantity
+	}
+
+	if quantity == 0 {
+		return Amount{}, errors.WithStack(ErrNoLots)
+	}
+
+	average := cost / quantity
+	if remainder := cost % quantity; remainder > 0 && remainder >= quantity-remainder {
+		average++
+	} else if remainder < 0 && -remainder >= quantity+remainder {
+		average--
+	}
+
+	return NewAmountFromMinorUnits(average, currency), nil
+}
+
+func mulInt64(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+
+	c := a * b
+	if c/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, false
+	}
+
+	return c, true
+}
@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestWeightedAverageCost(t *testing.T) {
+	usd := func(minorUnits int64) Amount { return NewAmountFromMinorUnits(minorUnits, CurrencyUSD) }
+
+	tests := []struct {
+		name    string
+		lots    []Lot
+		want    Amount
+		wantErr error
+	}{
+		{
+			name:    "two lots at different prices",
+			lots:    []Lot{{Amount: usd(10_00), Quantity: 3}, {Amount: usd(20_00), Quantity: 1}},
+			want:    usd(12_50),
+			wantErr: nil,
+		},
+		{
+			name:    "half rounds up",
+			lots:    []Lot{{Amount: usd(10_00), Quantity: 1}, {Amount: usd(10_01), Quantity: 1}},
+			want:    usd(10_01),
+			wantErr: nil,
+		},
+		{
+			name:    "below half rounds down",
+			lots:    []Lot{{Amount: usd(10_00), Quantity: 2}, {Amount: usd(10_01), Quantity: 1}},
+			want:    usd(10_00),
+			wantErr: nil,
+		},
+		{
+			name:    "zero quantity lot is ignored",
+			lots:    []Lot{{Amount: usd(10_00), Quantity: 2}, {Amount: usd(99_00), Quantity: 0}},
+			want:    usd(10_00),
+			wantErr: nil,
+		},
+		{
+			name:    "no lots",
+			lots:    nil,
+			wantErr: ErrNoLots,
+		},
+		{
+			name:    "zero total quantity",
+			lots:    []Lot{{Amount: usd(10_00), Quantity: 0}},
+			wantErr: ErrNoLots,
+		},
+		{
+			name:    "negative quantity",
+			lots:    []Lot{{Amount: usd(10_00), Quantity: -1}},
+			wantErr: ErrLotQuantityNegative,
+		},
+		{
+			name:    "mixed currencies",
+			lots:    []Lot{{Amount: usd(10_00), Quantity: 1}, {Amount: NewAmountFromMinorUnits(10_00, CurrencyHKD), Quantity: 1}},
+			wantErr: ErrCurrencyMismatch,
+		},
+		{
+			name:    "cost overflow",
+			lots:    []Lot{{Amount: usd(math.MaxInt64 / 2), Quantity: 3}},
+			wantErr: ErrAmountOverflow,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := WeightedAverageCost(tt.lots)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("WeightedAverageCost() = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Fatalf("WeightedAverageCost() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
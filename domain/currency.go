@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Currency is an ISO 4217 alphabetic code.
+type Currency string
+
+const (
+	CurrencyUSD Currency = "USD"
+	CurrencyEUR Currency = "EUR"
+	CurrencyGBP Currency = "GBP"
+	CurrencyJPY Currency = "JPY"
+	CurrencyHKD Currency = "HKD"
+	CurrencyCNY Currency = "CNY"
+	CurrencyTWD Currency = "TWD"
+	CurrencyKRW Currency = "KRW"
+	CurrencySGD Currency = "SGD"
+	CurrencyAUD Currency = "AUD"
+	CurrencyCAD Currency = "CAD"
+	CurrencyCHF Currency = "CHF"
+	CurrencyKWD Currency = "KWD"
+)
+
+// currencyDecimalPlaces holds the ISO 4217 minor unit of each currency.
+var currencyDecimalPlaces = map[Currency]int{
+	CurrencyUSD: 2,
+	CurrencyEUR: 2,
+	CurrencyGBP: 2,
+	CurrencyJPY: 0,
+	CurrencyHKD: 2,
+	CurrencyCNY: 2,
+	CurrencyTWD: 2,
+	CurrencyKRW: 0,
+	CurrencySGD: 2,
+	CurrencyAUD: 2,
+	CurrencyCAD: 2,
+	CurrencyCHF: 2,
+	CurrencyKWD: 3,
+}
+
+var ErrCurrencyInvalid = newFieldError("currency", "invalid", "currency: unknown ISO 4217 code")
+
+func NewCurrency(v string) (Currency, error) {
+	currency := Currency(strings.ToUpper(strings.TrimSpace(v)))
+	if _, ok := currencyDecimalPlaces[currency]; !ok {
+		return Currency(""), errors.WithStack(ErrCurrencyInvalid)
+	}
+
+	return currency, nil
+}
+
+func (c Currency) DecimalPlaces() int {
+	return currencyDecimalPlaces[c]
+}
+
+func (c Currency) minorPerMajor() int64 {
+	v := int64(1)
+	for i := 0; i < c.DecimalPlaces(); i++ {
+		v *= 10
+	}
+
+	return v
+}
@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewCurrency(t *testing.T) {
+	tests := []struct {
+		name    string
+		v       string
+		want    Currency
+		wantErr error
+	}{
+		{name: "upper case", v: "USD", want: CurrencyUSD, wantErr: nil},
+		{name: "lower case", v: " jpy ", want: CurrencyJPY, wantErr: nil},
+		{name: "unknown", v: "XYZ", want: "", wantErr: ErrCurrencyInvalid},
+		{name: "empty", v: "", want: "", wantErr: ErrCurrencyInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewCurrency(tt.v)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewCurrency(%q) = %v, want %v", tt.v, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("NewCurrency(%q) = %q, want %q", tt.v, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAmountCurrencyFormatting(t *testing.T) {
+	tests := []struct {
+		name     string
+		v        string
+		currency Currency
+		want     string
+		wantErr  error
+	}{
+		{name: "usd", v: "1250", currency: CurrencyUSD, want: "1250.00", wantErr: nil},
+		{name: "jpy without decimals", v: "1250", currency: CurrencyJPY, want: "1250", wantErr: nil},
+		{name: "jpy padding zeros", v: "1250.00", currency: CurrencyJPY, want: "1250", wantErr: nil},
+		{name: "jpy fraction", v: "1250.5", currency: CurrencyJPY, want: "", wantErr: ErrAmountInvalid},
+		{name: "kwd three decimals", v: "1.5", currency: CurrencyKWD, want: "1.500", wantErr: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount, err := NewAmountFromString(tt.v, tt.currency)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewAmountFromString(%q) = %v, want %v", tt.v, err, tt.wantErr)
+			}
+			if err == nil && amount.String() != tt.want {
+				t.Fatalf("String() = %q, want %q", amount.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestAmountCurrencyMismatch(t *testing.T) {
+	usd := NewAmountFromMinorUnits(100, CurrencyUSD)
+	jpy := NewAmountFromMinorUnits(100, CurrencyJPY)
+
+	if _, err := usd.Add(jpy); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Fatalf("Add() = %v, want %v", err, ErrCurrencyMismatch)
+	}
+	if _, err := usd.Sub(jpy); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Fatalf("Sub() = %v, want %v", err, ErrCurrencyMismatch)
+	}
+}
@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"net/mail"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type Email string
+
+const EmailMaxLength = 254
+
+var (
+	ErrEmailEmpty      = newFieldError("email", "empty", "email: must not be empty")
+	ErrEmailInvalid    = newFieldError("email", "invalid", "email: invalid format")
+	ErrEmailDisposable = newFieldError("email", "disposable", "email: disposable addresses are not allowed")
+)
+
+var DefaultDisposableEmailDomains = NewDisposableEmailDomains([]string{
+	"10minutemail.com",
+	"discard.email",
+	"guerrillamail.com",
+	"mailinator.com",
+	"maildrop.cc",
+	"sharklasers.com",
+	"temp-mail.org",
+	"throwawaymail.com",
+	"trashmail.com",
+	"yopmail.com",
+})
+
+func NewEmail(v string) (Email, error) {
+	return NewEmailWithDisposableDomains(v, DefaultDisposableEmailDomains)
+}
+
+func NewEmailWithDisposableDomains(v string, disposable DisposableEmailDomains) (Email, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return "", errors.WithStack(ErrEmailEmpty)
+	}
+
+	if len(v) > EmailMaxLength {
+		return "", errors.WithStack(ErrEmailInvalid)
+	}
+
+	address, err := mail.ParseAddress(v)
+	if err != nil || address.Name != "" || address.Address != v {
+		return "", errors.WithStack(ErrEmailInvalid)
+	}
+
+	at := strings.LastIndex(v, "@")
+	local, domain := v[:at], strings.ToLower(v[at+1:])
+	if !strings.Contains(domain, ".") {
+		return "", errors.WithStack(ErrEmailInvalid)
+	}
+
+	if disposable.Contains(domain) {
+		return "", errors.WithStack(ErrEmailDisposable)
+	}
+
+	return Email(local + "@" + domain), nil
+}
+
+func (e Email) Domain() string {
+	return string(e)[strings.LastIndex(string(e), "@")+1:]
+}
+
+type DisposableEmailDomains map[string]struct{}
+
+func NewDisposableEmailDomains(domains []string) DisposableEmailDomains {
+	disposable := make(DisposableEmailDomains, len(domains))
+	for _, domain := range domains {
+		disposable[strings.ToLower(domain)] = struct{}{}
+	}
+
+	return disposable
+}
+
+func (d DisposableEmailDomains) Contains(domain string) bool {
+	_, ok := d[strings.ToLower(domain)]
+
+	return ok
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewEmail(t *testing.T) {
+	tests := []struct {
+		name       string
+		email      string
+		want       Email
+		wantDomain string
+		wantErr    error
+	}{
+		{name: "lowercases domain only", email: "User@Example.COM", want: "User@example.com", wantDomain: "example.com", wantErr: nil},
+		{name: "trims", email: "  bob@example.org ", want: "bob@example.org", wantDomain: "example.org", wantErr: nil},
+		{name: "empty", email: " ", wantErr: ErrEmailEmpty},
+		{name: "missing domain", email: "foo@", wantErr: ErrEmailInvalid},
+		{name: "missing at", email: "foo.example.com", wantErr: ErrEmailInvalid},
+		{name: "domain without dot", email: "foo@localhost", wantErr: ErrEmailInvalid},
+		{name: "display name", email: "Foo <foo@example.com>", wantErr: ErrEmailInvalid},
+		{name: "disposable", email: "foo@Mailinator.com", wantErr: ErrEmailDisposable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewEmail(tt.email)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewEmail(%q) = %v, want %v", tt.email, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("NewEmail(%q) = %q, want %q", tt.email, got, tt.want)
+			}
+			if tt.wantErr == nil && got.Domain() != tt.wantDomain {
+				t.Fatalf("Domain() = %q, want %q", got.Domain(), tt.wantDomain)
+			}
+		})
+	}
+}
+
+func TestNewEmailWithDisposableDomains(t *testing.T) {
+	disposable := NewDisposableEmailDomains([]string{"Example.net"})
+
+	if _, err := NewEmailWithDisposableDomains("foo@example.net", disposable); !errors.Is(err, ErrEmailDisposable) {
+		t.Fatalf("NewEmailWithDisposableDomains() = %v, want %v", err, ErrEmailDisposable)
+	}
+	if _, err := NewEmailWithDisposableDomains("foo@mailinator.com", disposable); err != nil {
+		t.Fatalf("NewEmailWithDisposableDomains() = %v, want nil", err)
+	}
+}
@@ -0,0 +1,140 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const emailVerificationTokenBytes = 32
+
+var (
+	ErrEmailVerificationTokenExpired    = errors.New("email verification token: expired")
+	ErrEmailVerificationTokenInvalid    = errors.New("email verification token: invalid")
+	ErrEmailVerificationTokenTTLInvalid = errors.New("email verification token: ttl must be positive")
+	ErrVerificationResendTooSoon        = errors.New("email verification token: resend requested too soon")
+)
+
+// EmailVerificationToken is the stored side of an email confirmation. The
+// hash covers the address as well as the raw token, so a token sent to one
+// address cannot verify another.
+type EmailVerificationToken struct {
+	email     Email
+	hashed    string
+	expiresAt time.Time
+	sentAt    time.Time
+	used      bool
+}
+
+func (t *EmailVerificationToken) Email() Email         { return t.email }
+func (t *EmailVerificationToken) Hashed() string       { return t.hashed }
+func (t *EmailVerificationToken) ExpiresAt() time.Time { return t.expiresAt }
+func (t *EmailVerificationToken) SentAt() time.Time    { return t.sentAt }
+func (t *EmailVerificationToken) IsUsed() bool         { return t.used }
+
+// GenerateEmailVerificationToken returns the raw token to send and the token
+// to store.
+func GenerateEmailVerificationToken(
+	email Email,
+	ttl time.Duration,
+	now time.Time,
+) (string, *EmailVerificationToken, error) {
+	if ttl <= 0 {
+		return "", nil, errors.WithStack(ErrEmailVerificationTokenTTLInvalid)
+	}
+
+	b := make([]byte, emailVerificationTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, errors.WithStack(err)
+	}
+	raw := base64.RawURLEncoding.EncodeToString(b)
+
+	return raw, &EmailVerificationToken{
+		email:     email,
+		hashed:    hashEmailVerificationToken(email, raw),
+		expiresAt: now.Add(ttl),
+		sentAt:    now,
+	}, nil
+}
+
+func NewEmailVerificationTokenFromSource(
+	email string,
+	hashed string,
+	expiresAt time.Time,
+	sentAt time.Time,
+	used bool,
+) (*EmailVerificationToken, error) {
+	newEmail, err := NewEmailWithDisposableDomains(email, nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if hashed == "" {
+		return nil, errors.WithStack(ErrEmailVerificationTokenInvalid)
+	}
+
+	return &EmailVerificationToken{
+		email:     newEmail,
+		hashed:    hashed,
+		expiresAt: expiresAt,
+		sentAt:    sentAt,
+		used:      used,
+	}, nil
+}
+
+// Verify consumes the token when raw was issued for email and has not
+// expired.
+func (t *EmailVerificationToken) Verify(email Email, raw string, now time.Time) error {
+	if t.used {
+		return errors.WithStack(ErrEmailVerificationTokenInvalid)
+	}
+
+	expected := hashEmailVerificationToken(email, raw)
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(t.hashed)) != 1 {
+		return errors.WithStack(ErrEmailVerificationTokenInvalid)
+	}
+
+	if !now.Before(t.expiresAt) {
+		return errors.WithStack(ErrEmailVerificationTokenExpired)
+	}
+
+	t.used = true
+
+	return nil
+}
+
+func hashEmailVerificationToken(email Email, raw string) string {
+	sum := sha256.Sum256([]byte(string(email) + "\x00" + raw))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// EmailVerificationThrottle limits how often a user can ask for the
+// verification email to be sent again.
+type EmailVerificationThrottle struct {
+	interval time.Duration
+}
+
+func (t *EmailVerificationThrottle) Interval() time.Duration { return t.interval }
+
+func NewEmailVerificationThrottle(interval time.Duration) *EmailVerificationThrottle {
+	return &EmailVerificationThrottle{interval: interval}
+}
+
+// AllowResend checks against the last token sent; last is nil when none was.
+func (t *EmailVerificationThrottle) AllowResend(last *EmailVerificationToken, now time.Time) error {
+	if last == nil {
+		return nil
+	}
+
+	if now.Before(last.sentAt.Add(t.interval)) {
+		return errors.WithStack(ErrVerificationResendTooSoon)
+	}
+
+	return nil
+}
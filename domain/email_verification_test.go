@@ -0,0 +1,123 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestEmail(t *testing.T, v string) Email {
+	t.Helper()
+
+	email, err := NewEmail(v)
+	if err != nil {
+		t.Fatalf("NewEmail(%q) = %v", v, err)
+	}
+
+	return email
+}
+
+func TestEmailVerificationTokenVerify(t *testing.T) {
+	now := newFakeClock().Now()
+	alice := newTestEmail(t, "alice@example.com")
+	bob := newTestEmail(t, "bob@example.com")
+
+	tests := []struct {
+		name    string
+		email   Email
+		raw     func(raw string) string
+		now     time.Time
+		wantErr error
+	}{
+		{name: "valid", email: alice, raw: func(raw string) string { return raw }, now: now.Add(time.Minute), wantErr: nil},
+		{name: "expired", email: alice, raw: func(raw string) string { return raw }, now: now.Add(time.Hour), wantErr: ErrEmailVerificationTokenExpired},
+		{name: "another address", email: bob, raw: func(raw string) string { return raw }, now: now.Add(time.Minute), wantErr: ErrEmailVerificationTokenInvalid},
+		{name: "wrong token", email: alice, raw: func(raw string) string { return raw + "x" }, now: now.Add(time.Minute), wantErr: ErrEmailVerificationTokenInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, token, err := GenerateEmailVerificationToken(alice, time.Hour, now)
+			if err != nil {
+				t.Fatalf("GenerateEmailVerificationToken() = %v", err)
+			}
+
+			err = token.Verify(tt.email, tt.raw(raw), tt.now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() = %v, want %v", err, tt.wantErr)
+			}
+			if token.IsUsed() != (tt.wantErr == nil) {
+				t.Fatalf("IsUsed() = %v, want %v", token.IsUsed(), tt.wantErr == nil)
+			}
+		})
+	}
+}
+
+func TestEmailVerificationTokenSingleUse(t *testing.T) {
+	now := newFakeClock().Now()
+	email := newTestEmail(t, "alice@example.com")
+
+	raw, token, err := GenerateEmailVerificationToken(email, time.Hour, now)
+	if err != nil {
+		t.Fatalf("GenerateEmailVerificationToken() = %v", err)
+	}
+
+	if err := token.Verify(email, raw, now); err != nil {
+		t.Fatalf("Verify() = %v", err)
+	}
+	if err := token.Verify(email, raw, now); !errors.Is(err, ErrEmailVerificationTokenInvalid) {
+		t.Fatalf("second Verify() = %v, want %v", err, ErrEmailVerificationTokenInvalid)
+	}
+}
+
+func TestGenerateEmailVerificationTokenTTL(t *testing.T) {
+	email := newTestEmail(t, "alice@example.com")
+
+	tests := []struct {
+		name    string
+		ttl     time.Duration
+		wantErr error
+	}{
+		{name: "positive", ttl: time.Minute, wantErr: nil},
+		{name: "zero", ttl: 0, wantErr: ErrEmailVerificationTokenTTLInvalid},
+		{name: "negative", ttl: -time.Minute, wantErr: ErrEmailVerificationTokenTTLInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := GenerateEmailVerificationToken(email, tt.ttl, time.Now()); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GenerateEmailVerificationToken() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEmailVerificationThrottleAllowResend(t *testing.T) {
+	clock := newFakeClock()
+	throttle := NewEmailVerificationThrottle(time.Minute)
+
+	_, last, err := GenerateEmailVerificationToken(newTestEmail(t, "alice@example.com"), time.Hour, clock.Now())
+	if err != nil {
+		t.Fatalf("GenerateEmailVerificationToken() = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		last    *EmailVerificationToken
+		now     time.Time
+		wantErr error
+	}{
+		{name: "never sent", last: nil, now: clock.Now(), wantErr: nil},
+		{name: "right after sending", last: last, now: clock.Now(), wantErr: ErrVerificationResendTooSoon},
+		{name: "just before the interval", last: last, now: clock.Now().Add(time.Minute - time.Second), wantErr: ErrVerificationResendTooSoon},
+		{name: "at the interval", last: last, now: clock.Now().Add(time.Minute), wantErr: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := throttle.AllowResend(tt.last, tt.now); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("AllowResend() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
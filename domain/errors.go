@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrValidation is matched by every error a value object constructor returns
+// for bad input, so callers can map them all to a client error with
+// errors.Is(err, ErrValidation). The specific sentinel still matches too.
+var ErrValidation = errors.New("validation")
+
+// FieldError is the type behind those sentinels. Extract it with errors.As
+// to tell a client which field failed and why.
+type FieldError struct {
+	Field   string
+	Code    string
+	Message string
+
+	// parent is a broader sentinel this error also matches, e.g. ErrPassword.
+	parent error
+}
+
+func (e *FieldError) Error() string { return e.Message }
+
+func (e *FieldError) Is(target error) bool {
+	if target == ErrValidation {
+		return true
+	}
+
+	return e.parent != nil && errors.Is(e.parent, target)
+}
+
+func newFieldError(field, code, message string) error {
+	return &FieldError{Field: field, Code: code, Message: message}
+}
+
+func newPasswordError(code, detail string) error {
+	return &FieldError{
+		Field:   "password",
+		Code:    code,
+		Message: ErrPassword.Error() + ": " + detail,
+		parent:  ErrPassword,
+	}
+}
+
+// ValidationErrors collects the errors of several fields so a client can fix
+// them in one round trip.
+type ValidationErrors []error
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+func (e ValidationErrors) Is(target error) bool { return target == ErrValidation }
+
+// FieldErrors returns the FieldError behind each collected error; errors of
+// other types are skipped.
+func (e ValidationErrors) FieldErrors() []*FieldError {
+	fieldErrors := make([]*FieldError, 0, len(e))
+	for _, err := range e {
+		var fieldErr *FieldError
+		if errors.As(err, &fieldErr) {
+			fieldErrors = append(fieldErrors, fieldErr)
+		}
+	}
+
+	return fieldErrors
+}
@@ -0,0 +1,147 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestErrValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		call    func() error
+		wantErr error
+	}{
+		{name: "user name", call: func() error { _, err := NewUserName(""); return err }, wantErr: ErrUserNameEmpty},
+		{name: "password", call: func() error { _, err := NewPassword("Xq7!"); return err }, wantErr: ErrPasswordTooShort},
+		{name: "user role", call: func() error { _, err := NewUserRole("root"); return err }, wantErr: ErrUserRoleInvalid},
+		{name: "user id", call: func() error { _, err := NewUserID(0); return err }, wantErr: ErrUserIDZero},
+		{name: "invest type", call: func() error { _, err := NewInvestType("gold"); return err }, wantErr: ErrInvestTypeInvalid},
+		{name: "invested at", call: func() error { _, err := NewInvestedAt(time.Now().Add(time.Hour)); return err }, wantErr: ErrInvestedAtFuture},
+		{name: "amount", call: func() error { _, err := NewAmountFromString("abc", CurrencyUSD); return err }, wantErr: ErrAmountInvalid},
+		{name: "currency", call: func() error { _, err := NewCurrency("usd1"); return err }, wantErr: ErrCurrencyInvalid},
+		{name: "email", call: func() error { _, err := NewEmail("alice"); return err }, wantErr: ErrEmailInvalid},
+		{name: "client ip", call: func() error { _, err := NewClientIp("not-an-ip"); return err }, wantErr: ErrClientIpInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if !errors.Is(err, ErrValidation) {
+				t.Fatalf("errors.Is(%v, ErrValidation) = false, want true", err)
+			}
+		})
+	}
+}
+
+func TestErrValidationExcludesOtherErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{name: "password mismatch", err: ErrHashedPasswordNotMatch},
+		{name: "account locked", err: ErrAccountLocked},
+		{name: "hash cost", err: ErrPasswordHashCostInvalid},
+		{name: "plain error", err: errors.New("boom")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if errors.Is(tt.err, ErrValidation) {
+				t.Fatalf("errors.Is(%v, ErrValidation) = true, want false", tt.err)
+			}
+		})
+	}
+}
+
+func TestFieldError(t *testing.T) {
+	tests := []struct {
+		name      string
+		call      func() error
+		wantField string
+		wantCode  string
+	}{
+		{name: "empty name", call: func() error { _, err := NewUserName(""); return err }, wantField: "name", wantCode: "empty"},
+		{name: "reserved name", call: func() error { _, err := NewUserName("admin"); return err }, wantField: "name", wantCode: "reserved"},
+		{name: "short password", call: func() error { _, err := NewPassword("Xq7!"); return err }, wantField: "password", wantCode: "too_short"},
+		{name: "password without rules", call: func() error { _, err := NewPassword("xqkwlmqz"); return err }, wantField: "password", wantCode: "rules"},
+		{name: "invalid role", call: func() error { _, err := NewUserRole("root"); return err }, wantField: "role", wantCode: "invalid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+
+			var fieldErr *FieldError
+			if !errors.As(err, &fieldErr) {
+				t.Fatalf("errors.As(%v, *FieldError) = false, want true", err)
+			}
+			if fieldErr.Field != tt.wantField || fieldErr.Code != tt.wantCode {
+				t.Fatalf("FieldError = %s/%s, want %s/%s", fieldErr.Field, fieldErr.Code, tt.wantField, tt.wantCode)
+			}
+			if fieldErr.Message == "" || fieldErr.Error() != fieldErr.Message {
+				t.Fatalf("FieldError.Error() = %q, want the message %q", fieldErr.Error(), fieldErr.Message)
+			}
+		})
+	}
+}
+
+func TestValidateUserInput(t *testing.T) {
+	tests := []struct {
+		name       string
+		userName   string
+		password   string
+		role       string
+		wantFields []string
+	}{
+		{name: "valid", userName: "alice", password: "Tx7!qLmZ", role: string(RoleUser), wantFields: nil},
+		{name: "empty name and short password", userName: "", password: "Tx7!", role: string(RoleUser), wantFields: []string{"name", "password"}},
+		{name: "every field", userName: "", password: "Tx7!", role: "root", wantFields: []string{"name", "password", "role"}},
+		{name: "password with user name", userName: "alice", password: "Alice7!q", role: string(RoleUser), wantFields: []string{"password"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateUserInput(tt.userName, tt.password, tt.role, DefaultPasswordPolicy)
+			if tt.wantFields == nil {
+				if err != nil {
+					t.Fatalf("ValidateUserInput() = %v, want nil", err)
+				}
+				return
+			}
+
+			var errs ValidationErrors
+			if !errors.As(err, &errs) {
+				t.Fatalf("ValidateUserInput() = %v, want ValidationErrors", err)
+			}
+			if !errors.Is(err, ErrValidation) {
+				t.Fatalf("errors.Is(%v, ErrValidation) = false, want true", err)
+			}
+
+			fieldErrs := errs.FieldErrors()
+			if len(fieldErrs) != len(tt.wantFields) {
+				t.Fatalf("ValidateUserInput() = %d errors (%v), want %d", len(fieldErrs), err, len(tt.wantFields))
+			}
+			for i, fieldErr := range fieldErrs {
+				if fieldErr.Field != tt.wantFields[i] {
+					t.Fatalf("errors[%d].Field = %q, want %q", i, fieldErr.Field, tt.wantFields[i])
+				}
+			}
+		})
+	}
+
+	t.Run("specific errors match", func(t *testing.T) {
+		err := ValidateUserInput("", "Tx7!", string(RoleUser), DefaultPasswordPolicy)
+
+		var errs ValidationErrors
+		if !errors.As(err, &errs) {
+			t.Fatalf("ValidateUserInput() = %v, want ValidationErrors", err)
+		}
+		if !errors.Is(errs[0], ErrUserNameEmpty) || !errors.Is(errs[1], ErrPasswordTooShort) {
+			t.Fatalf("ValidateUserInput() = %v, want %v and %v", err, ErrUserNameEmpty, ErrPasswordTooShort)
+		}
+	})
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"net"
+
+	"github.com/pkg/errors"
+)
+
+// GeoInfo is the approximate location of a client ip. The zero value means
+// unknown.
+type GeoInfo struct {
+	CountryCode string
+	City        string
+	Latitude    float64
+	Longitude   float64
+}
+
+func (g GeoInfo) IsUnknown() bool {
+	return g == GeoInfo{}
+}
+
+// GeoResolver looks up where a client ip is.
+type GeoResolver interface {
+	Resolve(ip ClientIp) (GeoInfo, error)
+}
+
+// NopGeoResolver resolves every address to an unknown location.
+type NopGeoResolver struct{}
+
+func (NopGeoResolver) Resolve(ClientIp) (GeoInfo, error) {
+	return GeoInfo{}, nil
+}
+
+// ErrMaxMindUnavailable is returned by NewMaxMindGeoResolver in builds
+// without the maxmind tag; use NopGeoResolver instead.
+var ErrMaxMindUnavailable = errors.New("geo: built without maxmind support")
+
+// MaxMindGeoResolver reads a GeoLite2 or GeoIP2 City database opened with
+// NewMaxMindGeoResolver. Only the opening needs the maxmind build tag, which
+// keeps the MaxMind module out of default builds.
+type MaxMindGeoResolver struct {
+	lookupCity func(ip net.IP) (GeoInfo, error)
+	close      func() error
+}
+
+// Resolve returns an unknown location without a lookup for addresses a geo
+// database has no entry for: malformed, private, loopback and the like.
+func (r *MaxMindGeoResolver) Resolve(ip ClientIp) (GeoInfo, error) {
+	if !ip.IsPublic() {
+		return GeoInfo{}, nil
+	}
+
+	info, err := r.lookupCity(net.ParseIP(string(ip)))
+	if err != nil {
+		return GeoInfo{}, errors.WithStack(err)
+	}
+
+	return info, nil
+}
+
+func (r *MaxMindGeoResolver) Close() error {
+	return errors.WithStack(r.close())
+}
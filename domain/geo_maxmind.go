@@ -0,0 +1,35 @@
+//go:build maxmind
+
+package domain
+
+import (
+	"net"
+
+	"github.com/oschwald/geoip2-golang"
+	"github.com/pkg/errors"
+)
+
+// NewMaxMindGeoResolver opens the City database at path.
+func NewMaxMindGeoResolver(path string) (*MaxMindGeoResolver, error) {
+	reader, err := geoip2.Open(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &MaxMindGeoResolver{
+		lookupCity: func(ip net.IP) (GeoInfo, error) {
+			record, err := reader.City(ip)
+			if err != nil {
+				return GeoInfo{}, err
+			}
+
+			return GeoInfo{
+				CountryCode: record.Country.IsoCode,
+				City:        record.City.Names["en"],
+				Latitude:    record.Location.Latitude,
+				Longitude:   record.Location.Longitude,
+			}, nil
+		},
+		close: reader.Close,
+	}, nil
+}
@@ -0,0 +1,10 @@
+//go:build !maxmind
+
+package domain
+
+import "github.com/pkg/errors"
+
+// NewMaxMindGeoResolver always fails in builds without the maxmind tag.
+func NewMaxMindGeoResolver(string) (*MaxMindGeoResolver, error) {
+	return nil, errors.WithStack(ErrMaxMindUnavailable)
+}
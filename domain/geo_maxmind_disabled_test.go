@@ -0,0 +1,18 @@
+//go:build !maxmind
+
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewMaxMindGeoResolverDisabled(t *testing.T) {
+	resolver, err := NewMaxMindGeoResolver("GeoLite2-City.mmdb")
+	if !errors.Is(err, ErrMaxMindUnavailable) {
+		t.Fatalf("NewMaxMindGeoResolver() = %v, want %v", err, ErrMaxMindUnavailable)
+	}
+	if resolver != nil {
+		t.Fatalf("NewMaxMindGeoResolver() = %v, want nil", resolver)
+	}
+}
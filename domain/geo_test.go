@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"errors"
+	"net"
+	"testing"
+)
+
+func TestNopGeoResolver(t *testing.T) {
+	var resolver GeoResolver = NopGeoResolver{}
+
+	for _, ip := range []ClientIp{"203.0.113.42", "2001:db8::1", ""} {
+		info, err := resolver.Resolve(ip)
+		if err != nil {
+			t.Fatalf("Resolve(%q) = %v", ip, err)
+		}
+		if !info.IsUnknown() {
+			t.Fatalf("Resolve(%q) = %+v, want unknown", ip, info)
+		}
+	}
+}
+
+func TestMaxMindGeoResolverResolve(t *testing.T) {
+	tokyo := GeoInfo{CountryCode: "JP", City: "Tokyo", Latitude: 35.68, Longitude: 139.69}
+	errLookup := errors.New("lookup failed")
+
+	// The database is stood in for by what the maxmind build maps a City
+	// record to, so Resolve itself runs unchanged.
+	var looked []string
+	resolver := &MaxMindGeoResolver{
+		lookupCity: func(ip net.IP) (GeoInfo, error) {
+			looked = append(looked, ip.String())
+			if ip.String() == "198.51.100.7" {
+				return GeoInfo{}, errLookup
+			}
+
+			return tokyo, nil
+		},
+		close: func() error { return nil },
+	}
+
+	tests := []struct {
+		name       string
+		ip         ClientIp
+		want       GeoInfo
+		wantErr    error
+		wantLookup bool
+	}{
+		{name: "public ipv4", ip: "203.0.113.42", want: tokyo, wantLookup: true},
+		{name: "public ipv6", ip: "2001:db8::1", want: tokyo, wantLookup: true},
+		{name: "lookup error", ip: "198.51.100.7", wantErr: errLookup, wantLookup: true},
+		{name: "private", ip: "10.0.0.1", wantLookup: false},
+		{name: "loopback", ip: "::1", wantLookup: false},
+		{name: "unspecified", ip: "0.0.0.0", wantLookup: false},
+		{name: "malformed", ip: "not-an-ip", wantLookup: false},
+		{name: "empty", ip: "", wantLookup: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			looked = nil
+
+			got, err := resolver.Resolve(tt.ip)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Resolve(%q) = %v, want %v", tt.ip, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("Resolve(%q) = %+v, want %+v", tt.ip, got, tt.want)
+			}
+			if (len(looked) > 0) != tt.wantLookup {
+				t.Fatalf("Resolve(%q) looked up %v, want a lookup %v", tt.ip, looked, tt.wantLookup)
+			}
+		})
+	}
+}
+
+func TestMaxMindGeoResolverClose(t *testing.T) {
+	errClose := errors.New("close failed")
+	resolver := &MaxMindGeoResolver{close: func() error { return errClose }}
+
+	if err := resolver.Close(); !errors.Is(err, errClose) {
+		t.Fatalf("Close() = %v, want %v", err, errClose)
+	}
+}
@@ -0,0 +1,195 @@
+package domain
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type Invest struct {
+	id         InvestID
+	userID     UserID
+	amount     Amount
+	investType InvestType
+	investedAt InvestedAt
+	version    Version
+}
+
+func (i *Invest) ID() InvestID           { return i.id }
+func (i *Invest) UserID() UserID         { return i.userID }
+func (i *Invest) Amount() Amount         { return i.amount }
+func (i *Invest) Currency() Currency     { return i.amount.Currency() }
+func (i *Invest) Type() InvestType       { return i.investType }
+func (i *Invest) InvestedAt() InvestedAt { return i.investedAt }
+func (i *Invest) Version() Version       { return i.version }
+
+func NewInvest(
+	userID UserID,
+	amount Amount,
+	investType InvestType,
+	investedAt InvestedAt,
+) (*Invest, error) {
+	return NewInvestWithLimits(userID, amount, investType, investedAt, DefaultInvestAmountLimits)
+}
+
+func NewInvestWithLimits(
+	userID UserID,
+	amount Amount,
+	investType InvestType,
+	investedAt InvestedAt,
+	limits InvestAmountLimits,
+) (*Invest, error) {
+	if userID == 0 {
+		return nil, errors.WithStack(ErrUserIDZero)
+	}
+
+	if !amount.IsPositive() {
+		return nil, errors.WithStack(ErrInvestAmountNotPositive)
+	}
+
+	if err := limits.Validate(amount); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &Invest{
+		userID:     userID,
+		amount:     amount,
+		investType: investType,
+		investedAt: investedAt,
+	}, nil
+}
+
+func NewInvestFromSource(
+	id uint64,
+	userID uint64,
+	amount string,
+	currency string,
+	investType string,
+	investedAt time.Time,
+	version uint64,
+) (*Invest, error) {
+	newID, err := NewInvestID(id)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	newUserID, err := NewUserID(userID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	newCurrency, err := NewCurrency(currency)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	newAmount, err := NewAmountFromString(amount, newCurrency)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if !newAmount.IsPositive() {
+		return nil, errors.WithStack(ErrInvestAmountNotPositive)
+	}
+
+	newInvestType, err := NewInvestType(investType)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	newInvestedAt, err := NewInvestedAt(investedAt)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &Invest{
+		id:         newID,
+		userID:     newUserID,
+		amount:     newAmount,
+		investType: newInvestType,
+		investedAt: newInvestedAt,
+		version:    Version(version),
+	}, nil
+}
+
+var (
+	ErrInvalidWithdrawal        = errors.New("withdrawal: amount must be positive")
+	ErrWithdrawalExceedsBalance = errors.New("withdrawal: amount exceeds the invested amount")
+	ErrInvestFullyWithdrawn     = errors.New("withdrawal: nothing left, delete the invest")
+)
+
+// Withdraw returns a copy of the invest with amount taken out; the receiver
+// is left unchanged. An invest needs a positive amount, so withdrawing
+// everything returns ErrInvestFullyWithdrawn and the caller deletes the
+// invest instead.
+func (i *Invest) Withdraw(amount Amount) (*Invest, error) {
+	if !amount.IsPositive() {
+		return nil, errors.WithStack(ErrInvalidWithdrawal)
+	}
+
+	remaining, err := i.amount.Sub(amount)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if remaining.MinorUnits() < 0 {
+		return nil, errors.WithStack(ErrWithdrawalExceedsBalance)
+	}
+
+	if remaining.MinorUnits() == 0 {
+		return nil, errors.WithStack(ErrInvestFullyWithdrawn)
+	}
+
+	withdrawn := *i
+	withdrawn.amount = remaining
+
+	return &withdrawn, nil
+}
+
+// Version is the optimistic lock of a persisted record. Updates only apply
+// when the stored version still matches.
+type Version uint64
+
+type InvestID uint64
+
+var ErrInvestIDZero = newFieldError("id", "zero", "invest id: must not be zero")
+
+func NewInvestID(v uint64) (InvestID, error) {
+	if v == 0 {
+		return 0, errors.WithStack(ErrInvestIDZero)
+	}
+
+	return InvestID(v), nil
+}
+
+func (id InvestID) String() string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+var ErrInvestAmountNotPositive = newFieldError("amount", "not_positive", "invest amount: must be positive")
+
+type InvestedAt time.Time
+
+// InvestedAtClockSkew tolerates clients whose clocks run slightly ahead.
+const InvestedAtClockSkew = time.Minute
+
+var (
+	ErrInvestedAtMissing = newFieldError("invested_at", "empty", "invested at: must not be empty")
+	ErrInvestedAtFuture  = newFieldError("invested_at", "future", "invested at: must not be in the future")
+)
+
+func NewInvestedAt(v time.Time) (InvestedAt, error) {
+	return NewInvestedAtWithClock(v, SystemClock{})
+}
+
+func NewInvestedAtWithClock(v time.Time, clock Clock) (InvestedAt, error) {
+	if v.IsZero() {
+		return InvestedAt{}, errors.WithStack(ErrInvestedAtMissing)
+	}
+
+	if v.After(clock.Now().Add(InvestedAtClockSkew)) {
+		return InvestedAt{}, errors.WithStack(ErrInvestedAtFuture)
+	}
+
+	return InvestedAt(v), nil
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrInvestAmountTooSmall = newFieldError("amount", "too_small", "invest amount: below the minimum")
+	ErrInvestAmountTooLarge = newFieldError("amount", "too_large", "invest amount: above the maximum")
+)
+
+// InvestAmountLimit bounds a single investment, both ends inclusive.
+type InvestAmountLimit struct {
+	Min Amount
+	Max Amount
+}
+
+// InvestAmountLimits is keyed by currency because thresholds differ per
+// currency. Currencies without an entry are not bounded.
+type InvestAmountLimits map[Currency]InvestAmountLimit
+
+var DefaultInvestAmountLimits = InvestAmountLimits{
+	CurrencyUSD: {
+		Min: NewAmountFromMinorUnits(1_00, CurrencyUSD),
+		Max: NewAmountFromMinorUnits(1_000_000_00, CurrencyUSD),
+	},
+	CurrencyHKD: {
+		Min: NewAmountFromMinorUnits(10_00, CurrencyHKD),
+		Max: NewAmountFromMinorUnits(10_000_000_00, CurrencyHKD),
+	},
+	CurrencyJPY: {
+		Min: NewAmountFromMinorUnits(100, CurrencyJPY),
+		Max: NewAmountFromMinorUnits(150_000_000, CurrencyJPY),
+	},
+}
+
+func (l InvestAmountLimits) Validate(amount Amount) error {
+	limit, ok := l[amount.Currency()]
+	if !ok {
+		return nil
+	}
+
+	if amount.MinorUnits() < limit.Min.MinorUnits() {
+		return errors.WithStack(ErrInvestAmountTooSmall)
+	}
+
+	if limit.Max.MinorUnits() < amount.MinorUnits() {
+		return errors.WithStack(ErrInvestAmountTooLarge)
+	}
+
+	return nil
+}
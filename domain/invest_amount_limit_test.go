@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewInvestAmountLimits(t *testing.T) {
+	investedAt, err := NewInvestedAt(time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("NewInvestedAt() = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		amount  Amount
+		wantErr error
+	}{
+		{name: "usd below min", amount: NewAmountFromMinorUnits(99, CurrencyUSD), wantErr: ErrInvestAmountTooSmall},
+		{name: "usd at min", amount: NewAmountFromMinorUnits(1_00, CurrencyUSD), wantErr: nil},
+		{name: "usd at max", amount: NewAmountFromMinorUnits(1_000_000_00, CurrencyUSD), wantErr: nil},
+		{name: "usd above max", amount: NewAmountFromMinorUnits(1_000_000_01, CurrencyUSD), wantErr: ErrInvestAmountTooLarge},
+		{name: "jpy below min", amount: NewAmountFromMinorUnits(99, CurrencyJPY), wantErr: ErrInvestAmountTooSmall},
+		{name: "jpy at min", amount: NewAmountFromMinorUnits(100, CurrencyJPY), wantErr: nil},
+		{name: "jpy at max", amount: NewAmountFromMinorUnits(150_000_000, CurrencyJPY), wantErr: nil},
+		{name: "jpy above max", amount: NewAmountFromMinorUnits(150_000_001, CurrencyJPY), wantErr: ErrInvestAmountTooLarge},
+		{name: "unbounded currency", amount: NewAmountFromMinorUnits(1, CurrencyEUR), wantErr: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewInvest(1, tt.amount, InvestTypeStock, investedAt); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewInvest(%s %s) = %v, want %v", tt.amount, tt.amount.Currency(), err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewInvestWithCustomLimits(t *testing.T) {
+	investedAt, err := NewInvestedAt(time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("NewInvestedAt() = %v", err)
+	}
+	limits := InvestAmountLimits{
+		CurrencyEUR: {
+			Min: NewAmountFromMinorUnits(10_00, CurrencyEUR),
+			Max: NewAmountFromMinorUnits(20_00, CurrencyEUR),
+		},
+	}
+
+	if _, err := NewInvestWithLimits(1, NewAmountFromMinorUnits(9_99, CurrencyEUR), InvestTypeStock, investedAt, limits); !errors.Is(err, ErrInvestAmountTooSmall) {
+		t.Fatalf("NewInvestWithLimits() = %v, want %v", err, ErrInvestAmountTooSmall)
+	}
+	if _, err := NewInvestWithLimits(1, NewAmountFromMinorUnits(1, CurrencyUSD), InvestTypeStock, investedAt, limits); err != nil {
+		t.Fatalf("NewInvestWithLimits() = %v, want USD to be unbounded", err)
+	}
+}
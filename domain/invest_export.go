@@ -0,0 +1,132 @@
+package domain
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var investCSVHeader = []string{"id", "invested_at", "type", "amount", "currency"}
+
+// ExportInvestsCSV writes a header row and one row per invest. Amounts use
+// the currency's decimal places and dates are RFC 3339 in loc, UTC when loc
+// is nil. encoding/csv quotes any field containing a comma or quote.
+func ExportInvestsCSV(w io.Writer, invests []*Invest, loc *time.Location) error {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(investCSVHeader); err != nil {
+		return errors.WithStack(err)
+	}
+
+	for _, invest := range invests {
+		record := []string{
+			invest.ID().String(),
+			time.Time(invest.InvestedAt()).In(loc).Format(time.RFC3339),
+			string(invest.Type()),
+			invest.Amount().String(),
+			string(invest.Currency()),
+		}
+		if err := cw.Write(record); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	cw.Flush()
+
+	return errors.WithStack(cw.Error())
+}
+
+// InvestExportVersion is written into every JSON export. Import accepts
+// versions up to it; bump it when the record shape changes incompatibly.
+const InvestExportVersion = 1
+
+var (
+	ErrInvestExportVersionUnsupported = errors.New("invest export: unsupported version")
+	ErrInvestExportMalformed          = errors.New("invest export: malformed document")
+)
+
+type investExportJSON struct {
+	Version int                      `json:"version"`
+	Invests []investExportRecordJSON `json:"invests"`
+}
+
+// investExportRecordJSON leaves out ids and the user, which belong to the
+// account the file is imported into.
+type investExportRecordJSON struct {
+	Amount     string    `json:"amount"`
+	Currency   string    `json:"currency"`
+	Type       string    `json:"type"`
+	InvestedAt time.Time `json:"invested_at"`
+}
+
+func ExportInvestsJSON(w io.Writer, invests []*Invest) error {
+	doc := investExportJSON{
+		Version: InvestExportVersion,
+		Invests: make([]investExportRecordJSON, 0, len(invests)),
+	}
+
+	for _, invest := range invests {
+		doc.Invests = append(doc.Invests, investExportRecordJSON{
+			Amount:     invest.Amount().String(),
+			Currency:   string(invest.Currency()),
+			Type:       string(invest.Type()),
+			InvestedAt: time.Time(invest.InvestedAt()).UTC(),
+		})
+	}
+
+	return errors.WithStack(json.NewEncoder(w).Encode(doc))
+}
+
+// ImportInvestsJSON builds unsaved invests for userID. Every record goes
+// through NewInvest, and a single invalid record fails the whole import.
+func ImportInvestsJSON(r io.Reader, userID UserID) ([]*Invest, error) {
+	var doc investExportJSON
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, errors.Wrap(ErrInvestExportMalformed, err.Error())
+	}
+
+	if doc.Version < 1 || InvestExportVersion < doc.Version {
+		return nil, errors.WithStack(ErrInvestExportVersionUnsupported)
+	}
+
+	invests := make([]*Invest, 0, len(doc.Invests))
+	for i, record := range doc.Invests {
+		invest, err := record.toDomain(userID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invest export: record %d", i)
+		}
+		invests = append(invests, invest)
+	}
+
+	return invests, nil
+}
+
+func (r investExportRecordJSON) toDomain(userID UserID) (*Invest, error) {
+	currency, err := NewCurrency(r.Currency)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	amount, err := NewAmountFromString(r.Amount, currency)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	investType, err := NewInvestType(r.Type)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	investedAt, err := NewInvestedAt(r.InvestedAt)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return NewInvest(userID, amount, investType, investedAt)
+}
@@ -0,0 +1,172 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportInvestsCSV(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("LoadLocation() = %v", err)
+	}
+	investedAt := time.Date(2023, time.March, 10, 15, 0, 0, 0, time.UTC)
+	invest := func(id uint64, amount string, currency string, investType string) *Invest {
+		t.Helper()
+
+		invest, err := NewInvestFromSource(id, 1, amount, currency, investType, investedAt, 1)
+		if err != nil {
+			t.Fatalf("NewInvestFromSource() = %v", err)
+		}
+
+		return invest
+	}
+	header := []string{"id", "invested_at", "type", "amount", "currency"}
+
+	tests := []struct {
+		name    string
+		invests []*Invest
+		loc     *time.Location
+		want    [][]string
+	}{
+		{
+			name:    "no invests",
+			invests: nil,
+			loc:     tokyo,
+			want:    [][]string{header},
+		},
+		{
+			name:    "dates in the given timezone",
+			invests: []*Invest{invest(7, "1234567.5", "USD", "stock"), invest(8, "5000", "JPY", "bond")},
+			loc:     tokyo,
+			want: [][]string{
+				header,
+				{"7", "2023-03-11T00:00:00+09:00", "stock", "1234567.50", "USD"},
+				{"8", "2023-03-11T00:00:00+09:00", "bond", "5000", "JPY"},
+			},
+		},
+		{
+			name:    "nil location is utc",
+			invests: []*Invest{invest(7, "0.1", "USD", "etf")},
+			loc:     nil,
+			want: [][]string{
+				header,
+				{"7", "2023-03-10T15:00:00Z", "etf", "0.10", "USD"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := ExportInvestsCSV(&buf, tt.invests, tt.loc); err != nil {
+				t.Fatalf("ExportInvestsCSV() = %v", err)
+			}
+
+			got, err := csv.NewReader(&buf).ReadAll()
+			if err != nil {
+				t.Fatalf("csv.ReadAll() = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ExportInvestsCSV() rows = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInvestsJSONRoundTrip(t *testing.T) {
+	investedAt := time.Date(2023, time.March, 10, 15, 0, 0, 0, time.FixedZone("JST", 9*60*60))
+	exported := []*Invest{}
+	for i, source := range []struct{ amount, currency, investType string }{
+		{amount: "1234.5", currency: "USD", investType: "stock"},
+		{amount: "5000", currency: "JPY", investType: "bond"},
+		{amount: "0.01", currency: "EUR", investType: "cash"},
+	} {
+		invest, err := NewInvestFromSource(uint64(i+1), 1, source.amount, source.currency, source.investType, investedAt, 3)
+		if err != nil {
+			t.Fatalf("NewInvestFromSource() = %v", err)
+		}
+		exported = append(exported, invest)
+	}
+
+	var buf bytes.Buffer
+	if err := ExportInvestsJSON(&buf, exported); err != nil {
+		t.Fatalf("ExportInvestsJSON() = %v", err)
+	}
+	if !strings.Contains(buf.String(), `"version":1`) {
+		t.Fatalf("ExportInvestsJSON() = %s, want a version 1 envelope", buf.String())
+	}
+
+	imported, err := ImportInvestsJSON(&buf, 2)
+	if err != nil {
+		t.Fatalf("ImportInvestsJSON() = %v", err)
+	}
+	if len(imported) != len(exported) {
+		t.Fatalf("ImportInvestsJSON() returned %d invests, want %d", len(imported), len(exported))
+	}
+
+	for i, got := range imported {
+		want := exported[i]
+		if got.ID() != 0 || got.UserID() != 2 {
+			t.Fatalf("invest %d ID(), UserID() = %d, %d, want 0, 2", i, got.ID(), got.UserID())
+		}
+		if got.Amount().String() != want.Amount().String() || got.Currency() != want.Currency() || got.Type() != want.Type() {
+			t.Fatalf("invest %d = %s %s %s, want %s %s %s", i, got.Amount(), got.Currency(), got.Type(), want.Amount(), want.Currency(), want.Type())
+		}
+		if !time.Time(got.InvestedAt()).Equal(time.Time(want.InvestedAt())) {
+			t.Fatalf("invest %d InvestedAt() = %v, want %v", i, got.InvestedAt(), want.InvestedAt())
+		}
+	}
+}
+
+func TestImportInvestsJSONInvalid(t *testing.T) {
+	const valid = `{"amount":"100","currency":"USD","type":"stock","invested_at":"2023-03-10T15:00:00Z"}`
+
+	tests := []struct {
+		name    string
+		doc     string
+		wantErr error
+	}{
+		{name: "not json", doc: `invests`, wantErr: ErrInvestExportMalformed},
+		{name: "truncated", doc: `{"version":1,"invests":[` + valid, wantErr: ErrInvestExportMalformed},
+		{name: "missing version", doc: `{"invests":[` + valid + `]}`, wantErr: ErrInvestExportVersionUnsupported},
+		{name: "newer version", doc: `{"version":2,"invests":[` + valid + `]}`, wantErr: ErrInvestExportVersionUnsupported},
+		{
+			name:    "invalid type after valid records",
+			doc:     `{"version":1,"invests":[` + valid + `,` + valid + `,{"amount":"1","currency":"USD","type":"gold","invested_at":"2023-03-10T15:00:00Z"}]}`,
+			wantErr: ErrInvestTypeInvalid,
+		},
+		{
+			name:    "invalid amount",
+			doc:     `{"version":1,"invests":[{"amount":"1,000","currency":"USD","type":"stock","invested_at":"2023-03-10T15:00:00Z"}]}`,
+			wantErr: ErrAmountInvalid,
+		},
+		{
+			name:    "unknown currency",
+			doc:     `{"version":1,"invests":[{"amount":"1","currency":"XYZ","type":"stock","invested_at":"2023-03-10T15:00:00Z"}]}`,
+			wantErr: ErrCurrencyInvalid,
+		},
+		{
+			name:    "future date",
+			doc:     `{"version":1,"invests":[{"amount":"1","currency":"USD","type":"stock","invested_at":"2999-01-01T00:00:00Z"}]}`,
+			wantErr: ErrInvestedAtFuture,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ImportInvestsJSON(strings.NewReader(tt.doc), 1)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ImportInvestsJSON() = %v, want %v", err, tt.wantErr)
+			}
+			if got != nil {
+				t.Fatalf("ImportInvestsJSON() = %d invests, want nil on error", len(got))
+			}
+		})
+	}
+}
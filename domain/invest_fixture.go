@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"math/rand"
+	"time"
+)
+
+var (
+	fakeInvestTypes = []InvestType{
+		InvestTypeStock,
+		InvestTypeBond,
+		InvestTypeCrypto,
+		InvestTypeETF,
+		InvestTypeCash,
+	}
+	fakeInvestEpoch = time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+)
+
+const (
+	fakeInvestSpanDays      = 3 * 365
+	fakeInvestMinMinorUnits = 1_00
+	fakeInvestMaxMinorUnits = 10_000_00
+)
+
+// FakeInvests returns n USD invests for userID with ids 1..n, spread over
+// the three years from 2020-01-01 UTC. The same seed always gives the same
+// invests, so tests using them are reproducible. It is meant for tests and
+// panics if the domain rules ever reject what it generates.
+func FakeInvests(seed int64, n int, userID UserID) []*Invest {
+	rng := rand.New(rand.NewSource(seed))
+
+	invests := make([]*Invest, 0, n)
+	for i := 0; i < n; i++ {
+		minorUnits := fakeInvestMinMinorUnits + rng.Int63n(fakeInvestMaxMinorUnits-fakeInvestMinMinorUnits+1)
+		investType := fakeInvestTypes[rng.Intn(len(fakeInvestTypes))]
+		at := fakeInvestEpoch.
+			AddDate(0, 0, rng.Intn(fakeInvestSpanDays)).
+			Add(time.Duration(rng.Intn(24*60*60)) * time.Second)
+
+		investedAt, err := NewInvestedAt(at)
+		if err != nil {
+			panic(err)
+		}
+
+		invest, err := NewInvest(userID, NewAmountFromMinorUnits(minorUnits, CurrencyUSD), investType, investedAt)
+		if err != nil {
+			panic(err)
+		}
+		invest.id = InvestID(i + 1)
+
+		invests = append(invests, invest)
+	}
+
+	return invests
+}
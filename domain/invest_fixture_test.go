@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func describeInvests(invests []*Invest) []string {
+	described := make([]string, 0, len(invests))
+	for _, invest := range invests {
+		described = append(described, fmt.Sprintf("%d %d %s %s %s",
+			invest.ID(), invest.UserID(), invest.Amount(), invest.Type(), time.Time(invest.InvestedAt()).Format(time.RFC3339)))
+	}
+
+	return described
+}
+
+func TestFakeInvestsDeterministic(t *testing.T) {
+	tests := []struct {
+		name     string
+		seed     int64
+		other    int64
+		n        int
+		wantSame bool
+	}{
+		{name: "same seed", seed: 42, other: 42, n: 50, wantSame: true},
+		{name: "negative seed", seed: -7, other: -7, n: 50, wantSame: true},
+		{name: "different seed", seed: 42, other: 43, n: 50, wantSame: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := describeInvests(FakeInvests(tt.seed, tt.n, 1))
+			other := describeInvests(FakeInvests(tt.other, tt.n, 1))
+			if len(got) != tt.n {
+				t.Fatalf("FakeInvests() returned %d invests, want %d", len(got), tt.n)
+			}
+			if reflect.DeepEqual(got, other) != tt.wantSame {
+				t.Fatalf("FakeInvests(%d) equal to FakeInvests(%d) = %v, want %v", tt.seed, tt.other, !tt.wantSame, tt.wantSame)
+			}
+		})
+	}
+
+	t.Run("prefix is stable", func(t *testing.T) {
+		short := describeInvests(FakeInvests(42, 10, 1))
+		long := describeInvests(FakeInvests(42, 20, 1))
+		if !reflect.DeepEqual(short, long[:10]) {
+			t.Fatalf("FakeInvests(42, 10) = %v, want the first 10 of FakeInvests(42, 20)", short)
+		}
+	})
+}
+
+func TestFakeInvestsValid(t *testing.T) {
+	invests := FakeInvests(1, 500, 9)
+	types := map[InvestType]bool{}
+
+	for i, invest := range invests {
+		if invest.ID() != InvestID(i+1) || invest.UserID() != 9 {
+			t.Fatalf("invest %d ID(), UserID() = %d, %d, want %d, 9", i, invest.ID(), invest.UserID(), i+1)
+		}
+
+		investedAt := time.Time(invest.InvestedAt())
+		if _, err := NewInvestFromSource(uint64(invest.ID()), uint64(invest.UserID()), invest.Amount().String(), string(invest.Currency()), string(invest.Type()), investedAt, 1); err != nil {
+			t.Fatalf("invest %d rejected by NewInvestFromSource() = %v", i, err)
+		}
+		if investedAt.Before(fakeInvestEpoch) || !investedAt.Before(fakeInvestEpoch.AddDate(0, 0, fakeInvestSpanDays)) {
+			t.Fatalf("invest %d InvestedAt() = %v, want within %d days of %v", i, investedAt, fakeInvestSpanDays, fakeInvestEpoch)
+		}
+		types[invest.Type()] = true
+	}
+
+	if len(types) != len(fakeInvestTypes) {
+		t.Fatalf("FakeInvests() types = %v, want all of %v", types, fakeInvestTypes)
+	}
+	if got := FakeInvests(1, 0, 9); len(got) != 0 {
+		t.Fatalf("FakeInvests(n=0) returned %d invests, want 0", len(got))
+	}
+}
@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewInvestFromSource(t *testing.T) {
+	past := time.Now().Add(-24 * time.Hour)
+
+	tests := []struct {
+		name       string
+		id         uint64
+		userID     uint64
+		amount     string
+		currency   string
+		investType string
+		investedAt time.Time
+		wantErr    error
+	}{
+		{name: "valid", id: 1, userID: 1, amount: "100.50", currency: "USD", investType: "stock", investedAt: past, wantErr: nil},
+		{name: "negative amount", id: 1, userID: 1, amount: "-10.00", currency: "USD", investType: "stock", investedAt: past, wantErr: ErrInvestAmountNotPositive},
+		{name: "zero amount", id: 1, userID: 1, amount: "0", currency: "USD", investType: "stock", investedAt: past, wantErr: ErrInvestAmountNotPositive},
+		{name: "future date", id: 1, userID: 1, amount: "100.50", currency: "USD", investType: "stock", investedAt: time.Now().Add(time.Hour), wantErr: ErrInvestedAtFuture},
+		{name: "missing date", id: 1, userID: 1, amount: "100.50", currency: "USD", investType: "stock", investedAt: time.Time{}, wantErr: ErrInvestedAtMissing},
+		{name: "zero id", id: 0, userID: 1, amount: "100.50", currency: "USD", investType: "stock", investedAt: past, wantErr: ErrInvestIDZero},
+		{name: "zero user id", id: 1, userID: 0, amount: "100.50", currency: "USD", investType: "stock", investedAt: past, wantErr: ErrUserIDZero},
+		{name: "unknown type", id: 1, userID: 1, amount: "100.50", currency: "USD", investType: "stcok", investedAt: past, wantErr: ErrInvestTypeInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invest, err := NewInvestFromSource(tt.id, tt.userID, tt.amount, tt.currency, tt.investType, tt.investedAt, 1)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewInvestFromSource() = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if invest.ID() != InvestID(tt.id) || invest.UserID() != UserID(tt.userID) || invest.Amount().String() != tt.amount {
+				t.Fatalf("NewInvestFromSource() = %v/%v/%v", invest.ID(), invest.UserID(), invest.Amount())
+			}
+			if !time.Time(invest.InvestedAt()).Equal(tt.investedAt) {
+				t.Fatalf("InvestedAt() = %v, want %v", invest.InvestedAt(), tt.investedAt)
+			}
+		})
+	}
+}
+
+func TestNewInvestedAtWithClock(t *testing.T) {
+	clock := newFakeClock()
+
+	tests := []struct {
+		name    string
+		v       time.Time
+		wantErr error
+	}{
+		{name: "past", v: clock.Now().Add(-time.Hour), wantErr: nil},
+		{name: "now", v: clock.Now(), wantErr: nil},
+		{name: "at the skew", v: clock.Now().Add(InvestedAtClockSkew), wantErr: nil},
+		{name: "past the skew", v: clock.Now().Add(InvestedAtClockSkew + time.Nanosecond), wantErr: ErrInvestedAtFuture},
+		{name: "years ahead", v: clock.Now().AddDate(3, 0, 0), wantErr: ErrInvestedAtFuture},
+		{name: "zero", v: time.Time{}, wantErr: ErrInvestedAtMissing},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewInvestedAtWithClock(tt.v, clock); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewInvestedAtWithClock(%v) = %v, want %v", tt.v, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestInvestWithdraw(t *testing.T) {
+	usd := func(minorUnits int64) Amount { return NewAmountFromMinorUnits(minorUnits, CurrencyUSD) }
+
+	tests := []struct {
+		name    string
+		amount  Amount
+		want    Amount
+		wantErr error
+	}{
+		{name: "partial", amount: usd(40_00), want: usd(60_00), wantErr: nil},
+		{name: "all but a cent", amount: usd(99_99), want: usd(1), wantErr: nil},
+		{name: "full", amount: usd(100_00), wantErr: ErrInvestFullyWithdrawn},
+		{name: "over", amount: usd(100_01), wantErr: ErrWithdrawalExceedsBalance},
+		{name: "zero", amount: usd(0), wantErr: ErrInvalidWithdrawal},
+		{name: "negative", amount: usd(-1_00), wantErr: ErrInvalidWithdrawal},
+		{name: "other currency", amount: NewAmountFromMinorUnits(1_00, CurrencyHKD), wantErr: ErrCurrencyMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invest := newTestInvest(t, 1, "100.00", "USD", "stock")
+
+			got, err := invest.Withdraw(tt.amount)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Withdraw(%s) = %v, want %v", tt.amount, err, tt.wantErr)
+			}
+			if invest.Amount() != usd(100_00) {
+				t.Fatalf("Withdraw() changed the original amount to %s", invest.Amount())
+			}
+			if err != nil {
+				return
+			}
+
+			if got.Amount() != tt.want {
+				t.Fatalf("Withdraw(%s).Amount() = %s, want %s", tt.amount, got.Amount(), tt.want)
+			}
+			if got.ID() != invest.ID() || got.Version() != invest.Version() {
+				t.Fatalf("Withdraw() = id %d version %d, want id %d version %d", got.ID(), got.Version(), invest.ID(), invest.Version())
+			}
+			// What Withdraw returns is saved by UpdateInvest, so it must load back.
+			if _, err := NewInvestFromSource(uint64(got.ID()), uint64(got.UserID()), got.Amount().String(), string(got.Currency()), string(got.Type()), time.Time(got.InvestedAt()), uint64(got.Version())); err != nil {
+				t.Fatalf("NewInvestFromSource(Withdraw()) = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+type InvestType string
+
+const (
+	InvestTypeStock  InvestType = "stock"
+	InvestTypeBond   InvestType = "bond"
+	InvestTypeCrypto InvestType = "crypto"
+	InvestTypeETF    InvestType = "etf"
+	InvestTypeCash   InvestType = "cash"
+)
+
+var (
+	ErrInvestTypeEmpty   = newFieldError("type", "empty", "invest type: must not be empty")
+	ErrInvestTypeInvalid = newFieldError("type", "invalid", "invest type: invalid type")
+)
+
+var DefaultInvestTypeRegistry = NewInvestTypeRegistry(
+	InvestTypeStock,
+	InvestTypeBond,
+	InvestTypeCrypto,
+	InvestTypeETF,
+	InvestTypeCash,
+)
+
+func NewInvestType(v string) (InvestType, error) {
+	return DefaultInvestTypeRegistry.Validate(v)
+}
+
+// InvestTypeRegistry holds the types accepted by NewInvestType so that
+// deployments can add their own.
+type InvestTypeRegistry struct {
+	mu    sync.RWMutex
+	types map[InvestType]struct{}
+}
+
+func NewInvestTypeRegistry(types ...InvestType) *InvestTypeRegistry {
+	registry := &InvestTypeRegistry{types: make(map[InvestType]struct{}, len(types))}
+	for _, investType := range types {
+		registry.types[investType] = struct{}{}
+	}
+
+	return registry
+}
+
+func (r *InvestTypeRegistry) Register(name string) error {
+	if name == "" {
+		return errors.WithStack(ErrInvestTypeEmpty)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.types[InvestType(name)] = struct{}{}
+
+	return nil
+}
+
+func (r *InvestTypeRegistry) Validate(v string) (InvestType, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.types[InvestType(v)]; !ok {
+		return InvestType(""), errors.WithStack(ErrInvestTypeInvalid)
+	}
+
+	return InvestType(v), nil
+}
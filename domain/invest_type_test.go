@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewInvestType(t *testing.T) {
+	tests := []struct {
+		name    string
+		v       string
+		wantErr error
+	}{
+		{name: "stock", v: "stock", wantErr: nil},
+		{name: "etf", v: "etf", wantErr: nil},
+		{name: "typo", v: "stcok", wantErr: ErrInvestTypeInvalid},
+		{name: "wrong case", v: "Stock", wantErr: ErrInvestTypeInvalid},
+		{name: "empty", v: "", wantErr: ErrInvestTypeInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewInvestType(tt.v)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewInvestType(%q) = %v, want %v", tt.v, err, tt.wantErr)
+			}
+			if err == nil && got != InvestType(tt.v) {
+				t.Fatalf("NewInvestType(%q) = %q", tt.v, got)
+			}
+		})
+	}
+}
+
+func TestInvestTypeRegistry(t *testing.T) {
+	registry := NewInvestTypeRegistry(InvestTypeStock)
+
+	if err := registry.Register("reit"); err != nil {
+		t.Fatalf("Register() = %v", err)
+	}
+	if err := registry.Register(""); !errors.Is(err, ErrInvestTypeEmpty) {
+		t.Fatalf("Register(\"\") = %v, want %v", err, ErrInvestTypeEmpty)
+	}
+
+	tests := []struct {
+		v       string
+		wantErr error
+	}{
+		{v: "stock", wantErr: nil},
+		{v: "reit", wantErr: nil},
+		{v: "bond", wantErr: ErrInvestTypeInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.v, func(t *testing.T) {
+			if _, err := registry.Validate(tt.v); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Validate(%q) = %v, want %v", tt.v, err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := NewInvestType("reit"); !errors.Is(err, ErrInvestTypeInvalid) {
+		t.Fatalf("NewInvestType() = %v, want the default registry to be unaffected", err)
+	}
+}
@@ -0,0 +1,139 @@
+package domain
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const jwtMinSecretKeySize = 32
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+	Kid string `json:"kid,omitempty"`
+}
+
+const (
+	jwtAlgHS256 = "HS256"
+	jwtTyp      = "JWT"
+)
+
+type JWTMaker struct {
+	keyRing *KeyRing
+}
+
+func NewJWTMaker(secretKey SymmetricKey) (TokenMaker, error) {
+	if len(secretKey) < jwtMinSecretKeySize {
+		return nil, errors.WithStack(fmt.Errorf("invalid key size: must be at least %d characters", jwtMinSecretKeySize))
+	}
+
+	keyRing, err := NewKeyRing(DefaultKeyID, secretKey, 0, SystemClock{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &JWTMaker{keyRing: keyRing}, nil
+}
+
+// NewJWTMakerWithKeyRing signs with the ring's current key and verifies
+// against any key the ring still holds, so a rotation does not log everyone
+// out.
+func NewJWTMakerWithKeyRing(keyRing *KeyRing) TokenMaker {
+	return &JWTMaker{keyRing: keyRing}
+}
+
+func (maker *JWTMaker) CreateToken(user *User, duration time.Duration) (Token, *Payload, error) {
+	payload, err := NewPayload(user.ID(), user.Role(), duration)
+	if err != nil {
+		return "", nil, errors.WithStack(err)
+	}
+
+	kid, key := maker.keyRing.Current()
+
+	header, err := json.Marshal(jwtHeader{Alg: jwtAlgHS256, Typ: jwtTyp, Kid: kid})
+	if err != nil {
+		return "", nil, errors.WithStack(err)
+	}
+
+	claims, err := json.Marshal(payload)
+	if err != nil {
+		return "", nil, errors.WithStack(err)
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	token, err := NewToken(signingInput + "." + signHS256(key, signingInput))
+	if err != nil {
+		return "", nil, errors.WithStack(err)
+	}
+
+	return token, payload, nil
+}
+
+// VerifyToken only accepts HS256 so a token cannot pick a weaker algorithm.
+// Tokens without a kid were issued before key rotation and are checked
+// against DefaultKeyID.
+func (maker *JWTMaker) VerifyToken(token Token) (*Payload, error) {
+	parts := strings.Split(string(token), ".")
+	if len(parts) != 3 {
+		return nil, errors.WithStack(ErrInvalidToken)
+	}
+
+	rawHeader, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, errors.Wrap(ErrInvalidToken, err.Error())
+	}
+
+	header := jwtHeader{}
+	if err := json.Unmarshal(rawHeader, &header); err != nil {
+		return nil, errors.Wrap(ErrInvalidToken, err.Error())
+	}
+	if header.Alg != jwtAlgHS256 || header.Typ != jwtTyp {
+		return nil, errors.WithStack(ErrInvalidToken)
+	}
+
+	kid := header.Kid
+	if kid == "" {
+		kid = DefaultKeyID
+	}
+
+	key, ok := maker.keyRing.Key(kid)
+	if !ok {
+		return nil, errors.WithStack(ErrInvalidToken)
+	}
+
+	signingInput := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(signHS256(key, signingInput))) {
+		return nil, errors.WithStack(ErrInvalidToken)
+	}
+
+	rawClaims, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, errors.Wrap(ErrInvalidToken, err.Error())
+	}
+
+	payload := &Payload{}
+	if err := json.Unmarshal(rawClaims, payload); err != nil {
+		return nil, errors.Wrap(ErrInvalidToken, err.Error())
+	}
+
+	if err := payload.Valid(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return payload, nil
+}
+
+func signHS256(key SymmetricKey, signingInput string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(signingInput))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
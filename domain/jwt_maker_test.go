@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSymmetricKey = "12345678901234567890123456789012"
+
+func TestNewJWTMakerKeySize(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{name: "31 bytes", key: testSymmetricKey[:31], wantErr: true},
+		{name: "32 bytes", key: testSymmetricKey, wantErr: false},
+		{name: "64 bytes", key: testSymmetricKey + testSymmetricKey, wantErr: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewJWTMaker(SymmetricKey(tt.key)); (err != nil) != tt.wantErr {
+				t.Fatalf("NewJWTMaker() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestJWTMaker(t *testing.T) {
+	maker, err := NewJWTMaker(SymmetricKey(testSymmetricKey))
+	if err != nil {
+		t.Fatalf("NewJWTMaker() = %v", err)
+	}
+	otherMaker, err := NewJWTMaker(SymmetricKey(strings.Repeat("x", jwtMinSecretKeySize)))
+	if err != nil {
+		t.Fatalf("NewJWTMaker() = %v", err)
+	}
+	user := newTestUser(t, RoleUser)
+
+	valid, payload, err := maker.CreateToken(user, time.Minute)
+	if err != nil {
+		t.Fatalf("CreateToken() = %v", err)
+	}
+	expired, _, err := maker.CreateToken(user, -time.Minute)
+	if err != nil {
+		t.Fatalf("CreateToken() = %v", err)
+	}
+
+	parts := strings.Split(string(valid), ".")
+	signature := []byte(parts[2])
+	signature[0] ^= 1
+	tampered := Token(parts[0] + "." + parts[1] + "." + string(signature))
+
+	noneHeader := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`))
+	unsigned := Token(noneHeader + "." + parts[1] + ".")
+
+	tests := []struct {
+		name    string
+		maker   TokenMaker
+		token   Token
+		wantErr error
+	}{
+		{name: "valid", maker: maker, token: valid, wantErr: nil},
+		{name: "expired", maker: maker, token: expired, wantErr: ErrExpiredToken},
+		{name: "tampered signature", maker: maker, token: tampered, wantErr: ErrInvalidToken},
+		{name: "alg none", maker: maker, token: unsigned, wantErr: ErrInvalidToken},
+		{name: "wrong key", maker: otherMaker, token: valid, wantErr: ErrInvalidToken},
+		{name: "malformed", maker: maker, token: "not-a-jwt", wantErr: ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.maker.VerifyToken(tt.token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("VerifyToken() = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if got.ID != payload.ID || got.UserID != user.ID() || got.Role != user.Role() {
+				t.Fatalf("VerifyToken() = %+v, want %+v", got, payload)
+			}
+			if time.Time(got.ExpiresAt).Before(got.IssuedAt) {
+				t.Fatalf("VerifyToken() expires at %v before issued at %v", got.ExpiresAt, got.IssuedAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultKeyID names the key of a ring built from a single secret.
+const DefaultKeyID = "default"
+
+var (
+	ErrKeyIDEmpty     = errors.New("key ring: key id must not be empty")
+	ErrKeyIDDuplicate = errors.New("key ring: key id already in the ring")
+)
+
+type ringKey struct {
+	key       SymmetricKey
+	retiredAt time.Time
+}
+
+// KeyRing holds the current signing key and the keys it replaced. A replaced
+// key keeps verifying tokens for the grace period, which should be at least
+// the longest token lifetime, and is dropped afterwards.
+type KeyRing struct {
+	mu         sync.Mutex
+	currentKid string
+	keys       map[string]ringKey
+	grace      time.Duration
+	clock      Clock
+}
+
+func NewKeyRing(kid string, key SymmetricKey, grace time.Duration, clock Clock) (*KeyRing, error) {
+	if err := validateRingKey(kid, key); err != nil {
+		return nil, err
+	}
+
+	if clock == nil {
+		clock = SystemClock{}
+	}
+
+	return &KeyRing{
+		currentKid: kid,
+		keys:       map[string]ringKey{kid: {key: key}},
+		grace:      grace,
+		clock:      clock,
+	}, nil
+}
+
+// Rotate makes key the signing key. The previous one stays valid for
+// verification until the grace period has passed.
+func (r *KeyRing) Rotate(kid string, key SymmetricKey) error {
+	if err := validateRingKey(kid, key); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.prune()
+	if _, ok := r.keys[kid]; ok {
+		return errors.WithStack(ErrKeyIDDuplicate)
+	}
+
+	previous := r.keys[r.currentKid]
+	previous.retiredAt = r.clock.Now()
+	r.keys[r.currentKid] = previous
+
+	r.keys[kid] = ringKey{key: key}
+	r.currentKid = kid
+
+	return nil
+}
+
+func (r *KeyRing) Current() (string, SymmetricKey) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.currentKid, r.keys[r.currentKid].key
+}
+
+// Key returns the key for kid if it is current or still within its grace
+// period.
+func (r *KeyRing) Key(kid string) (SymmetricKey, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.prune()
+	k, ok := r.keys[kid]
+
+	return k.key, ok
+}
+
+func (r *KeyRing) prune() {
+	now := r.clock.Now()
+	for kid, k := range r.keys {
+		if kid != r.currentKid && now.Sub(k.retiredAt) > r.grace {
+			delete(r.keys, kid)
+		}
+	}
+}
+
+func validateRingKey(kid string, key SymmetricKey) error {
+	if kid == "" {
+		return errors.WithStack(ErrKeyIDEmpty)
+	}
+
+	if len(key) < jwtMinSecretKeySize {
+		return errors.WithStack(fmt.Errorf("invalid key size: must be at least %d characters", jwtMinSecretKeySize))
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKeyRingRotation(t *testing.T) {
+	const grace = time.Hour
+	previousKey := SymmetricKey(testSymmetricKey)
+	currentKey := SymmetricKey(strings.Repeat("x", jwtMinSecretKeySize))
+	user := newTestUser(t, RoleUser)
+
+	tests := []struct {
+		name         string
+		sinceRotate  time.Duration
+		wantPrevious error
+	}{
+		{name: "just rotated", sinceRotate: 0, wantPrevious: nil},
+		{name: "within grace", sinceRotate: grace, wantPrevious: nil},
+		{name: "after grace", sinceRotate: grace + time.Second, wantPrevious: ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newFakeClock()
+			ring, err := NewKeyRing("v1", previousKey, grace, clock)
+			if err != nil {
+				t.Fatalf("NewKeyRing() = %v", err)
+			}
+			maker := NewJWTMakerWithKeyRing(ring)
+
+			signedWithPrevious, _, err := maker.CreateToken(user, time.Minute)
+			if err != nil {
+				t.Fatalf("CreateToken() = %v", err)
+			}
+			if err := ring.Rotate("v2", currentKey); err != nil {
+				t.Fatalf("Rotate() = %v", err)
+			}
+			signedWithCurrent, _, err := maker.CreateToken(user, time.Minute)
+			if err != nil {
+				t.Fatalf("CreateToken() = %v", err)
+			}
+
+			clock.Advance(tt.sinceRotate)
+
+			if _, err := maker.VerifyToken(signedWithPrevious); !errors.Is(err, tt.wantPrevious) {
+				t.Fatalf("VerifyToken(previous key) = %v, want %v", err, tt.wantPrevious)
+			}
+			if _, err := maker.VerifyToken(signedWithCurrent); err != nil {
+				t.Fatalf("VerifyToken(current key) = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestKeyRingCurrent(t *testing.T) {
+	ring, err := NewKeyRing("v1", SymmetricKey(testSymmetricKey), time.Hour, newFakeClock())
+	if err != nil {
+		t.Fatalf("NewKeyRing() = %v", err)
+	}
+	next := SymmetricKey(strings.Repeat("x", jwtMinSecretKeySize))
+	if err := ring.Rotate("v2", next); err != nil {
+		t.Fatalf("Rotate() = %v", err)
+	}
+
+	if kid, key := ring.Current(); kid != "v2" || string(key) != string(next) {
+		t.Fatalf("Current() = %q, %q, want %q, %q", kid, key, "v2", next)
+	}
+	if _, ok := ring.Key("v1"); !ok {
+		t.Fatal("Key(v1) = false, want true within the grace period")
+	}
+	if _, ok := ring.Key("v0"); ok {
+		t.Fatal("Key(v0) = true, want false for an unknown kid")
+	}
+}
+
+func TestKeyRingRotateInvalid(t *testing.T) {
+	validKey := SymmetricKey(strings.Repeat("x", jwtMinSecretKeySize))
+
+	tests := []struct {
+		name    string
+		kid     string
+		key     SymmetricKey
+		wantErr error
+	}{
+		{name: "empty kid", kid: "", key: validKey, wantErr: ErrKeyIDEmpty},
+		{name: "current kid", kid: "v1", key: validKey, wantErr: ErrKeyIDDuplicate},
+		{name: "short key", kid: "v2", key: validKey[:jwtMinSecretKeySize-1], wantErr: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ring, err := NewKeyRing("v1", SymmetricKey(testSymmetricKey), time.Hour, newFakeClock())
+			if err != nil {
+				t.Fatalf("NewKeyRing() = %v", err)
+			}
+
+			err = ring.Rotate(tt.kid, tt.key)
+			if err == nil {
+				t.Fatal("Rotate() = nil, want an error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Rotate() = %v, want %v", err, tt.wantErr)
+			}
+			if kid, _ := ring.Current(); kid != "v1" {
+				t.Fatalf("Current() kid = %q after a failed Rotate, want %q", kid, "v1")
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package domain
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrAccountLocked          = errors.New("account: locked after repeated login failures")
+	ErrLockoutPolicyThreshold = errors.New("lockout policy: threshold must be positive")
+	ErrLockoutPolicyDuration  = errors.New("lockout policy: duration must be positive and not exceed the max duration")
+)
+
+// LoginAttempts is the failure state of one user. LockedUntil is zero while
+// the account is not locked.
+type LoginAttempts struct {
+	Failures    int
+	LockedUntil time.Time
+}
+
+// LoginAttemptStore persists LoginAttempts so that the lockout survives
+// across requests and, with a shared store, across instances. Update must
+// apply fn atomically, e.g. under a lock or in a row-locking transaction, or
+// concurrent failures overwrite each other's increments.
+type LoginAttemptStore interface {
+	Get(userID UserID) (LoginAttempts, error)
+	Update(userID UserID, fn func(LoginAttempts) LoginAttempts) (LoginAttempts, error)
+	Reset(userID UserID) error
+}
+
+type InMemoryLoginAttemptStore struct {
+	mu       sync.Mutex
+	attempts map[UserID]LoginAttempts
+}
+
+func NewInMemoryLoginAttemptStore() *InMemoryLoginAttemptStore {
+	return &InMemoryLoginAttemptStore{attempts: map[UserID]LoginAttempts{}}
+}
+
+func (s *InMemoryLoginAttemptStore) Get(userID UserID) (LoginAttempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.attempts[userID], nil
+}
+
+func (s *InMemoryLoginAttemptStore) Update(
+	userID UserID,
+	fn func(LoginAttempts) LoginAttempts,
+) (LoginAttempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempts := fn(s.attempts[userID])
+	s.attempts[userID] = attempts
+
+	return attempts, nil
+}
+
+func (s *InMemoryLoginAttemptStore) Reset(userID UserID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.attempts, userID)
+
+	return nil
+}
+
+// LockoutPolicy locks an account after threshold consecutive failures. Each
+// failure past the threshold doubles the lock, starting at duration and
+// capped at maxDuration.
+type LockoutPolicy struct {
+	threshold   int
+	duration    time.Duration
+	maxDuration time.Duration
+	store       LoginAttemptStore
+	clock       Clock
+}
+
+func NewLockoutPolicy(
+	threshold int,
+	duration time.Duration,
+	maxDuration time.Duration,
+	store LoginAttemptStore,
+	clock Clock,
+) (*LockoutPolicy, error) {
+	if threshold <= 0 {
+		return nil, errors.WithStack(ErrLockoutPolicyThreshold)
+	}
+
+	if duration <= 0 || maxDuration < duration {
+		return nil, errors.WithStack(ErrLockoutPolicyDuration)
+	}
+
+	return &LockoutPolicy{
+		threshold:   threshold,
+		duration:    duration,
+		maxDuration: maxDuration,
+		store:       store,
+		clock:       clock,
+	}, nil
+}
+
+func (p *LockoutPolicy) Check(userID UserID) error {
+	attempts, err := p.store.Get(userID)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if p.clock.Now().Before(attempts.LockedUntil) {
+		return errors.WithStack(ErrAccountLocked)
+	}
+
+	return nil
+}
+
+func (p *LockoutPolicy) RecordFailure(userID UserID) error {
+	_, err := p.store.Update(userID, func(attempts LoginAttempts) LoginAttempts {
+		attempts.Failures++
+		if attempts.Failures >= p.threshold {
+			attempts.LockedUntil = p.clock.Now().Add(p.lockDuration(attempts.Failures - p.threshold))
+		}
+
+		return attempts
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+func (p *LockoutPolicy) RecordSuccess(userID UserID) error {
+	if err := p.store.Reset(userID); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+func (p *LockoutPolicy) lockDuration(excess int) time.Duration {
+	d := p.duration
+	for i := 0; i < excess; i++ {
+		d *= 2
+		if d >= p.maxDuration {
+			return p.maxDuration
+		}
+	}
+
+	return d
+}
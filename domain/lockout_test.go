@@ -0,0 +1,141 @@
+package domain
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+func newTestLockoutPolicy(t *testing.T, threshold int, clock Clock) (*LockoutPolicy, *InMemoryLoginAttemptStore) {
+	t.Helper()
+
+	store := NewInMemoryLoginAttemptStore()
+	policy, err := NewLockoutPolicy(threshold, time.Minute, time.Hour, store, clock)
+	if err != nil {
+		t.Fatalf("NewLockoutPolicy() = %v", err)
+	}
+
+	return policy, store
+}
+
+func TestLockoutPolicy(t *testing.T) {
+	const userID UserID = 1
+	clock := newFakeClock()
+	policy, _ := newTestLockoutPolicy(t, 3, clock)
+
+	for i := 0; i < 2; i++ {
+		if err := policy.RecordFailure(userID); err != nil {
+			t.Fatalf("RecordFailure() = %v", err)
+		}
+		if err := policy.Check(userID); err != nil {
+			t.Fatalf("Check() after %d failures = %v, want nil", i+1, err)
+		}
+	}
+
+	if err := policy.RecordFailure(userID); err != nil {
+		t.Fatalf("RecordFailure() = %v", err)
+	}
+	if err := policy.Check(userID); !errors.Is(err, ErrAccountLocked) {
+		t.Fatalf("Check() at threshold = %v, want %v", err, ErrAccountLocked)
+	}
+
+	clock.Advance(time.Minute)
+	if err := policy.Check(userID); err != nil {
+		t.Fatalf("Check() after lock expiry = %v, want nil", err)
+	}
+
+	if err := policy.RecordSuccess(userID); err != nil {
+		t.Fatalf("RecordSuccess() = %v", err)
+	}
+	if err := policy.RecordFailure(userID); err != nil {
+		t.Fatalf("RecordFailure() = %v", err)
+	}
+	if err := policy.Check(userID); err != nil {
+		t.Fatalf("Check() after reset and one failure = %v, want nil", err)
+	}
+}
+
+func TestLockoutPolicyBackoff(t *testing.T) {
+	const userID UserID = 1
+	clock := newFakeClock()
+	policy, store := newTestLockoutPolicy(t, 1, clock)
+
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 1, want: time.Minute},
+		{failures: 2, want: 2 * time.Minute},
+		{failures: 3, want: 4 * time.Minute},
+		{failures: 8, want: time.Hour},
+	}
+
+	recorded := 0
+	for _, tt := range tests {
+		for ; recorded < tt.failures; recorded++ {
+			if err := policy.RecordFailure(userID); err != nil {
+				t.Fatalf("RecordFailure() = %v", err)
+			}
+		}
+
+		attempts, err := store.Get(userID)
+		if err != nil {
+			t.Fatalf("Get() = %v", err)
+		}
+		if got := attempts.LockedUntil.Sub(clock.Now()); got != tt.want {
+			t.Fatalf("lock after %d failures = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestLockoutPolicyConcurrentFailures(t *testing.T) {
+	const (
+		userID     UserID = 1
+		goroutines        = 50
+	)
+	policy, store := newTestLockoutPolicy(t, goroutines*2, newFakeClock())
+
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := policy.RecordFailure(userID); err != nil {
+				t.Errorf("RecordFailure() = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	attempts, err := store.Get(userID)
+	if err != nil {
+		t.Fatalf("Get() = %v", err)
+	}
+	if attempts.Failures != goroutines {
+		t.Fatalf("Failures = %d, want %d", attempts.Failures, goroutines)
+	}
+}
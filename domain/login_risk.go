@@ -0,0 +1,54 @@
+package domain
+
+type RiskLevel int
+
+const (
+	RiskLow RiskLevel = iota
+	RiskMedium
+	RiskHigh
+)
+
+func (r RiskLevel) String() string {
+	switch r {
+	case RiskMedium:
+		return "medium"
+	case RiskHigh:
+		return "high"
+	}
+
+	return "low"
+}
+
+// AssessLogin compares a login with the recent ones of the same user. Each
+// signal raises the level by one step: a device fingerprint not seen
+// recently, and a public address when every recent login came from a private
+// network. The first login has nothing to compare against and is low risk.
+//
+// TODO: add a country change signal once a GeoIP resolver is available.
+func AssessLogin(current *UserMetaData, recent []*UserMetaData) RiskLevel {
+	if len(recent) == 0 {
+		return RiskLow
+	}
+
+	fingerprint := current.Fingerprint()
+	knownDevice := false
+	allPrivate := true
+	for _, past := range recent {
+		if past.Fingerprint() == fingerprint {
+			knownDevice = true
+		}
+		if !past.ClientIp().IsPrivate() {
+			allPrivate = false
+		}
+	}
+
+	risk := RiskLow
+	if !knownDevice {
+		risk++
+	}
+	if allPrivate && current.ClientIp().IsPublic() {
+		risk++
+	}
+
+	return risk
+}
@@ -0,0 +1,84 @@
+package domain
+
+import "testing"
+
+func TestAssessLogin(t *testing.T) {
+	const (
+		chromeMac = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/114.0.0.0 Safari/537.36"
+		chromeWin = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/114.0.0.0 Safari/537.36"
+	)
+
+	meta := func(userAgent UserAgent, clientIp ClientIp) *UserMetaData {
+		m, err := NewUserMetadata(userAgent, clientIp)
+		if err != nil {
+			t.Fatalf("NewUserMetadata() = %v", err)
+		}
+
+		return m
+	}
+
+	tests := []struct {
+		name    string
+		current *UserMetaData
+		recent  []*UserMetaData
+		want    RiskLevel
+	}{
+		{
+			name:    "first login",
+			current: meta(chromeMac, "203.0.113.42"),
+			recent:  nil,
+			want:    RiskLow,
+		},
+		{
+			name:    "known device",
+			current: meta(chromeMac, "203.0.113.42"),
+			recent:  []*UserMetaData{meta(chromeWin, "198.51.100.7"), meta(chromeMac, "203.0.113.9")},
+			want:    RiskLow,
+		},
+		{
+			name:    "new device",
+			current: meta(chromeWin, "203.0.113.42"),
+			recent:  []*UserMetaData{meta(chromeMac, "203.0.113.42")},
+			want:    RiskMedium,
+		},
+		{
+			name:    "known device jumping from private to public",
+			current: meta(chromeMac, "203.0.113.42"),
+			recent:  []*UserMetaData{meta(chromeMac, "203.0.113.42"), meta(chromeMac, "10.0.0.5")},
+			want:    RiskLow,
+		},
+		{
+			name:    "new device jumping from private to public",
+			current: meta(chromeWin, "203.0.113.42"),
+			recent:  []*UserMetaData{meta(chromeMac, "10.0.0.5"), meta(chromeMac, "192.168.1.8")},
+			want:    RiskHigh,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AssessLogin(tt.current, tt.recent); got != tt.want {
+				t.Fatalf("AssessLogin() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRiskLevelString(t *testing.T) {
+	tests := []struct {
+		level RiskLevel
+		want  string
+	}{
+		{level: RiskLow, want: "low"},
+		{level: RiskMedium, want: "medium"},
+		{level: RiskHigh, want: "high"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.level.String(); got != tt.want {
+				t.Fatalf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
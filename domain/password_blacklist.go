@@ -0,0 +1,61 @@
+package domain
+
+import (
+	_ "embed"
+	"strings"
+)
+
+//go:embed common_passwords.txt
+var commonPasswords string
+
+var DefaultPasswordBlacklist = NewPasswordBlacklist(strings.Fields(commonPasswords))
+
+// PasswordBlacklist holds normalized passwords so that case changes,
+// leetspeak substitutions and trailing digits or symbols still match.
+type PasswordBlacklist map[string]struct{}
+
+func NewPasswordBlacklist(words []string) PasswordBlacklist {
+	blacklist := make(PasswordBlacklist, len(words))
+	for _, word := range words {
+		blacklist[normalizeLeetspeak(strings.ToLower(word))] = struct{}{}
+	}
+
+	return blacklist
+}
+
+func (b PasswordBlacklist) Contains(v string) bool {
+	lower := strings.ToLower(v)
+	base := strings.TrimRightFunc(lower, func(r rune) bool {
+		return !('a' <= r && r <= 'z')
+	})
+
+	for _, candidate := range []string{lower, base} {
+		if candidate == "" {
+			continue
+		}
+		if _, ok := b[normalizeLeetspeak(candidate)]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+var leetspeakReplacer = strings.NewReplacer(
+	"@", "a",
+	"4", "a",
+	"8", "b",
+	"3", "e",
+	"6", "g",
+	"1", "i",
+	"!", "i",
+	"0", "o",
+	"$", "s",
+	"5", "s",
+	"7", "t",
+	"+", "t",
+)
+
+func normalizeLeetspeak(v string) string {
+	return leetspeakReplacer.Replace(v)
+}
@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewPasswordWithBlacklist(t *testing.T) {
+	blacklist := NewPasswordBlacklist([]string{"password", "dragon"})
+
+	tests := []struct {
+		name     string
+		password string
+		wantErr  error
+	}{
+		{name: "exact with suffix", password: "password1!", wantErr: ErrPasswordBlacklisted},
+		{name: "case insensitive", password: "PassWord9#", wantErr: ErrPasswordBlacklisted},
+		{name: "leetspeak", password: "p@ssw0rd1!", wantErr: ErrPasswordBlacklisted},
+		{name: "another entry", password: "Dr@g0n42!", wantErr: ErrPasswordBlacklisted},
+		{name: "not listed", password: "Xq7!kLmQ", wantErr: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewPasswordWithBlacklist(tt.password, blacklist); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewPasswordWithBlacklist(%q) = %v, want %v", tt.password, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewPasswordDefaultBlacklist(t *testing.T) {
+	if _, err := NewPassword("Password1!"); !errors.Is(err, ErrPasswordBlacklisted) {
+		t.Fatalf("NewPassword() = %v, want %v", err, ErrPasswordBlacklisted)
+	}
+}
@@ -0,0 +1,245 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// PasswordHasher hashes with its own scheme but verifies any stored scheme,
+// dispatching on the hash prefix, so hashes made before a switch keep working.
+type PasswordHasher interface {
+	Hash(pass Password) (HashedPassword, error)
+	Verify(hashed HashedPassword, pass Password) error
+}
+
+type BcryptHasher struct {
+	cost int
+}
+
+func NewBcryptHasher(cost int) (PasswordHasher, error) {
+	if cost < bcrypt.MinCost || bcrypt.MaxCost < cost {
+		return nil, errors.WithStack(ErrPasswordHashCostInvalid)
+	}
+
+	return &BcryptHasher{cost: cost}, nil
+}
+
+func (h *BcryptHasher) Hash(pass Password) (HashedPassword, error) {
+	hashed, err := pass.HashWithCost(h.cost)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return hashed, nil
+}
+
+func (h *BcryptHasher) Verify(hashed HashedPassword, pass Password) error {
+	return hashed.Verify(pass)
+}
+
+var ErrBcryptCalibrationTargetInvalid = errors.New("bcrypt calibration: target must be positive")
+
+// calibrationPassword is only hashed to time bcrypt.
+const calibrationPassword = "calibration-password"
+
+// CalibrateBcryptCost returns the lowest cost, from bcrypt.MinCost up to
+// bcrypt.MaxCost, whose hash takes at least target on this host. Each cost
+// doubles the work, so once twice the last measurement reaches target the
+// next cost is returned without measuring it. Call it once at startup and
+// pass the result to NewBcryptHasher.
+func CalibrateBcryptCost(target time.Duration) (int, error) {
+	if target <= 0 {
+		return 0, errors.WithStack(ErrBcryptCalibrationTargetInvalid)
+	}
+
+	for cost := bcrypt.MinCost; cost < bcrypt.MaxCost; cost++ {
+		start := time.Now()
+		if _, err := bcrypt.GenerateFromPassword([]byte(calibrationPassword), cost); err != nil {
+			return 0, errors.WithStack(err)
+		}
+		elapsed := time.Since(start)
+
+		if elapsed >= target {
+			return cost, nil
+		}
+		if 2*elapsed >= target {
+			return cost + 1, nil
+		}
+	}
+
+	return bcrypt.MaxCost, nil
+}
+
+// argon2idPrefix marks hashes encoded in the PHC string format:
+// $argon2id$v=19$m=65536,t=1,p=4$<salt>$<key>
+const argon2idPrefix = "$argon2id$"
+
+const (
+	Argon2idDefaultMemory      uint32 = 64 * 1024
+	Argon2idDefaultTime        uint32 = 1
+	Argon2idDefaultParallelism uint8  = 4
+	argon2idSaltLength                = 16
+	argon2idKeyLength                 = 32
+
+	// argon2idMaxMemory (1 GiB) caps what a stored hash may ask for, so a
+	// corrupted or planted row cannot exhaust memory on verification.
+	argon2idMaxMemory uint32 = 1024 * 1024
+)
+
+var ErrArgon2idParamsInvalid = errors.New("argon2id: memory, time and parallelism must not be zero")
+
+type Argon2idHasher struct {
+	memory      uint32
+	time        uint32
+	parallelism uint8
+}
+
+func NewArgon2idHasher(memory uint32, time uint32, parallelism uint8) (PasswordHasher, error) {
+	if memory == 0 || time == 0 || parallelism == 0 {
+		return nil, errors.WithStack(ErrArgon2idParamsInvalid)
+	}
+
+	return &Argon2idHasher{
+		memory:      memory,
+		time:        time,
+		parallelism: parallelism,
+	}, nil
+}
+
+func (h *Argon2idHasher) Hash(pass Password) (HashedPassword, error) {
+	salt := make([]byte, argon2idSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	key := argon2.IDKey([]byte(pass), salt, h.time, h.memory, h.parallelism, argon2idKeyLength)
+
+	encoded := fmt.Sprintf(
+		"%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2idPrefix,
+		argon2.Version,
+		h.memory,
+		h.time,
+		h.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	)
+
+	return HashedPassword(encoded), nil
+}
+
+func (h *Argon2idHasher) Verify(hashed HashedPassword, pass Password) error {
+	return hashed.Verify(pass)
+}
+
+func isArgon2idHash(hashed HashedPassword) bool {
+	return strings.HasPrefix(string(hashed), argon2idPrefix)
+}
+
+func verifyArgon2id(hashed HashedPassword, pass Password) error {
+	parts := strings.Split(string(hashed), "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return errors.WithStack(ErrHashedPasswordInvalid)
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return errors.Wrap(ErrHashedPasswordInvalid, err.Error())
+	}
+	if version != argon2.Version {
+		return errors.WithStack(ErrHashedPasswordInvalid)
+	}
+
+	var memory, time uint32
+	var parallelism uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &parallelism); err != nil {
+		return errors.Wrap(ErrHashedPasswordInvalid, err.Error())
+	}
+	// argon2 panics on t=0 or p=0 and needs at least 8 KiB per lane.
+	if time == 0 || parallelism == 0 || memory < 8*uint32(parallelism) || argon2idMaxMemory < memory {
+		return errors.WithStack(ErrHashedPasswordInvalid)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return errors.Wrap(ErrHashedPasswordInvalid, err.Error())
+	}
+	if len(salt) == 0 {
+		return errors.WithStack(ErrHashedPasswordInvalid)
+	}
+
+	// An empty key would compare equal to the empty key derived from any
+	// password, so only the length Hash writes is accepted.
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return errors.Wrap(ErrHashedPasswordInvalid, err.Error())
+	}
+	if len(key) != argon2idKeyLength {
+		return errors.WithStack(ErrHashedPasswordInvalid)
+	}
+
+	other := argon2.IDKey([]byte(pass), salt, time, memory, parallelism, argon2idKeyLength)
+	if subtle.ConstantTimeCompare(key, other) != 1 {
+		return errors.WithStack(ErrHashedPasswordNotMatch)
+	}
+
+	return nil
+}
+
+// legacySHA256Prefix marks salted sha256 hashes imported from the previous
+// system: $sha256$<hex salt>$<hex sha256(salt || password)>
+// They are only verified, never created, and are replaced with bcrypt on the
+// next successful login.
+const legacySHA256Prefix = "$sha256$"
+
+// NewLegacySHA256HashedPassword encodes a salt and digest exported from the
+// previous system, both hex encoded.
+func NewLegacySHA256HashedPassword(salt string, digest string) (HashedPassword, error) {
+	if _, err := hex.DecodeString(salt); err != nil || salt == "" {
+		return nil, errors.WithStack(ErrHashedPasswordInvalid)
+	}
+
+	if b, err := hex.DecodeString(digest); err != nil || len(b) != sha256.Size {
+		return nil, errors.WithStack(ErrHashedPasswordInvalid)
+	}
+
+	return HashedPassword(legacySHA256Prefix + strings.ToLower(salt) + "$" + strings.ToLower(digest)), nil
+}
+
+func isLegacySHA256Hash(hashed HashedPassword) bool {
+	return strings.HasPrefix(string(hashed), legacySHA256Prefix)
+}
+
+func verifyLegacySHA256(hashed HashedPassword, pass Password) error {
+	parts := strings.Split(string(hashed), "$")
+	if len(parts) != 4 || parts[1] != "sha256" {
+		return errors.WithStack(ErrHashedPasswordInvalid)
+	}
+
+	salt, err := hex.DecodeString(parts[2])
+	if err != nil {
+		return errors.Wrap(ErrHashedPasswordInvalid, err.Error())
+	}
+
+	digest, err := hex.DecodeString(parts[3])
+	if err != nil {
+		return errors.Wrap(ErrHashedPasswordInvalid, err.Error())
+	}
+
+	sum := sha256.Sum256(append(salt, []byte(pass)...))
+	if subtle.ConstantTimeCompare(sum[:], digest) != 1 {
+		return errors.WithStack(ErrHashedPasswordNotMatch)
+	}
+
+	return nil
+}
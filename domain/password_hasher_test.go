@@ -0,0 +1,286 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func legacySHA256Hash(t *testing.T, salt string, password string) HashedPassword {
+	t.Helper()
+
+	rawSalt, err := hex.DecodeString(salt)
+	if err != nil {
+		t.Fatalf("hex.DecodeString(%q) = %v", salt, err)
+	}
+	sum := sha256.Sum256(append(rawSalt, []byte(password)...))
+
+	hashed, err := NewLegacySHA256HashedPassword(salt, hex.EncodeToString(sum[:]))
+	if err != nil {
+		t.Fatalf("NewLegacySHA256HashedPassword() = %v", err)
+	}
+
+	return hashed
+}
+
+func TestLegacySHA256HashedPassword(t *testing.T) {
+	hashed := legacySHA256Hash(t, "a1b2c3d4", "Legacy#Pass9")
+
+	tests := []struct {
+		name     string
+		password Password
+		wantErr  error
+	}{
+		{name: "match", password: "Legacy#Pass9", wantErr: nil},
+		{name: "mismatch", password: "Legacy#Pass8", wantErr: ErrHashedPasswordNotMatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := hashed.Verify(tt.password); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if !hashed.IsLegacy() {
+		t.Fatal("IsLegacy() = false, want true")
+	}
+	if needsRehash, err := hashed.NeedsRehash(PasswordHashCost); err != nil || !needsRehash {
+		t.Fatalf("NeedsRehash() = %v, %v, want true", needsRehash, err)
+	}
+}
+
+func TestNewLegacySHA256HashedPasswordInvalid(t *testing.T) {
+	digest := hex.EncodeToString(make([]byte, sha256.Size))
+
+	tests := []struct {
+		name   string
+		salt   string
+		digest string
+	}{
+		{name: "empty salt", salt: "", digest: digest},
+		{name: "salt not hex", salt: "zz", digest: digest},
+		{name: "short digest", salt: "a1", digest: "abcd"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewLegacySHA256HashedPassword(tt.salt, tt.digest); !errors.Is(err, ErrHashedPasswordInvalid) {
+				t.Fatalf("NewLegacySHA256HashedPassword() = %v, want %v", err, ErrHashedPasswordInvalid)
+			}
+		})
+	}
+}
+
+func TestUpgradePasswordHashFromLegacy(t *testing.T) {
+	// The password predates today's policy, which would reject it.
+	const legacyPassword = "password"
+	if _, err := NewPassword(legacyPassword); err == nil {
+		t.Fatalf("NewPassword(%q) = nil, want the policy to reject it", legacyPassword)
+	}
+
+	user, err := NewUserFromSource(1, "alice", string(legacySHA256Hash(t, "0f1e", legacyPassword)), string(RoleUser), time.Now())
+	if err != nil {
+		t.Fatalf("NewUserFromSource() = %v", err)
+	}
+	changedAt := user.PasswordChangedAt()
+
+	password, err := NewLoginPassword(legacyPassword)
+	if err != nil {
+		t.Fatalf("NewLoginPassword() = %v", err)
+	}
+	if err := VerifyUserPassword(user, password); err != nil {
+		t.Fatalf("VerifyUserPassword() = %v", err)
+	}
+
+	upgraded, err := user.UpgradePasswordHash(password)
+	if err != nil || !upgraded {
+		t.Fatalf("UpgradePasswordHash() = %v, %v, want true", upgraded, err)
+	}
+
+	if user.HashedPassword().IsLegacy() {
+		t.Fatal("hash is still legacy after the upgrade")
+	}
+	if cost, err := bcrypt.Cost(user.HashedPassword()); err != nil || cost != PasswordHashCost {
+		t.Fatalf("bcrypt.Cost() = %d, %v, want %d", cost, err, PasswordHashCost)
+	}
+	if err := user.HashedPassword().Verify(password); err != nil {
+		t.Fatalf("Verify() after the upgrade = %v", err)
+	}
+	if !user.PasswordChangedAt().Equal(changedAt) {
+		t.Fatal("UpgradePasswordHash() changed passwordChangedAt")
+	}
+
+	if upgraded, err := user.UpgradePasswordHash(password); err != nil || upgraded {
+		t.Fatalf("second UpgradePasswordHash() = %v, %v, want false", upgraded, err)
+	}
+}
+
+func TestPasswordHasherRoundTrip(t *testing.T) {
+	bcryptHasher, err := NewBcryptHasher(bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("NewBcryptHasher() = %v", err)
+	}
+	argon2idHasher, err := NewArgon2idHasher(8*1024, 1, 1)
+	if err != nil {
+		t.Fatalf("NewArgon2idHasher() = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		hasher     PasswordHasher
+		wantPrefix string
+	}{
+		{name: "bcrypt", hasher: bcryptHasher, wantPrefix: "$2a$"},
+		{name: "argon2id", hasher: argon2idHasher, wantPrefix: "$argon2id$v=19$m=8192,t=1,p=1$"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hashed, err := tt.hasher.Hash("Xq7!kLmQ")
+			if err != nil {
+				t.Fatalf("Hash() = %v", err)
+			}
+			if !strings.HasPrefix(string(hashed), tt.wantPrefix) {
+				t.Fatalf("Hash() = %q, want prefix %q", hashed, tt.wantPrefix)
+			}
+
+			if err := tt.hasher.Verify(hashed, "Xq7!kLmQ"); err != nil {
+				t.Fatalf("Verify() = %v, want nil", err)
+			}
+			if err := tt.hasher.Verify(hashed, "Xq7!kLmR"); !errors.Is(err, ErrHashedPasswordNotMatch) {
+				t.Fatalf("Verify() = %v, want %v", err, ErrHashedPasswordNotMatch)
+			}
+			// Hashes are self-describing, so any hasher verifies them.
+			if err := hashed.Verify("Xq7!kLmQ"); err != nil {
+				t.Fatalf("HashedPassword.Verify() = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestNewArgon2idHasherInvalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		memory      uint32
+		time        uint32
+		parallelism uint8
+	}{
+		{name: "zero memory", memory: 0, time: 1, parallelism: 1},
+		{name: "zero time", memory: 1024, time: 0, parallelism: 1},
+		{name: "zero parallelism", memory: 1024, time: 1, parallelism: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewArgon2idHasher(tt.memory, tt.time, tt.parallelism); !errors.Is(err, ErrArgon2idParamsInvalid) {
+				t.Fatalf("NewArgon2idHasher() = %v, want %v", err, ErrArgon2idParamsInvalid)
+			}
+		})
+	}
+}
+
+func TestVerifyArgon2idStoredParams(t *testing.T) {
+	const password = "Xq7!kLmQ"
+
+	hasher, err := NewArgon2idHasher(8*1024, 1, 1)
+	if err != nil {
+		t.Fatalf("NewArgon2idHasher() = %v", err)
+	}
+	hashed, err := hasher.Hash(password)
+	if err != nil {
+		t.Fatalf("Hash() = %v", err)
+	}
+	parts := strings.Split(string(hashed), "$")
+	salt, key := parts[4], parts[5]
+	encode := func(params string, salt string, key string) HashedPassword {
+		return HashedPassword("$argon2id$v=19$" + params + "$" + salt + "$" + key)
+	}
+	shortKey := base64.RawStdEncoding.EncodeToString(make([]byte, argon2idKeyLength/2))
+
+	tests := []struct {
+		name    string
+		hashed  HashedPassword
+		wantErr error
+	}{
+		{name: "valid", hashed: encode("m=8192,t=1,p=1", salt, key), wantErr: nil},
+		{name: "empty key", hashed: encode("m=8192,t=1,p=1", salt, ""), wantErr: ErrHashedPasswordInvalid},
+		{name: "short key", hashed: encode("m=8192,t=1,p=1", salt, shortKey), wantErr: ErrHashedPasswordInvalid},
+		{name: "empty salt", hashed: encode("m=8192,t=1,p=1", "", key), wantErr: ErrHashedPasswordInvalid},
+		{name: "zero time", hashed: encode("m=8192,t=0,p=1", salt, key), wantErr: ErrHashedPasswordInvalid},
+		{name: "zero parallelism", hashed: encode("m=8192,t=1,p=0", salt, key), wantErr: ErrHashedPasswordInvalid},
+		{name: "zero memory", hashed: encode("m=0,t=1,p=1", salt, key), wantErr: ErrHashedPasswordInvalid},
+		{name: "memory below 8 KiB per lane", hashed: encode("m=16,t=1,p=4", salt, key), wantErr: ErrHashedPasswordInvalid},
+		{name: "memory above max", hashed: encode("m=4194304,t=1,p=1", salt, key), wantErr: ErrHashedPasswordInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.hashed.Verify(password); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify(%q) = %v, want %v", tt.hashed, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCalibrateBcryptCostInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		target time.Duration
+	}{
+		{name: "zero", target: 0},
+		{name: "negative", target: -time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := CalibrateBcryptCost(tt.target); !errors.Is(err, ErrBcryptCalibrationTargetInvalid) {
+				t.Fatalf("CalibrateBcryptCost(%v) = %v, want %v", tt.target, err, ErrBcryptCalibrationTargetInvalid)
+			}
+		})
+	}
+}
+
+func TestCalibrateBcryptCost(t *testing.T) {
+	// Timings vary between runs, so a longer target may come back one cost
+	// lower than a shorter one; anything beyond that is a real regression.
+	const tolerance = 1
+
+	tests := []struct {
+		name   string
+		target time.Duration
+	}{
+		{name: "1ns", target: time.Nanosecond},
+		{name: "1ms", target: time.Millisecond},
+		{name: "10ms", target: 10 * time.Millisecond},
+		{name: "50ms", target: 50 * time.Millisecond},
+	}
+
+	previous := bcrypt.MinCost
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CalibrateBcryptCost(tt.target)
+			if err != nil {
+				t.Fatalf("CalibrateBcryptCost(%v) = %v", tt.target, err)
+			}
+			if got < bcrypt.MinCost || bcrypt.MaxCost < got {
+				t.Fatalf("CalibrateBcryptCost(%v) = %d, want within [%d, %d]", tt.target, got, bcrypt.MinCost, bcrypt.MaxCost)
+			}
+			if got < previous-tolerance {
+				t.Fatalf("CalibrateBcryptCost(%v) = %d, want at least %d for a longer target", tt.target, got, previous-tolerance)
+			}
+			previous = got
+		})
+	}
+
+	if got, err := CalibrateBcryptCost(time.Nanosecond); err != nil || got != bcrypt.MinCost {
+		t.Fatalf("CalibrateBcryptCost(1ns) = %d, %v, want %d, nil", got, err, bcrypt.MinCost)
+	}
+}
@@ -0,0 +1,57 @@
+package domain
+
+import "github.com/pkg/errors"
+
+const PasswordHistoryDefaultSize = 5
+
+var (
+	ErrPasswordReused             = newPasswordError("reused", "must not match a recently used password")
+	ErrPasswordHistorySizeInvalid = errors.New("password history: size must be positive")
+)
+
+// PasswordHistory holds the hashes of the most recent passwords of a user,
+// newest first, up to size.
+type PasswordHistory struct {
+	size   int
+	hashes []HashedPassword
+}
+
+func (h PasswordHistory) Size() int                { return h.size }
+func (h PasswordHistory) Hashes() []HashedPassword { return h.hashes }
+
+func NewPasswordHistory(size int, hashes ...HashedPassword) (PasswordHistory, error) {
+	if size <= 0 {
+		return PasswordHistory{}, errors.WithStack(ErrPasswordHistorySizeInvalid)
+	}
+
+	if len(hashes) > size {
+		hashes = hashes[:size]
+	}
+
+	return PasswordHistory{size: size, hashes: hashes}, nil
+}
+
+// Contains runs one full hash comparison per stored hash, so it costs up to
+// size bcrypt verifications.
+func (h PasswordHistory) Contains(candidate Password) bool {
+	for _, hashed := range h.hashes {
+		if hashed.Verify(candidate) == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Push returns the history with hashed as the newest entry, dropping the
+// oldest one past size.
+func (h PasswordHistory) Push(hashed HashedPassword) PasswordHistory {
+	hashes := make([]HashedPassword, 0, h.size)
+	hashes = append(hashes, hashed)
+	hashes = append(hashes, h.hashes...)
+	if len(hashes) > h.size {
+		hashes = hashes[:h.size]
+	}
+
+	return PasswordHistory{size: h.size, hashes: hashes}
+}
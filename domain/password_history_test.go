@@ -0,0 +1,141 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func hashForTest(t *testing.T, password Password) HashedPassword {
+	t.Helper()
+
+	hashed, err := password.HashWithCost(bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("HashWithCost() = %v", err)
+	}
+
+	return hashed
+}
+
+func TestNewPasswordHistory(t *testing.T) {
+	hashes := []HashedPassword{hashForTest(t, "Aa1!aaa1"), hashForTest(t, "Bb2@bbb2"), hashForTest(t, "Cc3#ccc3")}
+
+	tests := []struct {
+		name       string
+		size       int
+		wantHashes int
+		wantErr    error
+	}{
+		{name: "fits", size: 5, wantHashes: 3, wantErr: nil},
+		{name: "truncated to size", size: 2, wantHashes: 2, wantErr: nil},
+		{name: "zero size", size: 0, wantErr: ErrPasswordHistorySizeInvalid},
+		{name: "negative size", size: -1, wantErr: ErrPasswordHistorySizeInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history, err := NewPasswordHistory(tt.size, hashes...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewPasswordHistory(%d) = %v, want %v", tt.size, err, tt.wantErr)
+			}
+			if err == nil && len(history.Hashes()) != tt.wantHashes {
+				t.Fatalf("NewPasswordHistory(%d) kept %d hashes, want %d", tt.size, len(history.Hashes()), tt.wantHashes)
+			}
+		})
+	}
+}
+
+func TestPasswordHistoryContains(t *testing.T) {
+	history, err := NewPasswordHistory(3, hashForTest(t, "Newest1!"), hashForTest(t, "Middle2@"), hashForTest(t, "Oldest3#"))
+	if err != nil {
+		t.Fatalf("NewPasswordHistory() = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		candidate Password
+		want      bool
+	}{
+		{name: "newest", candidate: "Newest1!", want: true},
+		{name: "oldest", candidate: "Oldest3#", want: true},
+		{name: "unused", candidate: "Unused4$", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := history.Contains(tt.candidate); got != tt.want {
+				t.Fatalf("Contains(%q) = %v, want %v", string(tt.candidate), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPasswordHistoryPush(t *testing.T) {
+	history, err := NewPasswordHistory(2, hashForTest(t, "Older1!x"))
+	if err != nil {
+		t.Fatalf("NewPasswordHistory() = %v", err)
+	}
+
+	pushed := history.Push(hashForTest(t, "Newer2@y")).Push(hashForTest(t, "Newest3#"))
+
+	tests := []struct {
+		name      string
+		candidate Password
+		want      bool
+	}{
+		{name: "newest pushed", candidate: "Newest3#", want: true},
+		{name: "previous pushed", candidate: "Newer2@y", want: true},
+		{name: "dropped past size", candidate: "Older1!x", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pushed.Contains(tt.candidate); got != tt.want {
+				t.Fatalf("Contains(%q) = %v, want %v", string(tt.candidate), got, tt.want)
+			}
+		})
+	}
+
+	if len(history.Hashes()) != 1 {
+		t.Fatalf("Push() changed the receiver to %d hashes, want 1", len(history.Hashes()))
+	}
+}
+
+func TestUserChangePasswordHistory(t *testing.T) {
+	const current Password = "Tx7!qLmZ"
+
+	tests := []struct {
+		name    string
+		next    Password
+		wantErr error
+	}{
+		{name: "older password", next: "Oldest3#", wantErr: ErrPasswordReused},
+		{name: "newer password", next: "Middle2@", wantErr: ErrPasswordReused},
+		{name: "unused password", next: "Unused4$", wantErr: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history, err := NewPasswordHistory(PasswordHistoryDefaultSize, hashForTest(t, "Middle2@"), hashForTest(t, "Oldest3#"))
+			if err != nil {
+				t.Fatalf("NewPasswordHistory() = %v", err)
+			}
+			user := newTestUserWithPassword(t, current, time.Now())
+			user.SetPasswordHistory(history)
+
+			if err := user.ChangePassword(current, tt.next); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ChangePassword(%q) = %v, want %v", string(tt.next), err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			// The replaced password joins the history.
+			if !user.PasswordHistory().Contains(current) {
+				t.Fatal("PasswordHistory().Contains(previous password) = false, want true")
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/unicode/norm"
+)
+
+type PasswordPolicy struct {
+	MinLength         int
+	MaxLength         int
+	AllowedCharacters *regexp.Regexp
+	MustIncludes      []*regexp.Regexp
+	Blacklist         PasswordBlacklist
+
+	// LowEntropyRunLength rejects runs of this many repeated, sequential or
+	// keyboard-adjacent characters. Zero disables the check. Like every rule
+	// here it applies to new passwords only; login uses NewLoginPassword.
+	LowEntropyRunLength int
+}
+
+var DefaultPasswordPolicy = PasswordPolicy{
+	MinLength:           PasswordMinLength,
+	MaxLength:           PasswordMaxLength,
+	AllowedCharacters:   PasswordCharacters,
+	MustIncludes:        PasswordMustIncludes,
+	Blacklist:           DefaultPasswordBlacklist,
+	LowEntropyRunLength: PasswordLowEntropyRunLength,
+}
+
+// UnicodePasswordPolicy is DefaultPasswordPolicy for deployments whose users
+// write passwords in other scripts: any letter, number, punctuation or symbol
+// is allowed, and the required classes are matched in Unicode terms.
+var UnicodePasswordPolicy = PasswordPolicy{
+	MinLength:         PasswordMinLength,
+	MaxLength:         PasswordMaxLength,
+	AllowedCharacters: regexp.MustCompile(`^[\p{L}\p{M}\p{N}\p{P}\p{S}]+$`),
+	MustIncludes: []*regexp.Regexp{
+		regexp.MustCompile(`\p{L}`),
+		regexp.MustCompile(`\p{N}`),
+		regexp.MustCompile(`[\p{P}\p{S}]`),
+	},
+	Blacklist:           DefaultPasswordBlacklist,
+	LowEntropyRunLength: PasswordLowEntropyRunLength,
+}
+
+const (
+	PasswordCharsetASCII   = "ascii"
+	PasswordCharsetUnicode = "unicode"
+)
+
+var ErrPasswordCharsetUnknown = errors.New("password policy: charset must be ascii or unicode")
+
+// PasswordPolicyForCharset picks the policy configured for a deployment. An
+// empty charset keeps the default.
+func PasswordPolicyForCharset(charset string) (PasswordPolicy, error) {
+	switch charset {
+	case "", PasswordCharsetASCII:
+		return DefaultPasswordPolicy, nil
+	case PasswordCharsetUnicode:
+		return UnicodePasswordPolicy, nil
+	}
+
+	return PasswordPolicy{}, errors.WithStack(ErrPasswordCharsetUnknown)
+}
+
+// Validate normalizes raw to NFC first, so the same password typed on
+// devices that compose accents differently hashes the same.
+func (p PasswordPolicy) Validate(raw string) (Password, error) {
+	raw = norm.NFC.String(raw)
+
+	if raw == "" {
+		return "", errors.WithStack(ErrPasswordEmpty)
+	}
+
+	if len([]rune(raw)) < p.MinLength {
+		return "", errors.WithStack(fmt.Errorf("%w (at least %d characters)", ErrPasswordTooShort, p.MinLength))
+	}
+
+	if p.MaxLength < len([]rune(raw)) {
+		return "", errors.WithStack(fmt.Errorf("%w (at most %d characters)", ErrPasswordTooLong, p.MaxLength))
+	}
+
+	if p.AllowedCharacters != nil && !p.AllowedCharacters.MatchString(raw) {
+		return "", errors.WithStack(ErrPasswordDoesNotFollowRule)
+	}
+	for _, expected := range p.MustIncludes {
+		if expected.FindString(raw) == "" {
+			return "", errors.WithStack(ErrPasswordDoesNotFollowRule)
+		}
+	}
+
+	if p.LowEntropyRunLength > 0 && hasLowEntropyRun(raw, p.LowEntropyRunLength) {
+		return "", errors.WithStack(ErrPasswordLowEntropy)
+	}
+
+	if p.Blacklist.Contains(raw) {
+		return "", errors.WithStack(ErrPasswordBlacklisted)
+	}
+
+	return Password(raw), nil
+}
+
+var keyboardRows = []string{
+	"1234567890",
+	"qwertyuiop",
+	"asdfghjkl",
+	"zxcvbnm",
+}
+
+func hasLowEntropyRun(v string, runLength int) bool {
+	runes := []rune(strings.ToLower(v))
+	if len(runes) < runLength {
+		return false
+	}
+
+	repeated, ascending, descending := 1, 1, 1
+	for i := 1; i < len(runes); i++ {
+		repeated = nextRun(repeated, runes[i] == runes[i-1])
+		ascending = nextRun(ascending, runes[i] == runes[i-1]+1)
+		descending = nextRun(descending, runes[i] == runes[i-1]-1)
+
+		if repeated >= runLength || ascending >= runLength || descending >= runLength {
+			return true
+		}
+	}
+
+	for i := 0; i+runLength <= len(runes); i++ {
+		window := string(runes[i : i+runLength])
+		reversed := reverseString(window)
+		for _, row := range keyboardRows {
+			if strings.Contains(row, window) || strings.Contains(row, reversed) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func nextRun(current int, continues bool) int {
+	if continues {
+		return current + 1
+	}
+
+	return 1
+}
+
+func reverseString(v string) string {
+	runes := []rune(v)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+
+	return string(runes)
+}
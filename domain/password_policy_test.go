@@ -0,0 +1,128 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPasswordPolicyLowEntropyRun(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		wantErr  error
+	}{
+		{name: "three repeats", password: "Xq!aaa7Zp", wantErr: nil},
+		{name: "four repeats", password: "Xq!aaaa7Zp", wantErr: ErrPasswordLowEntropy},
+		{name: "three ascending", password: "Xq!abc7Zp", wantErr: nil},
+		{name: "four ascending", password: "Xq!abcd7Zp", wantErr: ErrPasswordLowEntropy},
+		{name: "three descending", password: "Xq!983Zp", wantErr: nil},
+		{name: "four descending", password: "Xq!9876Zp", wantErr: ErrPasswordLowEntropy},
+		{name: "four keyboard adjacent", password: "Xq!asdf7Z", wantErr: ErrPasswordLowEntropy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPassword(tt.password)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("NewPassword(%q) = %v, want nil", tt.password, err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewPassword(%q) = %v, want %v", tt.password, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewLoginPasswordSkipsCreationPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+	}{
+		{name: "low entropy", password: "Xq!aaaa7Zp"},
+		{name: "blacklisted", password: "Password1!"},
+		{name: "no symbol", password: "abc12345"},
+		{name: "longer than max length", password: "Correct-Horse-Battery-Staple-9"},
+		{name: "non ascii", password: "Café1234!"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewLoginPassword(tt.password); err != nil {
+				t.Fatalf("NewLoginPassword(%q) = %v, want nil", tt.password, err)
+			}
+		})
+	}
+}
+
+func TestPasswordPolicyForCharset(t *testing.T) {
+	// "Café1234!" from the original request is rejected by both policies for
+	// its "1234" run, so the accented password avoids sequences.
+	const accented = "Café7x9Q!"
+
+	tests := []struct {
+		charset string
+		wantErr error
+	}{
+		{charset: "", wantErr: ErrPasswordDoesNotFollowRule},
+		{charset: PasswordCharsetASCII, wantErr: ErrPasswordDoesNotFollowRule},
+		{charset: PasswordCharsetUnicode, wantErr: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.charset, func(t *testing.T) {
+			policy, err := PasswordPolicyForCharset(tt.charset)
+			if err != nil {
+				t.Fatalf("PasswordPolicyForCharset(%q) = %v", tt.charset, err)
+			}
+
+			_, err = policy.Validate(accented)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("Validate(%q) = %v, want nil", accented, err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Validate(%q) = %v, want %v", accented, err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := PasswordPolicyForCharset("latin1"); !errors.Is(err, ErrPasswordCharsetUnknown) {
+		t.Fatalf("PasswordPolicyForCharset(latin1) = %v, want %v", err, ErrPasswordCharsetUnknown)
+	}
+}
+
+func TestNewUserForCreateUsesPolicy(t *testing.T) {
+	const accented = "Café7x9Q!"
+
+	if _, err := NewUserForCreate("alice", accented, string(RoleUser), DefaultPasswordPolicy); !errors.Is(err, ErrPasswordDoesNotFollowRule) {
+		t.Fatalf("NewUserForCreate() with the default policy = %v, want %v", err, ErrPasswordDoesNotFollowRule)
+	}
+
+	if _, err := NewUserForCreate("alice", accented, string(RoleUser), UnicodePasswordPolicy); err != nil {
+		t.Fatalf("NewUserForCreate() with the unicode policy = %v, want nil", err)
+	}
+}
+
+func TestPasswordPolicyMinLength(t *testing.T) {
+	adminPolicy := DefaultPasswordPolicy
+	adminPolicy.MinLength = 12
+
+	tests := []struct {
+		name     string
+		policy   PasswordPolicy
+		password string
+		wantErr  error
+	}{
+		{name: "default accepts 8 chars", policy: DefaultPasswordPolicy, password: "Xq7!kLmQ", wantErr: nil},
+		{name: "admin rejects 8 chars", policy: adminPolicy, password: "Xq7!kLmQ", wantErr: ErrPasswordTooShort},
+		{name: "admin accepts 12 chars", policy: adminPolicy, password: "Xq7!kLmQ2#vR", wantErr: nil},
+		{name: "default rejects 17 chars", policy: DefaultPasswordPolicy, password: "Xq7!kLmQ2#vRxT9$w", wantErr: ErrPasswordTooLong},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.policy.Validate(tt.password); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Validate(%q) = %v, want %v", tt.password, err, tt.wantErr)
+			}
+		})
+	}
+}
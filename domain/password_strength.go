@@ -0,0 +1,118 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	PasswordScoreMin = 0
+	PasswordScoreMax = 4
+
+	// passwordScoreContextMinLength ignores context strings so short they
+	// would match by chance.
+	passwordScoreContextMinLength = 3
+)
+
+// ScorePassword rates raw from PasswordScoreMin to PasswordScoreMax for a
+// strength meter. Length and character classes add points; runs, blacklisted
+// passwords and any of contexts (user name, email) appearing in raw take them
+// away. A password DefaultPasswordPolicy rejects never scores above 1, so the
+// meter cannot look good for something signup will refuse.
+func ScorePassword(raw string, contexts ...string) int {
+	raw = norm.NFC.String(raw)
+	if raw == "" {
+		return PasswordScoreMin
+	}
+
+	points := passwordLengthPoints(len([]rune(raw))) + passwordClassPoints(raw)
+
+	if hasLowEntropyRun(raw, PasswordLowEntropyRunLength) {
+		points -= 2
+	}
+	if containsPasswordContext(raw, contexts) {
+		points -= 2
+	}
+
+	score := passwordPointsToScore(points)
+
+	if DefaultPasswordPolicy.Blacklist.Contains(raw) {
+		return PasswordScoreMin
+	}
+	if _, err := DefaultPasswordPolicy.Validate(raw); err != nil && score > 1 {
+		return 1
+	}
+
+	return score
+}
+
+func passwordLengthPoints(length int) int {
+	points := 0
+	for _, threshold := range []int{PasswordMinLength, 10, 14} {
+		if length >= threshold {
+			points++
+		}
+	}
+
+	return points
+}
+
+// passwordClassPoints gives a point for each of lower, upper, digit and
+// other beyond the first one used.
+func passwordClassPoints(raw string) int {
+	var lower, upper, digit, other bool
+	for _, r := range raw {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			other = true
+		}
+	}
+
+	classes := 0
+	for _, used := range []bool{lower, upper, digit, other} {
+		if used {
+			classes++
+		}
+	}
+
+	return classes - 1
+}
+
+func containsPasswordContext(raw string, contexts []string) bool {
+	lowered := strings.ToLower(raw)
+	for _, context := range contexts {
+		candidates := []string{context}
+		if local, _, ok := strings.Cut(context, "@"); ok {
+			candidates = append(candidates, local)
+		}
+
+		for _, candidate := range candidates {
+			candidate = strings.ToLower(norm.NFC.String(candidate))
+			if len([]rune(candidate)) >= passwordScoreContextMinLength && strings.Contains(lowered, candidate) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func passwordPointsToScore(points int) int {
+	score := points - 1
+	if score < PasswordScoreMin {
+		return PasswordScoreMin
+	}
+	if score > PasswordScoreMax {
+		return PasswordScoreMax
+	}
+
+	return score
+}
@@ -0,0 +1,39 @@
+package domain
+
+import "testing"
+
+func TestScorePassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		contexts []string
+		want     int
+	}{
+		{name: "empty", raw: "", want: PasswordScoreMin},
+		{name: "repeats", raw: "aaaa1111", want: 0},
+		{name: "sequence", raw: "abcdefgh", want: 0},
+		{name: "blacklisted", raw: "password", want: 0},
+		{name: "sequence inside", raw: "Xq7!abcdLm", want: 1},
+		{name: "rejected by policy", raw: "Gk7wQ2pL", want: 1},
+		{name: "short with every class", raw: "Gk7$wQ2!", want: 3},
+		{name: "strong", raw: "Gk7$wQ2!pL", want: PasswordScoreMax},
+		{name: "long and strong", raw: "Gk7$wQ2!pLm3Vz", want: PasswordScoreMax},
+		{name: "contains context", raw: "Gk7$wQ2!pL", contexts: []string{"wq2!"}, want: 2},
+		{name: "context too short", raw: "Gk7$wQ2!pL", contexts: []string{"pL"}, want: PasswordScoreMax},
+		{name: "contains user name", raw: "xAlice#9wZq", contexts: []string{"alice"}, want: 2},
+		{name: "contains email local part", raw: "xAlice#9wZq", contexts: []string{"alice@example.com"}, want: 2},
+		{name: "unrelated context", raw: "xAlice#9wZq", contexts: []string{"bob", "bob@example.com"}, want: PasswordScoreMax},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ScorePassword(tt.raw, tt.contexts...)
+			if got != tt.want {
+				t.Fatalf("ScorePassword(%q, %q) = %d, want %d", tt.raw, tt.contexts, got, tt.want)
+			}
+			if again := ScorePassword(tt.raw, tt.contexts...); again != got {
+				t.Fatalf("ScorePassword(%q) = %d then %d, want the same score", tt.raw, got, again)
+			}
+		})
+	}
+}
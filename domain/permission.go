@@ -0,0 +1,61 @@
+package domain
+
+type Permission string
+
+const (
+	PermissionCreateInvest Permission = "invest:create"
+	PermissionReadInvest   Permission = "invest:read"
+	PermissionUpdateInvest Permission = "invest:update"
+	PermissionDeleteInvest Permission = "invest:delete"
+	PermissionCreateUser   Permission = "user:create"
+	PermissionReadUser     Permission = "user:read"
+	PermissionUpdateUser   Permission = "user:update"
+	PermissionDeleteUser   Permission = "user:delete"
+)
+
+var AllPermissions = []Permission{
+	PermissionCreateInvest,
+	PermissionReadInvest,
+	PermissionUpdateInvest,
+	PermissionDeleteInvest,
+	PermissionCreateUser,
+	PermissionReadUser,
+	PermissionUpdateUser,
+	PermissionDeleteUser,
+}
+
+// RoleAdmin is not listed because it implicitly holds every permission.
+var rolePermissions = map[UserRole][]Permission{
+	RoleUser: {
+		PermissionCreateInvest,
+		PermissionReadInvest,
+		PermissionUpdateInvest,
+		PermissionDeleteInvest,
+		PermissionReadUser,
+	},
+}
+
+func (r UserRole) Can(action Permission) bool {
+	if r == RoleAdmin {
+		return true
+	}
+
+	for _, permission := range rolePermissions[r] {
+		if permission == action {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r UserRole) Permissions() []Permission {
+	permissions := []Permission{}
+	for _, permission := range AllPermissions {
+		if r.Can(permission) {
+			permissions = append(permissions, permission)
+		}
+	}
+
+	return permissions
+}
@@ -0,0 +1,38 @@
+package domain
+
+import "testing"
+
+func TestUserRoleCan(t *testing.T) {
+	tests := []struct {
+		name   string
+		role   UserRole
+		action Permission
+		want   bool
+	}{
+		{name: "user cannot delete users", role: RoleUser, action: PermissionDeleteUser, want: false},
+		{name: "admin can delete users", role: RoleAdmin, action: PermissionDeleteUser, want: true},
+		{name: "user can create invests", role: RoleUser, action: PermissionCreateInvest, want: true},
+		{name: "admin holds unlisted permissions", role: RoleAdmin, action: Permission("report:export"), want: true},
+		{name: "unknown role holds nothing", role: UserRole("guest"), action: PermissionReadInvest, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.role.Can(tt.action); got != tt.want {
+				t.Fatalf("%s.Can(%s) = %v, want %v", tt.role, tt.action, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserRolePermissions(t *testing.T) {
+	if got := RoleAdmin.Permissions(); len(got) != len(AllPermissions) {
+		t.Fatalf("RoleAdmin.Permissions() = %v, want %v", got, AllPermissions)
+	}
+
+	for _, permission := range RoleUser.Permissions() {
+		if permission == PermissionDeleteUser {
+			t.Fatalf("RoleUser.Permissions() contains %s", PermissionDeleteUser)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package domain
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// PhoneNumber is stored in E.164 form, e.g. +14155552671.
+type PhoneNumber string
+
+const (
+	PhoneNumberMinDigits = 8
+	PhoneNumberMaxDigits = 15
+)
+
+var (
+	ErrPhoneNumberEmpty         = newFieldError("phone_number", "empty", "phone number: must not be empty")
+	ErrPhoneNumberInvalid       = newFieldError("phone_number", "invalid", "phone number: invalid format")
+	ErrPhoneNumberRegionInvalid = newFieldError("phone_number", "unknown_region", "phone number: unknown region")
+)
+
+var phoneCountryCodes = map[string]string{
+	"US": "1",
+	"CA": "1",
+	"GB": "44",
+	"DE": "49",
+	"FR": "33",
+	"IT": "39",
+	"ES": "34",
+	"NL": "31",
+	"CH": "41",
+	"SE": "46",
+	"AU": "61",
+	"NZ": "64",
+	"JP": "81",
+	"KR": "82",
+	"CN": "86",
+	"HK": "852",
+	"MO": "853",
+	"TW": "886",
+	"SG": "65",
+	"MY": "60",
+	"TH": "66",
+	"PH": "63",
+	"ID": "62",
+	"VN": "84",
+	"IN": "91",
+	"AE": "971",
+	"BR": "55",
+	"MX": "52",
+}
+
+// NewPhoneNumber accepts either an international number starting with + or
+// 00, or a national number which is prefixed with the calling code of
+// defaultRegion (ISO 3166-1 alpha-2) after dropping a leading trunk 0.
+func NewPhoneNumber(raw string, defaultRegion string) (PhoneNumber, error) {
+	v := strings.TrimSpace(raw)
+	if v == "" {
+		return "", errors.WithStack(ErrPhoneNumberEmpty)
+	}
+
+	international := false
+	if strings.HasPrefix(v, "+") {
+		international = true
+		v = v[1:]
+	}
+
+	var digits strings.Builder
+	for _, r := range v {
+		switch {
+		case '0' <= r && r <= '9':
+			digits.WriteRune(r)
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", errors.WithStack(ErrPhoneNumberInvalid)
+		}
+	}
+
+	number := digits.String()
+	if !international && strings.HasPrefix(number, "00") {
+		international = true
+		number = number[2:]
+	}
+
+	if !international {
+		countryCode, ok := phoneCountryCodes[strings.ToUpper(defaultRegion)]
+		if !ok {
+			return "", errors.WithStack(ErrPhoneNumberRegionInvalid)
+		}
+
+		if countryCode == "1" {
+			number = strings.TrimPrefix(number, "1")
+		} else {
+			number = strings.TrimPrefix(number, "0")
+		}
+		number = countryCode + number
+	}
+
+	if len(number) < PhoneNumberMinDigits || PhoneNumberMaxDigits < len(number) {
+		return "", errors.WithStack(ErrPhoneNumberInvalid)
+	}
+
+	if phoneCountryCodeOf(number) == "" {
+		return "", errors.WithStack(ErrPhoneNumberInvalid)
+	}
+
+	return PhoneNumber("+" + number), nil
+}
+
+func (p PhoneNumber) CountryCode() string {
+	return phoneCountryCodeOf(strings.TrimPrefix(string(p), "+"))
+}
+
+func (p PhoneNumber) National() string {
+	return strings.TrimPrefix(strings.TrimPrefix(string(p), "+"), p.CountryCode())
+}
+
+// phoneCountryCodeOf relies on calling codes being prefix-free.
+func phoneCountryCodeOf(number string) string {
+	for _, countryCode := range phoneCountryCodes {
+		if strings.HasPrefix(number, countryCode) {
+			return countryCode
+		}
+	}
+
+	return ""
+}
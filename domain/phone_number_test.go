@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewPhoneNumber(t *testing.T) {
+	tests := []struct {
+		name            string
+		raw             string
+		defaultRegion   string
+		want            PhoneNumber
+		wantCountryCode string
+		wantNational    string
+		wantErr         error
+	}{
+		{name: "formatted international", raw: "+1 (415) 555-2671", want: "+14155552671", wantCountryCode: "1", wantNational: "4155552671"},
+		{name: "00 prefix", raw: "0044 20 7946 0958", want: "+442079460958", wantCountryCode: "44", wantNational: "2079460958"},
+		{name: "national with trunk zero", raw: "090-1234-5678", defaultRegion: "jp", want: "+819012345678", wantCountryCode: "81", wantNational: "9012345678"},
+		{name: "nanp national", raw: "1 415.555.2671", defaultRegion: "US", want: "+14155552671", wantCountryCode: "1", wantNational: "4155552671"},
+		{name: "empty", raw: "  ", wantErr: ErrPhoneNumberEmpty},
+		{name: "letters", raw: "+1 415 CALL NOW", wantErr: ErrPhoneNumberInvalid},
+		{name: "too short", raw: "+1 415", wantErr: ErrPhoneNumberInvalid},
+		{name: "too long", raw: "+44 1234 5678 9012 34", wantErr: ErrPhoneNumberInvalid},
+		{name: "unknown country code", raw: "+999 1234 5678", wantErr: ErrPhoneNumberInvalid},
+		{name: "unknown region", raw: "415 555 2671", defaultRegion: "ZZ", wantErr: ErrPhoneNumberRegionInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewPhoneNumber(tt.raw, tt.defaultRegion)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewPhoneNumber(%q) = %v, want %v", tt.raw, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("NewPhoneNumber(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if got.CountryCode() != tt.wantCountryCode {
+				t.Fatalf("CountryCode() = %q, want %q", got.CountryCode(), tt.wantCountryCode)
+			}
+			if got.National() != tt.wantNational {
+				t.Fatalf("National() = %q, want %q", got.National(), tt.wantNational)
+			}
+		})
+	}
+}
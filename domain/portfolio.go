@@ -0,0 +1,138 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// AggregateByType sums the amounts of each investment type. All investments
+// of a type must share a currency.
+func AggregateByType(invests []*Invest) (map[InvestType]Amount, error) {
+	totals := make(map[InvestType]Amount)
+
+	for _, invest := range invests {
+		total, ok := totals[invest.Type()]
+		if !ok {
+			total = NewZeroAmount(invest.Currency())
+		}
+
+		sum, err := total.Add(invest.Amount())
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		totals[invest.Type()] = sum
+	}
+
+	return totals, nil
+}
+
+var (
+	ErrEmptyPortfolio        = errors.New("portfolio: nothing invested")
+	ErrPortfolioUserMismatch = errors.New("portfolio: invest belongs to another user")
+)
+
+type Portfolio struct {
+	userID  UserID
+	invests []*Invest
+}
+
+func (p Portfolio) UserID() UserID     { return p.userID }
+func (p Portfolio) Invests() []*Invest { return p.invests }
+
+func NewPortfolio(userID UserID, invests []*Invest) (Portfolio, error) {
+	for _, invest := range invests {
+		if invest.UserID() != userID {
+			return Portfolio{}, errors.WithStack(ErrPortfolioUserMismatch)
+		}
+	}
+
+	return Portfolio{userID: userID, invests: invests}, nil
+}
+
+// TotalInvested sums every investment, so all of them must share one
+// currency.
+func (p Portfolio) TotalInvested() (Amount, error) {
+	if len(p.invests) == 0 {
+		return Amount{}, errors.WithStack(ErrEmptyPortfolio)
+	}
+
+	total := NewZeroAmount(p.invests[0].Currency())
+	for _, invest := range p.invests {
+		sum, err := total.Add(invest.Amount())
+		if err != nil {
+			return Amount{}, errors.WithStack(err)
+		}
+		total = sum
+	}
+
+	return total, nil
+}
+
+// ROI returns (currentValue - totalInvested) / totalInvested. Only the final
+// ratio leaves fixed-point arithmetic.
+func (p Portfolio) ROI(currentValue Amount) (float64, error) {
+	totalInvested, err := p.TotalInvested()
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	if totalInvested.MinorUnits() == 0 {
+		return 0, errors.WithStack(ErrEmptyPortfolio)
+	}
+
+	gain, err := currentValue.Sub(totalInvested)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	return float64(gain.MinorUnits()) / float64(totalInvested.MinorUnits()), nil
+}
+
+// PortfolioSummary is the account overview. For no investments every field
+// is zero and ByType is an empty map.
+type PortfolioSummary struct {
+	TotalInvested   Amount
+	ByType          map[InvestType]Amount
+	Count           int
+	FirstInvestedAt time.Time
+	LastInvestedAt  time.Time
+}
+
+// SummarizePortfolio needs every investment in one currency and returns
+// ErrCurrencyMismatch otherwise.
+func SummarizePortfolio(invests []*Invest) (PortfolioSummary, error) {
+	summary := PortfolioSummary{ByType: map[InvestType]Amount{}}
+	if len(invests) == 0 {
+		return summary, nil
+	}
+
+	total := NewZeroAmount(invests[0].Currency())
+	for _, invest := range invests {
+		sum, err := total.Add(invest.Amount())
+		if err != nil {
+			return PortfolioSummary{}, errors.WithStack(err)
+		}
+		total = sum
+
+		investedAt := time.Time(invest.InvestedAt())
+		if summary.FirstInvestedAt.IsZero() || investedAt.Before(summary.FirstInvestedAt) {
+			summary.FirstInvestedAt = investedAt
+		}
+		if investedAt.After(summary.LastInvestedAt) {
+			summary.LastInvestedAt = investedAt
+		}
+	}
+
+	byType, err := AggregateByType(invests)
+	if err != nil {
+		return PortfolioSummary{}, errors.WithStack(err)
+	}
+
+	summary.TotalInvested = total
+	summary.ByType = byType
+	summary.Count = len(invests)
+
+	return summary, nil
+}
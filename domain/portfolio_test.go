@@ -0,0 +1,236 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func newTestInvest(t *testing.T, userID uint64, amount string, currency string, investType string) *Invest {
+	t.Helper()
+
+	invest, err := NewInvestFromSource(1, userID, amount, currency, investType, time.Now().Add(-time.Hour), 1)
+	if err != nil {
+		t.Fatalf("NewInvestFromSource() = %v", err)
+	}
+
+	return invest
+}
+
+func TestAggregateByType(t *testing.T) {
+	tests := []struct {
+		name    string
+		invests []*Invest
+		want    map[InvestType]string
+		wantErr error
+	}{
+		{
+			name:    "empty",
+			invests: nil,
+			want:    map[InvestType]string{},
+			wantErr: nil,
+		},
+		{
+			name: "mixed types",
+			invests: []*Invest{
+				newTestInvest(t, 1, "100.10", "USD", "stock"),
+				newTestInvest(t, 1, "50.05", "USD", "bond"),
+				newTestInvest(t, 1, "0.20", "USD", "stock"),
+			},
+			want:    map[InvestType]string{InvestTypeStock: "100.30", InvestTypeBond: "50.05"},
+			wantErr: nil,
+		},
+		{
+			name: "currencies differ between types",
+			invests: []*Invest{
+				newTestInvest(t, 1, "100", "USD", "stock"),
+				newTestInvest(t, 1, "5000", "JPY", "bond"),
+			},
+			want:    map[InvestType]string{InvestTypeStock: "100.00", InvestTypeBond: "5000"},
+			wantErr: nil,
+		},
+		{
+			name: "currency mismatch within a type",
+			invests: []*Invest{
+				newTestInvest(t, 1, "100", "USD", "stock"),
+				newTestInvest(t, 1, "5000", "JPY", "stock"),
+			},
+			want:    nil,
+			wantErr: ErrCurrencyMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AggregateByType(tt.invests)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("AggregateByType() = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if got == nil {
+				t.Fatal("AggregateByType() = nil, want an empty map")
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("AggregateByType() = %v, want %v", got, tt.want)
+			}
+			for investType, want := range tt.want {
+				if got[investType].String() != want {
+					t.Fatalf("AggregateByType()[%s] = %s, want %s", investType, got[investType], want)
+				}
+			}
+		})
+	}
+}
+
+func TestPortfolioROI(t *testing.T) {
+	invested, err := NewPortfolio(1, []*Invest{
+		newTestInvest(t, 1, "600.00", "USD", "stock"),
+		newTestInvest(t, 1, "400.00", "USD", "bond"),
+	})
+	if err != nil {
+		t.Fatalf("NewPortfolio() = %v", err)
+	}
+	empty, err := NewPortfolio(1, nil)
+	if err != nil {
+		t.Fatalf("NewPortfolio() = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		portfolio    Portfolio
+		currentValue Amount
+		want         float64
+		wantErr      error
+	}{
+		{name: "gain", portfolio: invested, currentValue: NewAmountFromMinorUnits(1250_00, CurrencyUSD), want: 0.25, wantErr: nil},
+		{name: "loss", portfolio: invested, currentValue: NewAmountFromMinorUnits(900_00, CurrencyUSD), want: -0.1, wantErr: nil},
+		{name: "break even", portfolio: invested, currentValue: NewAmountFromMinorUnits(1000_00, CurrencyUSD), want: 0, wantErr: nil},
+		{name: "empty portfolio", portfolio: empty, currentValue: NewAmountFromMinorUnits(100, CurrencyUSD), want: 0, wantErr: ErrEmptyPortfolio},
+		{name: "other currency", portfolio: invested, currentValue: NewAmountFromMinorUnits(1000, CurrencyJPY), want: 0, wantErr: ErrCurrencyMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.portfolio.ROI(tt.currentValue)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ROI() = %v, want %v", err, tt.wantErr)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Fatalf("ROI() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewPortfolioUserMismatch(t *testing.T) {
+	invests := []*Invest{newTestInvest(t, 1, "1.00", "USD", "stock"), newTestInvest(t, 2, "1.00", "USD", "stock")}
+
+	if _, err := NewPortfolio(1, invests); !errors.Is(err, ErrPortfolioUserMismatch) {
+		t.Fatalf("NewPortfolio() = %v, want %v", err, ErrPortfolioUserMismatch)
+	}
+}
+
+func TestSummarizePortfolio(t *testing.T) {
+	at := func(day int) time.Time {
+		return time.Date(2023, time.March, day, 9, 30, 0, 0, time.UTC)
+	}
+	invest := func(amount string, currency string, investType string, investedAt time.Time) *Invest {
+		t.Helper()
+
+		invest, err := NewInvestFromSource(1, 1, amount, currency, investType, investedAt, 1)
+		if err != nil {
+			t.Fatalf("NewInvestFromSource() = %v", err)
+		}
+
+		return invest
+	}
+
+	tests := []struct {
+		name      string
+		invests   []*Invest
+		wantTotal string
+		wantBy    map[InvestType]string
+		wantCount int
+		wantFirst time.Time
+		wantLast  time.Time
+		wantErr   error
+	}{
+		{
+			name:      "empty",
+			invests:   nil,
+			wantTotal: "0",
+			wantBy:    map[InvestType]string{},
+			wantErr:   nil,
+		},
+		{
+			name: "multiple types",
+			invests: []*Invest{
+				invest("100.10", "USD", "stock", at(10)),
+				invest("50.05", "USD", "bond", at(2)),
+				invest("0.20", "USD", "stock", at(20)),
+				invest("25", "USD", "crypto", at(15)),
+			},
+			wantTotal: "175.35",
+			wantBy:    map[InvestType]string{InvestTypeStock: "100.30", InvestTypeBond: "50.05", InvestTypeCrypto: "25.00"},
+			wantCount: 4,
+			wantFirst: at(2),
+			wantLast:  at(20),
+			wantErr:   nil,
+		},
+		{
+			name:      "single investment",
+			invests:   []*Invest{invest("5000", "JPY", "etf", at(1))},
+			wantTotal: "5000",
+			wantBy:    map[InvestType]string{InvestTypeETF: "5000"},
+			wantCount: 1,
+			wantFirst: at(1),
+			wantLast:  at(1),
+			wantErr:   nil,
+		},
+		{
+			name: "mixed currencies",
+			invests: []*Invest{
+				invest("100", "USD", "stock", at(1)),
+				invest("5000", "JPY", "bond", at(2)),
+			},
+			wantErr: ErrCurrencyMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SummarizePortfolio(tt.invests)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SummarizePortfolio() = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if got.TotalInvested.String() != tt.wantTotal {
+				t.Fatalf("TotalInvested = %s, want %s", got.TotalInvested, tt.wantTotal)
+			}
+			if got.ByType == nil {
+				t.Fatal("ByType = nil, want an empty map")
+			}
+			if len(got.ByType) != len(tt.wantBy) {
+				t.Fatalf("ByType = %v, want %v", got.ByType, tt.wantBy)
+			}
+			for investType, want := range tt.wantBy {
+				if got.ByType[investType].String() != want {
+					t.Fatalf("ByType[%s] = %s, want %s", investType, got.ByType[investType], want)
+				}
+			}
+			if got.Count != tt.wantCount {
+				t.Fatalf("Count = %d, want %d", got.Count, tt.wantCount)
+			}
+			if !got.FirstInvestedAt.Equal(tt.wantFirst) || !got.LastInvestedAt.Equal(tt.wantLast) {
+				t.Fatalf("FirstInvestedAt, LastInvestedAt = %v, %v, want %v, %v", got.FirstInvestedAt, got.LastInvestedAt, tt.wantFirst, tt.wantLast)
+			}
+		})
+	}
+}
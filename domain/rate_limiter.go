@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var ErrRateLimiterInvalid = errors.New("rate limiter: rate and burst must be positive")
+
+type RateLimiter interface {
+	Allow(ip ClientIp) bool
+}
+
+type tokenBucket struct {
+	tokens   float64
+	refilled time.Time
+}
+
+// TokenBucketRateLimiter gives each ClientIp a bucket of burst tokens that
+// refills at rate tokens per second. Buckets that have refilled completely
+// are dropped, as they behave the same as a new one.
+type TokenBucketRateLimiter struct {
+	mu      sync.Mutex
+	rate    float64
+	burst   float64
+	clock   Clock
+	buckets map[ClientIp]*tokenBucket
+	swept   time.Time
+}
+
+func NewTokenBucketRateLimiter(rate float64, burst int, clock Clock) (*TokenBucketRateLimiter, error) {
+	if rate <= 0 || burst <= 0 {
+		return nil, errors.WithStack(ErrRateLimiterInvalid)
+	}
+
+	return &TokenBucketRateLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		clock:   clock,
+		buckets: map[ClientIp]*tokenBucket{},
+		swept:   clock.Now(),
+	}, nil
+}
+
+func (l *TokenBucketRateLimiter) Allow(ip ClientIp) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.clock.Now()
+	l.sweep(now)
+
+	bucket, ok := l.buckets[ip]
+	if !ok {
+		bucket = &tokenBucket{tokens: l.burst, refilled: now}
+		l.buckets[ip] = bucket
+	}
+
+	bucket.tokens = l.refill(bucket, now)
+	bucket.refilled = now
+
+	if bucket.tokens < 1 {
+		return false
+	}
+
+	bucket.tokens--
+	return true
+}
+
+func (l *TokenBucketRateLimiter) refill(bucket *tokenBucket, now time.Time) float64 {
+	tokens := bucket.tokens + now.Sub(bucket.refilled).Seconds()*l.rate
+	if tokens > l.burst {
+		return l.burst
+	}
+
+	return tokens
+}
+
+// sweep runs at most once per time needed to refill an empty bucket.
+func (l *TokenBucketRateLimiter) sweep(now time.Time) {
+	fullRefill := time.Duration(l.burst / l.rate * float64(time.Second))
+	if now.Sub(l.swept) < fullRefill {
+		return
+	}
+
+	for ip, bucket := range l.buckets {
+		if l.refill(bucket, now) >= l.burst {
+			delete(l.buckets, ip)
+		}
+	}
+	l.swept = now
+}
@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewTokenBucketRateLimiter(t *testing.T) {
+	tests := []struct {
+		name    string
+		rate    float64
+		burst   int
+		wantErr error
+	}{
+		{name: "valid", rate: 1, burst: 5, wantErr: nil},
+		{name: "zero rate", rate: 0, burst: 5, wantErr: ErrRateLimiterInvalid},
+		{name: "negative rate", rate: -1, burst: 5, wantErr: ErrRateLimiterInvalid},
+		{name: "zero burst", rate: 1, burst: 0, wantErr: ErrRateLimiterInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewTokenBucketRateLimiter(tt.rate, tt.burst, newFakeClock()); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewTokenBucketRateLimiter(%v, %d) = %v, want %v", tt.rate, tt.burst, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTokenBucketRateLimiterAllow(t *testing.T) {
+	const (
+		burst = 3
+		ip    = ClientIp("203.0.113.42")
+		other = ClientIp("198.51.100.7")
+	)
+
+	// Each step runs against the same limiter, after advancing the clock.
+	steps := []struct {
+		name    string
+		advance time.Duration
+		ip      ClientIp
+		want    []bool
+	}{
+		{name: "burst then denied", advance: 0, ip: ip, want: []bool{true, true, true, false}},
+		{name: "other ip has its own bucket", advance: 0, ip: other, want: []bool{true}},
+		{name: "half a token is not enough", advance: 500 * time.Millisecond, ip: ip, want: []bool{false}},
+		{name: "one token refilled", advance: 500 * time.Millisecond, ip: ip, want: []bool{true, false}},
+		{name: "refill is capped at burst", advance: time.Hour, ip: ip, want: []bool{true, true, true, false}},
+	}
+
+	clock := newFakeClock()
+	limiter, err := NewTokenBucketRateLimiter(1, burst, clock)
+	if err != nil {
+		t.Fatalf("NewTokenBucketRateLimiter() = %v", err)
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			clock.Advance(step.advance)
+
+			for i, want := range step.want {
+				if got := limiter.Allow(step.ip); got != want {
+					t.Fatalf("Allow() #%d = %v, want %v", i+1, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestTokenBucketRateLimiterSweep(t *testing.T) {
+	clock := newFakeClock()
+	limiter, err := NewTokenBucketRateLimiter(1, 2, clock)
+	if err != nil {
+		t.Fatalf("NewTokenBucketRateLimiter() = %v", err)
+	}
+
+	limiter.Allow("203.0.113.1")
+	limiter.Allow("203.0.113.2")
+	clock.Advance(2 * time.Second)
+	limiter.Allow("203.0.113.3")
+
+	if got := len(limiter.buckets); got != 1 {
+		t.Fatalf("buckets after a full refill = %d, want 1", got)
+	}
+}
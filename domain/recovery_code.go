@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/hex"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	recoveryCodeBytes     = 10
+	RecoveryCodeMaxAmount = 20
+)
+
+var ErrRecoveryCodeAmountInvalid = errors.New("recovery code: amount must be between 1 and 20")
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// RecoveryCode is shown to the user once, e.g. "abcde-fghij-klmno-pqrst".
+type RecoveryCode string
+
+// HashedRecoveryCode is what gets stored. A used code is replaced with the
+// empty hash, which no input matches.
+type HashedRecoveryCode string
+
+func GenerateRecoveryCodes(n int) ([]RecoveryCode, []HashedRecoveryCode, error) {
+	if n <= 0 || RecoveryCodeMaxAmount < n {
+		return nil, nil, errors.WithStack(ErrRecoveryCodeAmountInvalid)
+	}
+
+	codes := make([]RecoveryCode, 0, n)
+	hashes := make([]HashedRecoveryCode, 0, n)
+	for i := 0; i < n; i++ {
+		b := make([]byte, recoveryCodeBytes)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, errors.WithStack(err)
+		}
+
+		encoded := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))
+		code := RecoveryCode(encoded[0:4] + "-" + encoded[4:8] + "-" + encoded[8:12] + "-" + encoded[12:16])
+
+		codes = append(codes, code)
+		hashes = append(hashes, code.Hash())
+	}
+
+	return codes, hashes, nil
+}
+
+func (c RecoveryCode) Hash() HashedRecoveryCode {
+	sum := sha256.Sum256([]byte(normalizeRecoveryCode(string(c))))
+
+	return HashedRecoveryCode(hex.EncodeToString(sum[:]))
+}
+
+// VerifyRecoveryCode compares input against every unused hash without
+// stopping early, and consumes the matching one by clearing it in hashes.
+// The caller must persist hashes afterwards.
+func VerifyRecoveryCode(hashes []HashedRecoveryCode, input string) (index int, ok bool) {
+	inputHash := []byte(RecoveryCode(input).Hash())
+
+	index = -1
+	for i, hash := range hashes {
+		match := subtle.ConstantTimeCompare([]byte(hash), inputHash)
+		if match == 1 && index < 0 {
+			index = i
+		}
+	}
+
+	if index < 0 {
+		return -1, false
+	}
+
+	hashes[index] = ""
+
+	return index, true
+}
+
+// normalizeRecoveryCode ignores case, separators and surrounding spaces so
+// users can type the code however it was written down.
+func normalizeRecoveryCode(v string) string {
+	v = strings.ToLower(strings.TrimSpace(v))
+
+	return strings.NewReplacer("-", "", " ", "").Replace(v)
+}
@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	tests := []struct {
+		name    string
+		n       int
+		wantErr error
+	}{
+		{name: "one", n: 1, wantErr: nil},
+		{name: "max", n: RecoveryCodeMaxAmount, wantErr: nil},
+		{name: "zero", n: 0, wantErr: ErrRecoveryCodeAmountInvalid},
+		{name: "negative", n: -1, wantErr: ErrRecoveryCodeAmountInvalid},
+		{name: "above max", n: RecoveryCodeMaxAmount + 1, wantErr: ErrRecoveryCodeAmountInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			codes, hashes, err := GenerateRecoveryCodes(tt.n)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GenerateRecoveryCodes(%d) = %v, want %v", tt.n, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if len(codes) != tt.n || len(hashes) != tt.n {
+				t.Fatalf("GenerateRecoveryCodes(%d) returned %d codes and %d hashes", tt.n, len(codes), len(hashes))
+			}
+
+			seen := make(map[RecoveryCode]bool, tt.n)
+			for i, code := range codes {
+				if seen[code] {
+					t.Fatalf("GenerateRecoveryCodes() returned %q twice", code)
+				}
+				seen[code] = true
+
+				if hashes[i] != code.Hash() {
+					t.Fatalf("hashes[%d] = %q, want %q", i, hashes[i], code.Hash())
+				}
+				if strings.Contains(string(hashes[i]), string(code)) {
+					t.Fatalf("hashes[%d] contains the plaintext code", i)
+				}
+			}
+		})
+	}
+}
+
+func TestVerifyRecoveryCode(t *testing.T) {
+	codes, hashes, err := GenerateRecoveryCodes(3)
+	if err != nil {
+		t.Fatalf("GenerateRecoveryCodes() = %v", err)
+	}
+
+	// The steps share hashes, so a code consumed by one is gone for the next.
+	tests := []struct {
+		name      string
+		input     string
+		wantIndex int
+		wantOK    bool
+	}{
+		{name: "second code", input: string(codes[1]), wantIndex: 1, wantOK: true},
+		{name: "reuse", input: string(codes[1]), wantIndex: -1, wantOK: false},
+		{name: "upper case without separators", input: strings.ToUpper(strings.ReplaceAll(string(codes[0]), "-", "")), wantIndex: 0, wantOK: true},
+		{name: "reuse in another form", input: " " + string(codes[0]) + " ", wantIndex: -1, wantOK: false},
+		{name: "unknown code", input: "aaaa-bbbb-cccc-dddd", wantIndex: -1, wantOK: false},
+		{name: "empty", input: "", wantIndex: -1, wantOK: false},
+		{name: "last code", input: string(codes[2]), wantIndex: 2, wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index, ok := VerifyRecoveryCode(hashes, tt.input)
+			if index != tt.wantIndex || ok != tt.wantOK {
+				t.Fatalf("VerifyRecoveryCode(%q) = (%d, %v), want (%d, %v)", tt.input, index, ok, tt.wantIndex, tt.wantOK)
+			}
+		})
+	}
+
+	for i, hash := range hashes {
+		if hash != "" {
+			t.Fatalf("hashes[%d] = %q after every code was used, want empty", i, hash)
+		}
+	}
+}
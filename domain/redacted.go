@@ -0,0 +1,19 @@
+package domain
+
+// RedactedMarker replaces secrets wherever they would be formatted or
+// encoded, e.g. by fmt or a structured logger.
+const RedactedMarker = "[REDACTED]"
+
+// RedactedString holds a secret that is not one of the domain types below,
+// such as an API token read from config. Use string(v) to get the value.
+type RedactedString string
+
+func (RedactedString) String() string               { return RedactedMarker }
+func (RedactedString) GoString() string             { return RedactedMarker }
+func (RedactedString) MarshalText() ([]byte, error) { return []byte(RedactedMarker), nil }
+func (Password) String() string                     { return RedactedMarker }
+func (Password) GoString() string                   { return RedactedMarker }
+func (Password) MarshalText() ([]byte, error)       { return []byte(RedactedMarker), nil }
+func (HashedPassword) String() string               { return RedactedMarker }
+func (HashedPassword) GoString() string             { return RedactedMarker }
+func (HashedPassword) MarshalText() ([]byte, error) { return []byte(RedactedMarker), nil }
@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRedactedFormatting(t *testing.T) {
+	const secret = "Tx7!qLmZ"
+
+	hashed, err := Password(secret).Hash()
+	if err != nil {
+		t.Fatalf("Hash() = %v", err)
+	}
+
+	values := []struct {
+		name   string
+		value  interface{}
+		secret string
+	}{
+		{name: "Password", value: Password(secret), secret: secret},
+		{name: "HashedPassword", value: hashed, secret: string(hashed)},
+		{name: "RedactedString", value: RedactedString(secret), secret: secret},
+	}
+	verbs := []string{"%v", "%s", "%+v", "%#v", "%q", "%x"}
+
+	for _, v := range values {
+		for _, verb := range verbs {
+			t.Run(v.name+" "+verb, func(t *testing.T) {
+				got := fmt.Sprintf(verb, v.value)
+				if strings.Contains(got, v.secret) {
+					t.Fatalf("Sprintf(%q) = %q, leaks the secret", verb, got)
+				}
+				want := RedactedMarker
+				if verb == "%x" {
+					want = fmt.Sprintf("%x", RedactedMarker)
+				}
+				if !strings.Contains(got, want) {
+					t.Fatalf("Sprintf(%q) = %q, want %q", verb, got, want)
+				}
+			})
+		}
+
+		t.Run(v.name+" json", func(t *testing.T) {
+			data, err := json.Marshal(map[string]interface{}{"secret": v.value})
+			if err != nil {
+				t.Fatalf("json.Marshal() = %v", err)
+			}
+			if want := `{"secret":"` + RedactedMarker + `"}`; string(data) != want {
+				t.Fatalf("json.Marshal() = %s, want %s", data, want)
+			}
+		})
+	}
+}
+
+func TestRedactedVerify(t *testing.T) {
+	hashed, err := Password("Tx7!qLmZ").Hash()
+	if err != nil {
+		t.Fatalf("Hash() = %v", err)
+	}
+
+	if err := hashed.Verify("Tx7!qLmZ"); err != nil {
+		t.Fatalf("Verify() = %v, want nil", err)
+	}
+}
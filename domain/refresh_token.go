@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+
+	"github.com/pkg/errors"
+)
+
+const refreshTokenBytes = 32
+
+// RefreshTokenHashLength is the length of the hex encoded sha256 that Hash
+// returns, whatever the length of the raw token.
+const RefreshTokenHashLength = sha256.Size * 2
+
+var (
+	ErrRefreshTokenEmpty       = newFieldError("refresh_token", "empty", "refresh token: must not be empty")
+	ErrRefreshTokenHashEmpty   = newFieldError("refresh_token", "empty", "refresh token hash: must not be empty")
+	ErrRefreshTokenHashInvalid = newFieldError("refresh_token", "invalid", "refresh token hash: must be a hex encoded sha256")
+)
+
+// RefreshToken is the raw value handed to the client. Only its hash is
+// persisted, so a database leak does not expose usable tokens.
+type RefreshToken string
+
+func GenerateRefreshToken() (RefreshToken, error) {
+	b := make([]byte, refreshTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	return RefreshToken(base64.RawURLEncoding.EncodeToString(b)), nil
+}
+
+func NewRefreshToken(v string) (RefreshToken, error) {
+	if v == "" {
+		return "", errors.WithStack(ErrRefreshTokenEmpty)
+	}
+
+	return RefreshToken(v), nil
+}
+
+func (t RefreshToken) Hash() string {
+	sum := sha256.Sum256([]byte(t))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyAgainst compares hashes rather than raw tokens, so both sides always
+// have RefreshTokenHashLength bytes and subtle.ConstantTimeCompare runs in
+// the same time whether or not they match. Never compare raw tokens directly.
+func (t RefreshToken) VerifyAgainst(storedHash string) bool {
+	return subtle.ConstantTimeCompare([]byte(t.Hash()), []byte(storedHash)) == 1
+}
+
+type RefreshTokenHash string
+
+func NewRefreshTokenHash(v string) (RefreshTokenHash, error) {
+	if v == "" {
+		return "", errors.WithStack(ErrRefreshTokenHashEmpty)
+	}
+
+	if len(v) != RefreshTokenHashLength {
+		return "", errors.WithStack(ErrRefreshTokenHashInvalid)
+	}
+
+	return RefreshTokenHash(v), nil
+}
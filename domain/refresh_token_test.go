@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRefreshTokenHash(t *testing.T) {
+	token, err := GenerateRefreshToken()
+	if err != nil {
+		t.Fatalf("GenerateRefreshToken() = %v", err)
+	}
+	other, err := GenerateRefreshToken()
+	if err != nil {
+		t.Fatalf("GenerateRefreshToken() = %v", err)
+	}
+	if token == other {
+		t.Fatal("GenerateRefreshToken() returned the same token twice")
+	}
+
+	hashed := token.Hash()
+	if hashed == string(token) || strings.Contains(hashed, string(token)) {
+		t.Fatalf("Hash() = %q, want it not to contain the raw token", hashed)
+	}
+	if _, err := NewRefreshTokenHash(hashed); err != nil {
+		t.Fatalf("NewRefreshTokenHash(Hash()) = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		token      RefreshToken
+		storedHash string
+		want       bool
+	}{
+		{name: "match", token: token, storedHash: hashed, want: true},
+		{name: "other token", token: other, storedHash: hashed, want: false},
+		{name: "raw token stored", token: token, storedHash: string(token), want: false},
+		{name: "empty hash", token: token, storedHash: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.token.VerifyAgainst(tt.storedHash); got != tt.want {
+				t.Fatalf("VerifyAgainst() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRefreshTokenHash(t *testing.T) {
+	tests := []struct {
+		name    string
+		v       string
+		wantErr error
+	}{
+		{name: "valid", v: RefreshToken("token").Hash(), wantErr: nil},
+		{name: "empty", v: "", wantErr: ErrRefreshTokenHashEmpty},
+		{name: "raw token", v: "token", wantErr: ErrRefreshTokenHashInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewRefreshTokenHash(tt.v); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewRefreshTokenHash(%q) = %v, want %v", tt.v, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRefreshTokenVerifyAgainstFixedLength(t *testing.T) {
+	token := RefreshToken("token")
+	hashed := token.Hash()
+
+	lastFlipped := []byte(hashed)
+	if lastFlipped[len(lastFlipped)-1] == '0' {
+		lastFlipped[len(lastFlipped)-1] = '1'
+	} else {
+		lastFlipped[len(lastFlipped)-1] = '0'
+	}
+
+	tests := []struct {
+		name       string
+		token      RefreshToken
+		storedHash string
+		want       bool
+	}{
+		{name: "equal", token: token, storedHash: hashed, want: true},
+		{name: "long raw token equal", token: RefreshToken(strings.Repeat("a", 1024)), storedHash: RefreshToken(strings.Repeat("a", 1024)).Hash(), want: true},
+		{name: "last byte differs", token: token, storedHash: string(lastFlipped), want: false},
+		{name: "truncated", token: token, storedHash: hashed[:RefreshTokenHashLength-1], want: false},
+		{name: "upper case", token: token, storedHash: strings.ToUpper(hashed), want: false},
+		{name: "longer", token: token, storedHash: hashed + "0", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := len(tt.token.Hash()); got != RefreshTokenHashLength {
+				t.Fatalf("len(Hash()) = %d, want %d", got, RefreshTokenHashLength)
+			}
+			if got := tt.token.VerifyAgainst(tt.storedHash); got != tt.want {
+				t.Fatalf("VerifyAgainst() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
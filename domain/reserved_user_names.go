@@ -0,0 +1,31 @@
+package domain
+
+var DefaultReservedUserNames = NewReservedUserNames([]string{
+	"admin",
+	"administrator",
+	"api",
+	"help",
+	"info",
+	"invest",
+	"root",
+	"security",
+	"support",
+	"system",
+})
+
+type ReservedUserNames map[string]struct{}
+
+func NewReservedUserNames(names []string) ReservedUserNames {
+	reserved := make(ReservedUserNames, len(names))
+	for _, name := range names {
+		reserved[canonicalUserName(name)] = struct{}{}
+	}
+
+	return reserved
+}
+
+func (r ReservedUserNames) Contains(v string) bool {
+	_, ok := r[canonicalUserName(v)]
+
+	return ok
+}
@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const resetTokenBytes = 32
+
+var (
+	ErrResetTokenExpired    = errors.New("reset token: expired")
+	ErrResetTokenInvalid    = errors.New("reset token: invalid")
+	ErrResetTokenTTLInvalid = errors.New("reset token: ttl must be positive")
+)
+
+// GenerateResetToken returns the raw token to email and the hashed form to
+// store. The raw token is "<user id>.<random>" so the stored token can be
+// looked up from the link alone, and the hash carries the expiry,
+// "<unix seconds>$<sha256 hex>", so VerifyResetToken needs nothing else.
+func GenerateResetToken(userID UserID, ttl time.Duration) (raw string, hashed string, expiresAt time.Time, err error) {
+	if ttl <= 0 {
+		return "", "", time.Time{}, errors.WithStack(ErrResetTokenTTLInvalid)
+	}
+
+	b := make([]byte, resetTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", time.Time{}, errors.WithStack(err)
+	}
+
+	raw = userID.String() + "." + base64.RawURLEncoding.EncodeToString(b)
+	expiresAt = time.Now().Add(ttl).Truncate(time.Second)
+
+	return raw, hashResetToken(raw, expiresAt), expiresAt, nil
+}
+
+func ResetTokenUserID(raw string) (UserID, error) {
+	id, _, ok := strings.Cut(raw, ".")
+	if !ok {
+		return 0, errors.WithStack(ErrResetTokenInvalid)
+	}
+
+	userID, err := NewUserIDFromString(id)
+	if err != nil {
+		return 0, errors.Wrap(ErrResetTokenInvalid, err.Error())
+	}
+
+	return userID, nil
+}
+
+func VerifyResetToken(hashed, raw string, now time.Time) error {
+	expiry, _, ok := strings.Cut(hashed, "$")
+	if !ok {
+		return errors.WithStack(ErrResetTokenInvalid)
+	}
+
+	unix, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil {
+		return errors.Wrap(ErrResetTokenInvalid, err.Error())
+	}
+	expiresAt := time.Unix(unix, 0)
+
+	if subtle.ConstantTimeCompare([]byte(hashResetToken(raw, expiresAt)), []byte(hashed)) != 1 {
+		return errors.WithStack(ErrResetTokenInvalid)
+	}
+
+	if !now.Before(expiresAt) {
+		return errors.WithStack(ErrResetTokenExpired)
+	}
+
+	return nil
+}
+
+func hashResetToken(raw string, expiresAt time.Time) string {
+	sum := sha256.Sum256([]byte(raw))
+
+	return strconv.FormatInt(expiresAt.Unix(), 10) + "$" + hex.EncodeToString(sum[:])
+}
+
+// ResetToken is the stored side of a password reset. Consume verifies the
+// raw token and marks it used, so the same link cannot reset twice.
+type ResetToken struct {
+	userID UserID
+	hashed string
+	used   bool
+}
+
+func (t *ResetToken) UserID() UserID { return t.userID }
+func (t *ResetToken) Hashed() string { return t.hashed }
+func (t *ResetToken) IsUsed() bool   { return t.used }
+
+func NewResetTokenFromSource(userID uint64, hashed string, used bool) (*ResetToken, error) {
+	newUserID, err := NewUserID(userID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if hashed == "" {
+		return nil, errors.WithStack(ErrResetTokenInvalid)
+	}
+
+	return &ResetToken{userID: newUserID, hashed: hashed, used: used}, nil
+}
+
+func (t *ResetToken) Consume(raw string, now time.Time) error {
+	if t.used {
+		return errors.WithStack(ErrResetTokenInvalid)
+	}
+
+	if err := VerifyResetToken(t.hashed, raw, now); err != nil {
+		return errors.WithStack(err)
+	}
+
+	t.used = true
+
+	return nil
+}
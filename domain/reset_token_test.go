@@ -0,0 +1,136 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerifyResetToken(t *testing.T) {
+	raw, hashed, expiresAt, err := GenerateResetToken(42, time.Hour)
+	if err != nil {
+		t.Fatalf("GenerateResetToken() = %v", err)
+	}
+	other, _, _, err := GenerateResetToken(42, time.Hour)
+	if err != nil {
+		t.Fatalf("GenerateResetToken() = %v", err)
+	}
+
+	_, digest, _ := strings.Cut(hashed, "$")
+
+	tests := []struct {
+		name    string
+		hashed  string
+		raw     string
+		now     time.Time
+		wantErr error
+	}{
+		{name: "valid", hashed: hashed, raw: raw, now: expiresAt.Add(-time.Second), wantErr: nil},
+		{name: "expired", hashed: hashed, raw: raw, now: expiresAt, wantErr: ErrResetTokenExpired},
+		{name: "mismatched", hashed: hashed, raw: other, now: expiresAt.Add(-time.Second), wantErr: ErrResetTokenInvalid},
+		{name: "no expiry", hashed: digest, raw: raw, now: expiresAt.Add(-time.Second), wantErr: ErrResetTokenInvalid},
+		{name: "non numeric expiry", hashed: "soon$" + digest, raw: raw, now: expiresAt.Add(-time.Second), wantErr: ErrResetTokenInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := VerifyResetToken(tt.hashed, tt.raw, tt.now); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("VerifyResetToken() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateResetToken(t *testing.T) {
+	tests := []struct {
+		name    string
+		ttl     time.Duration
+		wantErr error
+	}{
+		{name: "positive", ttl: time.Minute, wantErr: nil},
+		{name: "zero", ttl: 0, wantErr: ErrResetTokenTTLInvalid},
+		{name: "negative", ttl: -time.Minute, wantErr: ErrResetTokenTTLInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, hashed, _, err := GenerateResetToken(7, tt.ttl)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GenerateResetToken() = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if strings.Contains(hashed, raw) {
+				t.Fatal("GenerateResetToken() hashed contains the raw token")
+			}
+			userID, err := ResetTokenUserID(raw)
+			if err != nil || userID != 7 {
+				t.Fatalf("ResetTokenUserID() = (%d, %v), want (7, nil)", userID, err)
+			}
+		})
+	}
+}
+
+func TestResetTokenUserID(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    UserID
+		wantErr error
+	}{
+		{name: "valid", raw: "12.abc", want: 12, wantErr: nil},
+		{name: "no separator", raw: "12abc", want: 0, wantErr: ErrResetTokenInvalid},
+		{name: "non numeric id", raw: "x.abc", want: 0, wantErr: ErrResetTokenInvalid},
+		{name: "zero id", raw: "0.abc", want: 0, wantErr: ErrResetTokenInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResetTokenUserID(tt.raw)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ResetTokenUserID(%q) = %v, want %v", tt.raw, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("ResetTokenUserID(%q) = %d, want %d", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResetTokenConsume(t *testing.T) {
+	raw, hashed, expiresAt, err := GenerateResetToken(42, time.Hour)
+	if err != nil {
+		t.Fatalf("GenerateResetToken() = %v", err)
+	}
+	token, err := NewResetTokenFromSource(42, hashed, false)
+	if err != nil {
+		t.Fatalf("NewResetTokenFromSource() = %v", err)
+	}
+	now := expiresAt.Add(-time.Minute)
+
+	// The steps share token, so the first consume uses it up.
+	tests := []struct {
+		name    string
+		raw     string
+		wantErr error
+	}{
+		{name: "wrong token", raw: raw + "x", wantErr: ErrResetTokenInvalid},
+		{name: "first use", raw: raw, wantErr: nil},
+		{name: "reuse", raw: raw, wantErr: ErrResetTokenInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := token.Consume(tt.raw, now); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Consume() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if !token.IsUsed() {
+		t.Fatal("IsUsed() = false after a successful consume, want true")
+	}
+}
@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var ErrRevokedToken = errors.New("token has been revoked")
+
+// RevocationStore is a denylist of token IDs (Payload.ID). An entry only has
+// to outlive the token itself, after which expiry rejects it anyway.
+type RevocationStore interface {
+	Revoke(jti string, until time.Time)
+	IsRevoked(jti string) bool
+}
+
+// InMemoryRevocationStore forgets entries once until has passed. It is per
+// process, so every instance behind a load balancer needs its own copy of
+// each revocation.
+type InMemoryRevocationStore struct {
+	mu      sync.Mutex
+	clock   Clock
+	revoked map[string]time.Time
+}
+
+func NewInMemoryRevocationStore(clock Clock) *InMemoryRevocationStore {
+	return &InMemoryRevocationStore{
+		clock:   clock,
+		revoked: map[string]time.Time{},
+	}
+}
+
+func (s *InMemoryRevocationStore) Revoke(jti string, until time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	for id, u := range s.revoked {
+		if !now.Before(u) {
+			delete(s.revoked, id)
+		}
+	}
+
+	if current, ok := s.revoked[jti]; !ok || until.After(current) {
+		s.revoked[jti] = until
+	}
+}
+
+func (s *InMemoryRevocationStore) IsRevoked(jti string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	until, ok := s.revoked[jti]
+	if !ok {
+		return false
+	}
+
+	if !s.clock.Now().Before(until) {
+		delete(s.revoked, jti)
+		return false
+	}
+
+	return true
+}
+
+// RevocableTokenMaker wraps a TokenMaker so VerifyToken also rejects tokens
+// in the RevocationStore.
+type RevocableTokenMaker struct {
+	TokenMaker
+	store RevocationStore
+}
+
+func NewRevocableTokenMaker(maker TokenMaker, store RevocationStore) *RevocableTokenMaker {
+	return &RevocableTokenMaker{TokenMaker: maker, store: store}
+}
+
+func (maker *RevocableTokenMaker) VerifyToken(token Token) (*Payload, error) {
+	payload, err := maker.TokenMaker.VerifyToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if maker.store.IsRevoked(payload.ID.String()) {
+		return nil, errors.WithStack(ErrRevokedToken)
+	}
+
+	return payload, nil
+}
+
+// Revoke denies the token until it would have expired on its own.
+func (maker *RevocableTokenMaker) Revoke(payload *Payload) {
+	maker.store.Revoke(payload.ID.String(), time.Time(payload.ExpiresAt))
+}
@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestUser(t *testing.T, role UserRole) *User {
+	t.Helper()
+
+	user, err := NewUserFromSource(1, "alice", string(DummyHash), string(role), time.Now())
+	if err != nil {
+		t.Fatalf("NewUserFromSource() = %v", err)
+	}
+
+	return user
+}
+
+func TestRevocableTokenMaker(t *testing.T) {
+	jwtMaker, err := NewJWTMaker(SymmetricKey("12345678901234567890123456789012"))
+	if err != nil {
+		t.Fatalf("NewJWTMaker() = %v", err)
+	}
+
+	clock := &fakeClock{now: time.Now()}
+	maker := NewRevocableTokenMaker(jwtMaker, NewInMemoryRevocationStore(clock))
+
+	token, payload, err := maker.CreateToken(newTestUser(t, RoleUser), time.Hour)
+	if err != nil {
+		t.Fatalf("CreateToken() = %v", err)
+	}
+	other, _, err := maker.CreateToken(newTestUser(t, RoleUser), time.Hour)
+	if err != nil {
+		t.Fatalf("CreateToken() = %v", err)
+	}
+
+	if _, err := maker.VerifyToken(token); err != nil {
+		t.Fatalf("VerifyToken() before revocation = %v", err)
+	}
+
+	maker.Revoke(payload)
+
+	if _, err := maker.VerifyToken(token); !errors.Is(err, ErrRevokedToken) {
+		t.Fatalf("VerifyToken() after revocation = %v, want %v", err, ErrRevokedToken)
+	}
+	if _, err := maker.VerifyToken(other); err != nil {
+		t.Fatalf("VerifyToken() of another token = %v, want nil", err)
+	}
+
+	clock.Advance(59 * time.Minute)
+	if _, err := maker.VerifyToken(token); !errors.Is(err, ErrRevokedToken) {
+		t.Fatalf("VerifyToken() just before expiry = %v, want %v", err, ErrRevokedToken)
+	}
+}
+
+func TestInMemoryRevocationStore(t *testing.T) {
+	clock := newFakeClock()
+	store := NewInMemoryRevocationStore(clock)
+
+	store.Revoke("a", clock.Now().Add(time.Minute))
+	store.Revoke("b", clock.Now().Add(time.Hour))
+
+	tests := []struct {
+		name    string
+		advance time.Duration
+		jti     string
+		want    bool
+	}{
+		{name: "revoked", jti: "a", want: true},
+		{name: "never revoked", jti: "c", want: false},
+		{name: "at until", advance: time.Minute, jti: "a", want: false},
+		{name: "longer entry kept", jti: "b", want: true},
+		{name: "longer entry expired", advance: time.Hour, jti: "b", want: false},
+	}
+
+	for _, tt := range tests {
+		clock.Advance(tt.advance)
+		if got := store.IsRevoked(tt.jti); got != tt.want {
+			t.Fatalf("%s: IsRevoked(%q) = %v, want %v", tt.name, tt.jti, got, tt.want)
+		}
+	}
+}
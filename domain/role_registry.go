@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+var ErrUserRoleEmpty = newFieldError("role", "empty", "user role: must not be empty")
+
+var DefaultRoleRegistry = NewRoleRegistry(RoleUser, RoleAdmin)
+
+// RoleRegistry holds the roles accepted by NewUserRole. Roles registered at
+// runtime are not part of roleHierarchy, so AtLeast is false for them.
+type RoleRegistry struct {
+	mu    sync.RWMutex
+	roles map[UserRole]struct{}
+}
+
+func NewRoleRegistry(roles ...UserRole) *RoleRegistry {
+	registry := &RoleRegistry{roles: make(map[UserRole]struct{}, len(roles))}
+	for _, role := range roles {
+		registry.roles[role] = struct{}{}
+	}
+
+	return registry
+}
+
+func (r *RoleRegistry) Register(name string) error {
+	if name == "" {
+		return errors.WithStack(ErrUserRoleEmpty)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.roles[UserRole(name)] = struct{}{}
+
+	return nil
+}
+
+func (r *RoleRegistry) Validate(v string) (UserRole, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.roles[UserRole(v)]; !ok {
+		return UserRole(""), errors.WithStack(ErrUserRoleInvalid)
+	}
+
+	return UserRole(v), nil
+}
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestUserRoleAtLeast(t *testing.T) {
+	const roleModerator UserRole = "moderator"
+
+	original := roleHierarchy
+	roleHierarchy = []UserRole{RoleUser, roleModerator, RoleAdmin}
+	t.Cleanup(func() { roleHierarchy = original })
+
+	tests := []struct {
+		role UserRole
+		min  UserRole
+		want bool
+	}{
+		{role: RoleUser, min: RoleUser, want: true},
+		{role: RoleUser, min: roleModerator, want: false},
+		{role: RoleUser, min: RoleAdmin, want: false},
+		{role: roleModerator, min: RoleUser, want: true},
+		{role: roleModerator, min: roleModerator, want: true},
+		{role: roleModerator, min: RoleAdmin, want: false},
+		{role: RoleAdmin, min: RoleUser, want: true},
+		{role: RoleAdmin, min: roleModerator, want: true},
+		{role: RoleAdmin, min: RoleAdmin, want: true},
+		{role: UserRole("guest"), min: RoleUser, want: false},
+		{role: RoleAdmin, min: UserRole("guest"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.role)+">="+string(tt.min), func(t *testing.T) {
+			if got := tt.role.AtLeast(tt.min); got != tt.want {
+				t.Fatalf("%s.AtLeast(%s) = %v, want %v", tt.role, tt.min, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoleRegistry(t *testing.T) {
+	registry := NewRoleRegistry(RoleUser, RoleAdmin)
+
+	if err := registry.Register("auditor"); err != nil {
+		t.Fatalf("Register() = %v", err)
+	}
+	if err := registry.Register(""); !errors.Is(err, ErrUserRoleEmpty) {
+		t.Fatalf("Register(\"\") = %v, want %v", err, ErrUserRoleEmpty)
+	}
+
+	tests := []struct {
+		name    string
+		role    string
+		wantErr error
+	}{
+		{name: "seeded user", role: "user", wantErr: nil},
+		{name: "seeded admin", role: "admin", wantErr: nil},
+		{name: "registered", role: "auditor", wantErr: nil},
+		{name: "unregistered", role: "moderator", wantErr: ErrUserRoleInvalid},
+		{name: "empty", role: "", wantErr: ErrUserRoleInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := registry.Validate(tt.role)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Validate(%q) = %v, want %v", tt.role, err, tt.wantErr)
+			}
+			if err == nil && got != UserRole(tt.role) {
+				t.Fatalf("Validate(%q) = %q", tt.role, got)
+			}
+		})
+	}
+
+	if _, err := NewUserRole("auditor"); !errors.Is(err, ErrUserRoleInvalid) {
+		t.Fatalf("NewUserRole() = %v, want the default registry to be unaffected", err)
+	}
+}
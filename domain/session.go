@@ -4,65 +4,235 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/pkg/errors"
 )
 
 type Session struct {
-	uuid         SessionUUID
-	userID       UserID
-	refreshToken Token
-	userAgent    UserAgent
-	clientIp     ClientIp
-	isBlocked    IsBlocked
-	expiresAt    ExpiresAt
-}
-
-func (s *Session) UUID() SessionUUID    { return s.uuid }
-func (s *Session) UserID() UserID       { return s.userID }
-func (s *Session) RefreshToken() Token  { return s.refreshToken }
-func (s *Session) UserAgent() UserAgent { return s.userAgent }
-func (s *Session) ClientIp() ClientIp   { return s.clientIp }
-func (s *Session) IsBlocked() IsBlocked { return s.isBlocked }
-func (s *Session) ExpiresAt() ExpiresAt { return s.expiresAt }
+	id               SessionID
+	userID           UserID
+	refreshTokenHash RefreshTokenHash
+	userAgent        UserAgent
+	clientIp         ClientIp
+	isBlocked        IsBlocked
+	isRotated        IsRotated
+	expiresAt        ExpiresAt
+	createdAt        CreatedAt
+	lastSeenAt       LastSeenAt
+}
+
+func (s *Session) ID() SessionID                      { return s.id }
+func (s *Session) UserID() UserID                     { return s.userID }
+func (s *Session) RefreshTokenHash() RefreshTokenHash { return s.refreshTokenHash }
+func (s *Session) UserAgent() UserAgent               { return s.userAgent }
+func (s *Session) ClientIp() ClientIp                 { return s.clientIp }
+func (s *Session) IsBlocked() IsBlocked               { return s.isBlocked }
+func (s *Session) IsRotated() IsRotated               { return s.isRotated }
+func (s *Session) ExpiresAt() ExpiresAt               { return s.expiresAt }
+func (s *Session) CreatedAt() CreatedAt               { return s.createdAt }
+func (s *Session) LastSeenAt() LastSeenAt             { return s.lastSeenAt }
+
+func (s *Session) IsExpired(now time.Time) bool {
+	return !now.Before(time.Time(s.expiresAt))
+}
+
+func (s *Session) TimeUntilExpiry(now time.Time) time.Duration {
+	if s.IsExpired(now) {
+		return 0
+	}
+
+	return time.Time(s.expiresAt).Sub(now)
+}
+
+var ErrSessionExpiresAtPast = newFieldError("expires_at", "past", "session: expires at must be in the future")
 
 func NewSession(
-	sessionUUID SessionUUID,
+	sessionID SessionID,
 	userID UserID,
-	refreshToken Token,
+	refreshToken RefreshToken,
 	expiresAt ExpiresAt,
 	userMetaData *UserMetaData,
 ) (*Session, error) {
+	return NewSessionWithClock(sessionID, userID, refreshToken, expiresAt, userMetaData, SystemClock{})
+}
+
+// NewSessionWithClock takes created at and last seen at from clock, and
+// checks expiresAt against it.
+func NewSessionWithClock(
+	sessionID SessionID,
+	userID UserID,
+	refreshToken RefreshToken,
+	expiresAt ExpiresAt,
+	userMetaData *UserMetaData,
+	clock Clock,
+) (*Session, error) {
+	if userID == 0 {
+		return nil, errors.WithStack(ErrUserIDZero)
+	}
+
+	if refreshToken == "" {
+		return nil, errors.WithStack(ErrRefreshTokenEmpty)
+	}
+
+	now := clock.Now()
+	if !time.Time(expiresAt).After(now) {
+		return nil, errors.WithStack(ErrSessionExpiresAtPast)
+	}
+
 	isBlocked, _ := NewIsBlocked(false)
+	isRotated, _ := NewIsRotated(false)
+	createdAt, _ := NewCreatedAt(now)
+	lastSeenAt, _ := NewLastSeenAt(now)
 	return &Session{
-		uuid:         sessionUUID,
-		userID:       userID,
-		refreshToken: refreshToken,
-		userAgent:    userMetaData.UserAgent(),
-		clientIp:     userMetaData.ClientIp(),
-		isBlocked:    isBlocked,
-		expiresAt:    expiresAt,
+		id:               sessionID,
+		userID:           userID,
+		refreshTokenHash: RefreshTokenHash(refreshToken.Hash()),
+		userAgent:        userMetaData.UserAgent(),
+		clientIp:         userMetaData.ClientIp(),
+		isBlocked:        isBlocked,
+		isRotated:        isRotated,
+		expiresAt:        expiresAt,
+		createdAt:        createdAt,
+		lastSeenAt:       lastSeenAt,
 	}, nil
 }
 
-type SessionUUID uuid.UUID
+func NewSessionFromSource(
+	sessionID string,
+	userID uint64,
+	refreshTokenHash string,
+	userAgent string,
+	clientIp string,
+	isBlocked bool,
+	isRotated bool,
+	expiresAt time.Time,
+	createdAt time.Time,
+	lastSeenAt time.Time,
+) (*Session, error) {
+	newID, err := NewSessionIDFromString(sessionID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	newUserID, err := NewUserID(userID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	newRefreshTokenHash, err := NewRefreshTokenHash(refreshTokenHash)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	newUserAgent, err := NewUserAgent(userAgent)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	newClientIp, err := NewClientIp(clientIp)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	newIsBlocked, err := NewIsBlocked(isBlocked)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	newIsRotated, err := NewIsRotated(isRotated)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	newExpiresAt, err := NewExpiresAt(expiresAt)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
 
-func NewSessionUUID(v uuid.UUID) (SessionUUID, error) {
-	return SessionUUID(v), nil
+	newCreatedAt, err := NewCreatedAt(createdAt)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	newLastSeenAt, err := NewLastSeenAt(lastSeenAt)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &Session{
+		id:               newID,
+		userID:           newUserID,
+		refreshTokenHash: newRefreshTokenHash,
+		userAgent:        newUserAgent,
+		clientIp:         newClientIp,
+		isBlocked:        newIsBlocked,
+		isRotated:        newIsRotated,
+		expiresAt:        newExpiresAt,
+		createdAt:        newCreatedAt,
+		lastSeenAt:       newLastSeenAt,
+	}, nil
 }
 
-func (sessionUUID SessionUUID) ToString() string {
-	return uuid.UUID(sessionUUID).String()
+type SessionID uuid.UUID
+
+func NewSessionID() (SessionID, error) {
+	v, err := uuid.NewRandom()
+	if err != nil {
+		return SessionID{}, errors.WithStack(err)
+	}
+
+	return SessionID(v), nil
 }
 
-type UserAgent string
+var ErrSessionIDInvalid = newFieldError("session_id", "invalid", "session id: must be a version 4 uuid")
 
-func NewUserAgent(v string) (UserAgent, error) {
-	return UserAgent(v), nil
+func NewSessionIDFromString(v string) (SessionID, error) {
+	parsed, err := uuid.Parse(v)
+	if err != nil {
+		return SessionID{}, errors.Wrap(ErrSessionIDInvalid, err.Error())
+	}
+
+	return newSessionIDFromParsed(parsed)
+}
+
+func NewSessionIDFromBytes(v []byte) (SessionID, error) {
+	parsed, err := uuid.FromBytes(v)
+	if err != nil {
+		return SessionID{}, errors.Wrap(ErrSessionIDInvalid, err.Error())
+	}
+
+	return newSessionIDFromParsed(parsed)
 }
 
-type ClientIp string
+func newSessionIDFromParsed(v uuid.UUID) (SessionID, error) {
+	if v.Version() != 4 || v.Variant() != uuid.RFC4122 {
+		return SessionID{}, errors.WithStack(ErrSessionIDInvalid)
+	}
+
+	return SessionID(v), nil
+}
+
+func (id SessionID) String() string {
+	return uuid.UUID(id).String()
+}
 
-func NewClientIp(v string) (ClientIp, error) {
-	return ClientIp(v), nil
+func (id SessionID) MarshalText() ([]byte, error) {
+	return uuid.UUID(id).MarshalText()
+}
+
+func (id *SessionID) UnmarshalText(data []byte) error {
+	return (*uuid.UUID)(id).UnmarshalText(data)
+}
+
+func (id SessionID) Bytes() []byte {
+	b := uuid.UUID(id)
+
+	return b[:]
+}
+
+type UserAgent string
+
+func NewUserAgent(v string) (UserAgent, error) {
+	return UserAgent(v), nil
 }
 
 type IsBlocked bool
@@ -71,8 +241,37 @@ func NewIsBlocked(v bool) (IsBlocked, error) {
 	return IsBlocked(v), nil
 }
 
+type IsRotated bool
+
+func NewIsRotated(v bool) (IsRotated, error) {
+	return IsRotated(v), nil
+}
+
 type ExpiresAt time.Time
 
 func NewExpiresAt(v time.Time) (ExpiresAt, error) {
 	return ExpiresAt(v), nil
 }
+
+func (expiresAt ExpiresAt) MarshalJSON() ([]byte, error) {
+	return time.Time(expiresAt).MarshalJSON()
+}
+
+func (expiresAt *ExpiresAt) UnmarshalJSON(data []byte) error {
+	return (*time.Time)(expiresAt).UnmarshalJSON(data)
+}
+
+type CreatedAt time.Time
+
+func NewCreatedAt(v time.Time) (CreatedAt, error) {
+	return CreatedAt(v), nil
+}
+
+// LastSeenAt is the last time the session was used. It is only written when
+// it has fallen behind by the store's touch interval, so it can lag by up to
+// that much.
+type LastSeenAt time.Time
+
+func NewLastSeenAt(v time.Time) (LastSeenAt, error) {
+	return LastSeenAt(v), nil
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var ErrMaxSessionsPerUserInvalid = errors.New("session limit: max sessions per user must be positive")
+
+// SessionEvictionPolicy picks which of the active sessions give way so that
+// one more session fits within max.
+type SessionEvictionPolicy interface {
+	Evict(active []*Session, max int) []*Session
+}
+
+type EvictOldestSessionPolicy struct{}
+
+func (EvictOldestSessionPolicy) Evict(active []*Session, max int) []*Session {
+	excess := len(active) - max + 1
+	if excess <= 0 {
+		return nil
+	}
+
+	oldest := make([]*Session, len(active))
+	copy(oldest, active)
+	sort.SliceStable(oldest, func(i, j int) bool {
+		return time.Time(oldest[i].CreatedAt()).Before(time.Time(oldest[j].CreatedAt()))
+	})
+
+	return oldest[:excess]
+}
+
+type SessionLimit struct {
+	maxSessionsPerUser int
+	policy             SessionEvictionPolicy
+}
+
+func (l *SessionLimit) MaxSessionsPerUser() int { return l.maxSessionsPerUser }
+
+func NewSessionLimit(maxSessionsPerUser int, policy SessionEvictionPolicy) (*SessionLimit, error) {
+	if maxSessionsPerUser <= 0 {
+		return nil, errors.WithStack(ErrMaxSessionsPerUserInvalid)
+	}
+
+	return &SessionLimit{
+		maxSessionsPerUser: maxSessionsPerUser,
+		policy:             policy,
+	}, nil
+}
+
+// SessionsToEvict only considers sessions that can still be used at now.
+func (l *SessionLimit) SessionsToEvict(sessions []*Session, now time.Time) []*Session {
+	active := make([]*Session, 0, len(sessions))
+	for _, session := range sessions {
+		if !bool(session.IsBlocked()) && !bool(session.IsRotated()) && !session.IsExpired(now) {
+			active = append(active, session)
+		}
+	}
+
+	return l.policy.Evict(active, l.maxSessionsPerUser)
+}
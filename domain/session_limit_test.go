@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSessionLimitSessionsToEvict(t *testing.T) {
+	clock := newFakeClock()
+	limit, err := NewSessionLimit(5, EvictOldestSessionPolicy{})
+	if err != nil {
+		t.Fatalf("NewSessionLimit() = %v", err)
+	}
+
+	// Created one minute apart, oldest first.
+	sessions := make([]*Session, 0, 5)
+	for i := 0; i < 5; i++ {
+		createdAt := clock.Now().Add(time.Duration(i) * time.Minute)
+		sessions = append(sessions, newTestSession(t, 1, createdAt, clock.Now().Add(time.Hour)))
+	}
+	reversed := []*Session{sessions[4], sessions[3], sessions[2], sessions[1], sessions[0]}
+
+	blocked := newTestSession(t, 1, clock.Now().Add(-time.Minute), clock.Now().Add(time.Hour))
+	blocked.isBlocked = true
+	expired := newTestSession(t, 1, clock.Now().Add(-2*time.Hour), clock.Now().Add(-time.Hour))
+
+	tests := []struct {
+		name     string
+		sessions []*Session
+		want     []*Session
+	}{
+		{name: "below the limit", sessions: sessions[:4], want: nil},
+		{name: "at the limit evicts the oldest", sessions: sessions, want: sessions[:1]},
+		{name: "order does not matter", sessions: reversed, want: sessions[:1]},
+		{name: "inactive sessions are ignored", sessions: append([]*Session{blocked, expired}, sessions[:4]...), want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := limit.SessionsToEvict(tt.sessions, clock.Now())
+			if len(got) != len(tt.want) {
+				t.Fatalf("SessionsToEvict() evicted %d sessions, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if got[i].ID() != tt.want[i].ID() {
+					t.Fatalf("SessionsToEvict()[%d] = %v, want %v", i, got[i].ID(), tt.want[i].ID())
+				}
+			}
+		})
+	}
+}
+
+func TestNewSessionLimitInvalid(t *testing.T) {
+	for _, max := range []int{0, -1} {
+		if _, err := NewSessionLimit(max, EvictOldestSessionPolicy{}); !errors.Is(err, ErrMaxSessionsPerUserInvalid) {
+			t.Fatalf("NewSessionLimit(%d) = %v, want %v", max, err, ErrMaxSessionsPerUserInvalid)
+		}
+	}
+}
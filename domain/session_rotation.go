@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrRefreshTokenReuse = errors.New("refresh token: already used")
+	ErrSessionBlocked    = errors.New("session: blocked")
+	ErrSessionExpired    = errors.New("session: expired")
+)
+
+// RotateSession marks old as used and issues a replacement session with a new
+// refresh token for the same user and device. Presenting a token whose
+// session was already rotated means it was replayed, so callers must block
+// every session of the user when ErrRefreshTokenReuse is returned.
+func RotateSession(old *Session, now time.Time, duration time.Duration) (*Session, RefreshToken, error) {
+	if old.IsRotated() {
+		return nil, "", errors.WithStack(ErrRefreshTokenReuse)
+	}
+
+	if old.IsBlocked() {
+		return nil, "", errors.WithStack(ErrSessionBlocked)
+	}
+
+	if old.IsExpired(now) {
+		return nil, "", errors.WithStack(ErrSessionExpired)
+	}
+
+	sessionID, err := NewSessionID()
+	if err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+
+	refreshToken, err := GenerateRefreshToken()
+	if err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+
+	expiresAt, err := NewExpiresAt(now.Add(duration))
+	if err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+
+	userMetaData, err := NewUserMetadata(old.UserAgent(), old.ClientIp())
+	if err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+
+	session, err := NewSession(sessionID, old.UserID(), refreshToken, expiresAt, userMetaData)
+	if err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+
+	old.isRotated = true
+
+	return session, refreshToken, nil
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRotateSession(t *testing.T) {
+	// NewSession checks the expiry against the wall clock.
+	clock := &fakeClock{now: time.Now()}
+	old := newTestSession(t, 1, clock.Now(), clock.Now().Add(time.Hour))
+
+	next, refreshToken, err := RotateSession(old, clock.Now(), time.Hour)
+	if err != nil {
+		t.Fatalf("RotateSession() = %v", err)
+	}
+	if !old.IsRotated() {
+		t.Fatal("old session is not marked as rotated")
+	}
+	if next.ID() == old.ID() || next.UserID() != old.UserID() || next.ClientIp() != old.ClientIp() {
+		t.Fatalf("RotateSession() = %v for user %v, want a new session for user %v", next.ID(), next.UserID(), old.UserID())
+	}
+	if !refreshToken.VerifyAgainst(string(next.RefreshTokenHash())) {
+		t.Fatal("returned refresh token does not match the new session")
+	}
+
+	// Replaying the token of the rotated session must be detected.
+	if _, _, err := RotateSession(old, clock.Now(), time.Hour); !errors.Is(err, ErrRefreshTokenReuse) {
+		t.Fatalf("replayed RotateSession() = %v, want %v", err, ErrRefreshTokenReuse)
+	}
+}
+
+func TestRotateSessionErrors(t *testing.T) {
+	clock := newFakeClock()
+
+	blocked := newTestSession(t, 1, clock.Now(), clock.Now().Add(time.Hour))
+	blocked.isBlocked = true
+
+	expired := newTestSession(t, 1, clock.Now().Add(-2*time.Hour), clock.Now().Add(-time.Hour))
+
+	tests := []struct {
+		name    string
+		session *Session
+		wantErr error
+	}{
+		{name: "blocked", session: blocked, wantErr: ErrSessionBlocked},
+		{name: "expired", session: expired, wantErr: ErrSessionExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := RotateSession(tt.session, clock.Now(), time.Hour); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RotateSession() = %v, want %v", err, tt.wantErr)
+			}
+			if tt.session.IsRotated() {
+				t.Fatal("rejected session was marked as rotated")
+			}
+		})
+	}
+}
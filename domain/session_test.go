@@ -0,0 +1,223 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestNewSessionIDFromBytes(t *testing.T) {
+	generated, err := NewSessionID()
+	if err != nil {
+		t.Fatalf("NewSessionID() = %v", err)
+	}
+
+	v1, err := uuid.NewUUID()
+	if err != nil {
+		t.Fatalf("uuid.NewUUID() = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		bytes   []byte
+		wantErr bool
+	}{
+		{name: "generated", bytes: generated.Bytes(), wantErr: false},
+		{name: "five bytes", bytes: []byte{1, 2, 3, 4, 5}, wantErr: true},
+		{name: "empty", bytes: nil, wantErr: true},
+		{name: "version 1", bytes: v1[:], wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewSessionIDFromBytes(tt.bytes)
+			if tt.wantErr {
+				if !errors.Is(err, ErrSessionIDInvalid) {
+					t.Fatalf("NewSessionIDFromBytes() = %v, want %v", err, ErrSessionIDInvalid)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewSessionIDFromBytes() = %v", err)
+			}
+			if got != generated {
+				t.Fatalf("NewSessionIDFromBytes() = %s, want %s", got, generated)
+			}
+		})
+	}
+}
+
+func TestSessionIDString(t *testing.T) {
+	id, err := NewSessionID()
+	if err != nil {
+		t.Fatalf("NewSessionID() = %v", err)
+	}
+
+	parsed, err := NewSessionIDFromString(id.String())
+	if err != nil {
+		t.Fatalf("NewSessionIDFromString(%q) = %v", id.String(), err)
+	}
+	if parsed != id {
+		t.Fatalf("NewSessionIDFromString(%q) = %s, want %s", id.String(), parsed, id)
+	}
+
+	if len(id.String()) != 36 {
+		t.Fatalf("String() = %q, want the 36 character hyphenated form", id.String())
+	}
+}
+
+func TestNewSession(t *testing.T) {
+	sessionID, err := NewSessionID()
+	if err != nil {
+		t.Fatalf("NewSessionID() = %v", err)
+	}
+	clientIp, err := NewClientIp("203.0.113.42")
+	if err != nil {
+		t.Fatalf("NewClientIp() = %v", err)
+	}
+	metaData, err := NewUserMetadata(UserAgent("Mozilla/5.0"), clientIp)
+	if err != nil {
+		t.Fatalf("NewUserMetadata() = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		userID       UserID
+		refreshToken RefreshToken
+		expiresAt    time.Time
+		wantErr      error
+	}{
+		{name: "valid", userID: 1, refreshToken: "token", expiresAt: time.Now().Add(time.Hour), wantErr: nil},
+		{name: "zero user id", userID: 0, refreshToken: "token", expiresAt: time.Now().Add(time.Hour), wantErr: ErrUserIDZero},
+		{name: "blank refresh token", userID: 1, refreshToken: "", expiresAt: time.Now().Add(time.Hour), wantErr: ErrRefreshTokenEmpty},
+		{name: "past expiry", userID: 1, refreshToken: "token", expiresAt: time.Now().Add(-time.Minute), wantErr: ErrSessionExpiresAtPast},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session, err := NewSession(sessionID, tt.userID, tt.refreshToken, ExpiresAt(tt.expiresAt), metaData)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewSession() = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if session.ID() != sessionID || session.UserID() != tt.userID {
+				t.Fatalf("NewSession() = %v/%v, want %v/%v", session.ID(), session.UserID(), sessionID, tt.userID)
+			}
+			if session.RefreshTokenHash() == RefreshTokenHash(tt.refreshToken) {
+				t.Fatal("RefreshTokenHash() stores the raw token")
+			}
+			if bool(session.IsBlocked()) || bool(session.IsRotated()) {
+				t.Fatal("new session is blocked or rotated")
+			}
+		})
+	}
+}
+
+func TestNewSessionWithClock(t *testing.T) {
+	sessionID, err := NewSessionID()
+	if err != nil {
+		t.Fatalf("NewSessionID() = %v", err)
+	}
+	metaData, err := NewUserMetadata(UserAgent("Mozilla/5.0"), ClientIp("203.0.113.42"))
+	if err != nil {
+		t.Fatalf("NewUserMetadata() = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		expiresIn time.Duration
+		advance   time.Duration
+		wantErr   error
+		wantAlive bool
+	}{
+		{name: "before expiry", expiresIn: time.Hour, advance: time.Hour - time.Second, wantErr: nil, wantAlive: true},
+		{name: "at expiry", expiresIn: time.Hour, advance: time.Hour, wantErr: nil, wantAlive: false},
+		{name: "expires now", expiresIn: 0, wantErr: ErrSessionExpiresAtPast},
+		{name: "expired", expiresIn: -time.Second, wantErr: ErrSessionExpiresAtPast},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newFakeClock()
+			created := clock.Now()
+
+			session, err := NewSessionWithClock(sessionID, 1, "token", ExpiresAt(created.Add(tt.expiresIn)), metaData, clock)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewSessionWithClock() = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if !time.Time(session.CreatedAt()).Equal(created) || !time.Time(session.LastSeenAt()).Equal(created) {
+				t.Fatalf("CreatedAt(), LastSeenAt() = %v, %v, want %v", session.CreatedAt(), session.LastSeenAt(), created)
+			}
+
+			clock.Advance(tt.advance)
+			if got := !session.IsExpired(clock.Now()); got != tt.wantAlive {
+				t.Fatalf("IsExpired() after %v = %v, want %v", tt.advance, !got, !tt.wantAlive)
+			}
+		})
+	}
+}
+
+func newTestSession(t *testing.T, userID uint64, createdAt time.Time, expiresAt time.Time) *Session {
+	t.Helper()
+
+	sessionID, err := NewSessionID()
+	if err != nil {
+		t.Fatalf("NewSessionID() = %v", err)
+	}
+
+	session, err := NewSessionFromSource(
+		sessionID.String(),
+		userID,
+		RefreshToken("token-"+sessionID.String()).Hash(),
+		"Mozilla/5.0",
+		"203.0.113.42",
+		false,
+		false,
+		expiresAt,
+		createdAt,
+		createdAt,
+	)
+	if err != nil {
+		t.Fatalf("NewSessionFromSource() = %v", err)
+	}
+
+	return session
+}
+
+func TestSessionIsExpired(t *testing.T) {
+	clock := newFakeClock()
+	session := newTestSession(t, 1, clock.Now(), clock.Now().Add(time.Hour))
+
+	tests := []struct {
+		name        string
+		advance     time.Duration
+		wantExpired bool
+		wantUntil   time.Duration
+	}{
+		{name: "fresh", advance: 0, wantExpired: false, wantUntil: time.Hour},
+		{name: "almost expired", advance: 59 * time.Minute, wantExpired: false, wantUntil: time.Minute},
+		{name: "at expiry", advance: time.Minute, wantExpired: true, wantUntil: 0},
+		{name: "past expiry", advance: time.Minute, wantExpired: true, wantUntil: 0},
+	}
+
+	// The cases share the clock and advance it in order.
+	for _, tt := range tests {
+		clock.Advance(tt.advance)
+
+		if got := session.IsExpired(clock.Now()); got != tt.wantExpired {
+			t.Fatalf("%s: IsExpired() = %v, want %v", tt.name, got, tt.wantExpired)
+		}
+		if got := session.TimeUntilExpiry(clock.Now()); got != tt.wantUntil {
+			t.Fatalf("%s: TimeUntilExpiry() = %v, want %v", tt.name, got, tt.wantUntil)
+		}
+	}
+}
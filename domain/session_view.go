@@ -0,0 +1,37 @@
+package domain
+
+import "time"
+
+// SessionView is a session as shown on the "your active sessions" page. The
+// address is anonymized, since the page only needs a rough location.
+type SessionView struct {
+	ID         SessionID
+	Device     string
+	DeviceType string
+	ClientIp   ClientIp
+	LastSeenAt time.Time
+	IsCurrent  bool
+}
+
+// NewSessionViews skips sessions that can no longer be used at now and flags
+// the one with currentID.
+func NewSessionViews(sessions []*Session, currentID SessionID, now time.Time) []SessionView {
+	views := make([]SessionView, 0, len(sessions))
+	for _, session := range sessions {
+		if bool(session.IsBlocked()) || bool(session.IsRotated()) || session.IsExpired(now) {
+			continue
+		}
+
+		parsed := session.UserAgent().Parse()
+		views = append(views, SessionView{
+			ID:         session.ID(),
+			Device:     parsed.String(),
+			DeviceType: parsed.DeviceType,
+			ClientIp:   session.ClientIp().Anonymize(),
+			LastSeenAt: time.Time(session.LastSeenAt()),
+			IsCurrent:  session.ID() == currentID,
+		})
+	}
+
+	return views
+}
@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+const testChromeOnMacUserAgent = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/114.0.0.0 Safari/537.36"
+
+func newTestSessionWithState(t *testing.T, expiresAt time.Time, isBlocked, isRotated bool) *Session {
+	t.Helper()
+
+	sessionID, err := NewSessionID()
+	if err != nil {
+		t.Fatalf("NewSessionID() = %v", err)
+	}
+
+	lastSeenAt := expiresAt.Add(-2 * time.Hour)
+	session, err := NewSessionFromSource(
+		sessionID.String(),
+		1,
+		RefreshToken("token-"+sessionID.String()).Hash(),
+		testChromeOnMacUserAgent,
+		"203.0.113.42",
+		isBlocked,
+		isRotated,
+		expiresAt,
+		lastSeenAt.Add(-time.Hour),
+		lastSeenAt,
+	)
+	if err != nil {
+		t.Fatalf("NewSessionFromSource() = %v", err)
+	}
+
+	return session
+}
+
+func TestNewSessionViews(t *testing.T) {
+	now := newFakeClock().Now()
+
+	current := newTestSessionWithState(t, now.Add(time.Hour), false, false)
+	other := newTestSessionWithState(t, now.Add(time.Hour), false, false)
+	expired := newTestSessionWithState(t, now.Add(-time.Second), false, false)
+	blocked := newTestSessionWithState(t, now.Add(time.Hour), true, false)
+	rotated := newTestSessionWithState(t, now.Add(time.Hour), false, true)
+
+	views := NewSessionViews([]*Session{current, expired, other, blocked, rotated}, current.ID(), now)
+
+	tests := []struct {
+		name          string
+		session       *Session
+		wantIsCurrent bool
+	}{
+		{name: "current", session: current, wantIsCurrent: true},
+		{name: "other", session: other, wantIsCurrent: false},
+	}
+
+	if len(views) != len(tests) {
+		t.Fatalf("NewSessionViews() returned %d views, want %d", len(views), len(tests))
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			view := views[i]
+
+			if view.ID != tt.session.ID() {
+				t.Fatalf("views[%d].ID = %s, want %s", i, view.ID, tt.session.ID())
+			}
+			if view.IsCurrent != tt.wantIsCurrent {
+				t.Fatalf("views[%d].IsCurrent = %v, want %v", i, view.IsCurrent, tt.wantIsCurrent)
+			}
+			if view.Device != "Chrome on macOS" || view.DeviceType != "Desktop" {
+				t.Fatalf("views[%d] device = %q %q, want %q %q", i, view.Device, view.DeviceType, "Chrome on macOS", "Desktop")
+			}
+			if view.ClientIp != "203.0.113.0" {
+				t.Fatalf("views[%d].ClientIp = %q, want %q", i, view.ClientIp, "203.0.113.0")
+			}
+			if !view.LastSeenAt.Equal(time.Time(tt.session.LastSeenAt())) {
+				t.Fatalf("views[%d].LastSeenAt = %v, want %v", i, view.LastSeenAt, tt.session.LastSeenAt())
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrTimeSeriesRangeInvalid    = errors.New("time series: from must not be after to")
+	ErrTimeSeriesLocationMissing = errors.New("time series: location must not be nil")
+)
+
+// DayPoint is the cumulative amount invested up to the end of Date, which is
+// midnight in the requested location.
+type DayPoint struct {
+	Date  time.Time
+	Total Amount
+}
+
+// TimeSeriesByDay returns one point per calendar day in loc from the day of
+// from to the day of to. Investments before from count towards the first
+// point. Days are stepped by calendar date, so a 23 or 25 hour day around a
+// DST change is still one point. All investments must share one currency;
+// with none, totals have no currency.
+func TimeSeriesByDay(invests []*Invest, from, to time.Time, loc *time.Location) ([]DayPoint, error) {
+	if loc == nil {
+		return nil, errors.WithStack(ErrTimeSeriesLocationMissing)
+	}
+
+	if from.After(to) {
+		return nil, errors.WithStack(ErrTimeSeriesRangeInvalid)
+	}
+
+	var currency Currency
+	if len(invests) > 0 {
+		currency = invests[0].Currency()
+	}
+
+	start := startOfDay(from, loc)
+	end := startOfDay(to, loc)
+
+	daily := make(map[int64]Amount)
+	total := NewZeroAmount(currency)
+	for _, invest := range invests {
+		day := startOfDay(time.Time(invest.InvestedAt()), loc)
+		if day.After(end) {
+			continue
+		}
+
+		if day.Before(start) {
+			sum, err := total.Add(invest.Amount())
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			total = sum
+			continue
+		}
+
+		amount, ok := daily[day.Unix()]
+		if !ok {
+			amount = NewZeroAmount(currency)
+		}
+
+		sum, err := amount.Add(invest.Amount())
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		daily[day.Unix()] = sum
+	}
+
+	points := []DayPoint{}
+	for day := start; !day.After(end); day = nextDay(day, loc) {
+		if amount, ok := daily[day.Unix()]; ok {
+			sum, err := total.Add(amount)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			total = sum
+		}
+
+		points = append(points, DayPoint{Date: day, Total: total})
+	}
+
+	return points, nil
+}
+
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.In(loc).Date()
+
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
+
+func nextDay(day time.Time, loc *time.Location) time.Time {
+	y, m, d := day.Date()
+
+	return time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+}
@@ -0,0 +1,132 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func newTestInvestAt(t *testing.T, amount string, investedAt time.Time) *Invest {
+	t.Helper()
+
+	invest, err := NewInvestFromSource(1, 1, amount, "USD", "stock", investedAt, 1)
+	if err != nil {
+		t.Fatalf("NewInvestFromSource() = %v", err)
+	}
+
+	return invest
+}
+
+func TestTimeSeriesByDay(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("LoadLocation() = %v", err)
+	}
+	at := func(month time.Month, day, hour, min int) time.Time {
+		return time.Date(2023, month, day, hour, min, 0, 0, newYork)
+	}
+
+	type point struct {
+		date  time.Time
+		total string
+	}
+
+	tests := []struct {
+		name    string
+		invests []*Invest
+		from    time.Time
+		to      time.Time
+		want    []point
+	}{
+		{
+			// Clocks move from 02:00 EST to 03:00 EDT on 12 March, so that day
+			// has 23 hours. 23:30 EDT is already 13 March in UTC.
+			name: "spring forward",
+			invests: []*Invest{
+				newTestInvestAt(t, "5.00", at(time.March, 1, 12, 0)),
+				newTestInvestAt(t, "10.00", at(time.March, 12, 1, 30)),
+				newTestInvestAt(t, "20.00", at(time.March, 12, 23, 30)),
+				newTestInvestAt(t, "40.00", at(time.March, 14, 0, 0)),
+			},
+			from: at(time.March, 11, 15, 0),
+			to:   at(time.March, 14, 9, 0),
+			want: []point{
+				{date: at(time.March, 11, 0, 0), total: "5.00"},
+				{date: at(time.March, 12, 0, 0), total: "35.00"},
+				{date: at(time.March, 13, 0, 0), total: "35.00"},
+				{date: at(time.March, 14, 0, 0), total: "75.00"},
+			},
+		},
+		{
+			// Clocks move from 02:00 EDT back to 01:00 EST on 5 November, so
+			// that day has 25 hours.
+			name: "fall back",
+			invests: []*Invest{
+				newTestInvestAt(t, "10.00", at(time.November, 5, 23, 30)),
+				newTestInvestAt(t, "20.00", at(time.November, 7, 0, 0)),
+			},
+			from: at(time.November, 4, 0, 0),
+			to:   at(time.November, 6, 0, 0),
+			want: []point{
+				{date: at(time.November, 4, 0, 0), total: "0.00"},
+				{date: at(time.November, 5, 0, 0), total: "10.00"},
+				{date: at(time.November, 6, 0, 0), total: "10.00"},
+			},
+		},
+		{
+			// Without invests the totals have no currency to format with.
+			name:    "empty range without invests",
+			invests: nil,
+			from:    at(time.March, 12, 9, 0),
+			to:      at(time.March, 12, 18, 0),
+			want: []point{
+				{date: at(time.March, 12, 0, 0), total: "0"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TimeSeriesByDay(tt.invests, tt.from, tt.to, newYork)
+			if err != nil {
+				t.Fatalf("TimeSeriesByDay() = %v", err)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("TimeSeriesByDay() returned %d points, want %d", len(got), len(tt.want))
+			}
+			for i, p := range got {
+				if !p.Date.Equal(tt.want[i].date) {
+					t.Fatalf("points[%d].Date = %v, want %v", i, p.Date, tt.want[i].date)
+				}
+				if p.Total.String() != tt.want[i].total {
+					t.Fatalf("points[%d].Total = %s, want %s", i, p.Total, tt.want[i].total)
+				}
+			}
+		})
+	}
+}
+
+func TestTimeSeriesByDayInvalid(t *testing.T) {
+	now := time.Date(2023, time.March, 12, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		from    time.Time
+		to      time.Time
+		loc     *time.Location
+		wantErr error
+	}{
+		{name: "from after to", from: now.Add(time.Second), to: now, loc: time.UTC, wantErr: ErrTimeSeriesRangeInvalid},
+		{name: "nil location", from: now, to: now, loc: nil, wantErr: ErrTimeSeriesLocationMissing},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := TimeSeriesByDay(nil, tt.from, tt.to, tt.loc); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("TimeSeriesByDay() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
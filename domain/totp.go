@@ -0,0 +1,159 @@
+package domain
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// TOTP parameters follow the defaults every authenticator app understands:
+// RFC 6238 with HMAC-SHA1, 6 digits and a 30 second step.
+const (
+	totpSecretBytes = 20
+	TOTPDigits      = 6
+	TOTPPeriod      = 30 * time.Second
+	TOTPSkewSteps   = 1
+)
+
+var (
+	ErrTOTPSecretInvalid          = errors.New("totp secret: invalid")
+	ErrEncryptedTOTPSecretInvalid = errors.New("encrypted totp secret: invalid")
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+type TOTPSecret []byte
+
+func GenerateTOTPSecret() (TOTPSecret, error) {
+	b := make([]byte, totpSecretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return TOTPSecret(b), nil
+}
+
+func NewTOTPSecretFromString(v string) (TOTPSecret, error) {
+	b, err := totpEncoding.DecodeString(v)
+	if err != nil {
+		return nil, errors.Wrap(ErrTOTPSecretInvalid, err.Error())
+	}
+	if len(b) == 0 {
+		return nil, errors.WithStack(ErrTOTPSecretInvalid)
+	}
+
+	return TOTPSecret(b), nil
+}
+
+// String is the base32 form users type in when they cannot scan the QR code.
+func (s TOTPSecret) String() string {
+	return totpEncoding.EncodeToString(s)
+}
+
+func (s TOTPSecret) ProvisioningURI(issuer, account string) string {
+	query := url.Values{}
+	query.Set("secret", s.String())
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(TOTPDigits))
+	query.Set("period", fmt.Sprint(int(TOTPPeriod/time.Second)))
+
+	uri := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + account,
+		RawQuery: query.Encode(),
+	}
+
+	return uri.String()
+}
+
+// Validate accepts the code of the step containing now and of the steps
+// right before and after it, to tolerate clock drift on the device.
+func (s TOTPSecret) Validate(code string, now time.Time) bool {
+	if len(code) != TOTPDigits {
+		return false
+	}
+
+	step := now.Unix() / int64(TOTPPeriod/time.Second)
+
+	valid := 0
+	for offset := -TOTPSkewSteps; offset <= TOTPSkewSteps; offset++ {
+		expected := s.code(uint64(step + int64(offset)))
+		valid |= subtle.ConstantTimeCompare([]byte(expected), []byte(code))
+	}
+
+	return valid == 1
+}
+
+func (s TOTPSecret) code(counter uint64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+
+	mac := hmac.New(sha1.New, s)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	truncated := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < TOTPDigits; i++ {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", TOTPDigits, truncated%mod)
+}
+
+// EncryptedTOTPSecret is how the secret is stored: nonce and ciphertext,
+// base64 encoded.
+type EncryptedTOTPSecret string
+
+func (s TOTPSecret) Encrypt(key SymmetricKey) (EncryptedTOTPSecret, error) {
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(s)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	sealed := aead.Seal(nonce, nonce, s, nil)
+
+	return EncryptedTOTPSecret(base64.RawStdEncoding.EncodeToString(sealed)), nil
+}
+
+func (e EncryptedTOTPSecret) Decrypt(key SymmetricKey) (TOTPSecret, error) {
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	sealed, err := base64.RawStdEncoding.DecodeString(string(e))
+	if err != nil {
+		return nil, errors.Wrap(ErrEncryptedTOTPSecretInvalid, err.Error())
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.WithStack(ErrEncryptedTOTPSecretInvalid)
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	secret, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, errors.Wrap(ErrEncryptedTOTPSecretInvalid, err.Error())
+	}
+
+	return TOTPSecret(secret), nil
+}
@@ -0,0 +1,144 @@
+package domain
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA1 seed of the RFC 6238 appendix B test vectors.
+var rfc6238Secret = TOTPSecret("12345678901234567890")
+
+func TestTOTPSecretValidate(t *testing.T) {
+	at := time.Unix(1111111109, 0)
+
+	tests := []struct {
+		name string
+		code string
+		now  time.Time
+		want bool
+	}{
+		{name: "rfc 6238 vector at 59", code: "287082", now: time.Unix(59, 0), want: true},
+		{name: "rfc 6238 vector at 1111111109", code: "081804", now: at, want: true},
+		{name: "previous step", code: "081804", now: at.Add(TOTPPeriod), want: true},
+		{name: "next step", code: "081804", now: at.Add(-TOTPPeriod), want: true},
+		{name: "a minute stale", code: "081804", now: at.Add(time.Minute + TOTPPeriod), want: false},
+		{name: "wrong code", code: "081805", now: at, want: false},
+		{name: "too short", code: "81804", now: at, want: false},
+		{name: "empty", code: "", now: at, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rfc6238Secret.Validate(tt.code, tt.now); got != tt.want {
+				t.Fatalf("Validate(%q) = %v, want %v", tt.code, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret() = %v", err)
+	}
+
+	now := time.Now()
+	if !secret.Validate(secret.code(uint64(now.Unix()/int64(TOTPPeriod/time.Second))), now) {
+		t.Fatal("Validate() of a code generated at the same timestamp = false, want true")
+	}
+
+	parsed, err := NewTOTPSecretFromString(secret.String())
+	if err != nil {
+		t.Fatalf("NewTOTPSecretFromString() = %v", err)
+	}
+	if string(parsed) != string(secret) {
+		t.Fatalf("NewTOTPSecretFromString(String()) = %x, want %x", parsed, secret)
+	}
+}
+
+func TestNewTOTPSecretFromString(t *testing.T) {
+	tests := []struct {
+		name    string
+		v       string
+		wantErr error
+	}{
+		{name: "valid", v: "GEZDGNBVGY3TQOJQ", wantErr: nil},
+		{name: "empty", v: "", wantErr: ErrTOTPSecretInvalid},
+		{name: "not base32", v: "not-base32!", wantErr: ErrTOTPSecretInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewTOTPSecretFromString(tt.v); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewTOTPSecretFromString(%q) = %v, want %v", tt.v, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTOTPSecretProvisioningURI(t *testing.T) {
+	uri, err := url.Parse(rfc6238Secret.ProvisioningURI("invest-api", "alice"))
+	if err != nil {
+		t.Fatalf("url.Parse() = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "scheme", got: uri.Scheme, want: "otpauth"},
+		{name: "type", got: uri.Host, want: "totp"},
+		{name: "label", got: uri.Path, want: "/invest-api:alice"},
+		{name: "secret", got: uri.Query().Get("secret"), want: rfc6238Secret.String()},
+		{name: "issuer", got: uri.Query().Get("issuer"), want: "invest-api"},
+		{name: "digits", got: uri.Query().Get("digits"), want: "6"},
+		{name: "period", got: uri.Query().Get("period"), want: "30"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Fatalf("ProvisioningURI() %s = %q, want %q", tt.name, tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTOTPSecretEncrypt(t *testing.T) {
+	key := SymmetricKey(testSymmetricKey)
+
+	encrypted, err := rfc6238Secret.Encrypt(key)
+	if err != nil {
+		t.Fatalf("Encrypt() = %v", err)
+	}
+	tampered := []byte(encrypted)
+	tampered[len(tampered)/2] ^= 0x01
+
+	tests := []struct {
+		name      string
+		encrypted EncryptedTOTPSecret
+		key       SymmetricKey
+		wantErr   error
+	}{
+		{name: "round trip", encrypted: encrypted, key: key, wantErr: nil},
+		{name: "wrong key", encrypted: encrypted, key: SymmetricKey("abcdefghijklmnopqrstuvwxyz012345"), wantErr: ErrEncryptedTOTPSecretInvalid},
+		{name: "tampered", encrypted: EncryptedTOTPSecret(tampered), key: key, wantErr: ErrEncryptedTOTPSecretInvalid},
+		{name: "too short", encrypted: "AAAA", key: key, wantErr: ErrEncryptedTOTPSecretInvalid},
+		{name: "not base64", encrypted: "!!", key: key, wantErr: ErrEncryptedTOTPSecretInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret, err := tt.encrypted.Decrypt(tt.key)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Decrypt() = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && string(secret) != string(rfc6238Secret) {
+				t.Fatalf("Decrypt() = %x, want %x", secret, rfc6238Secret)
+			}
+		})
+	}
+}
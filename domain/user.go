@@ -1,25 +1,107 @@
 package domain
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
 
 	"github.com/pkg/errors"
 
 	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
 )
 
 type User struct {
-	id             UserID
-	name           UserName
-	hashedPassword HashedPassword
-	role           UserRole
+	id              UserID
+	name            UserName
+	hashedPassword  HashedPassword
+	role            UserRole
+	passwordHistory PasswordHistory
+
+	passwordChangedAt time.Time
+}
+
+func (u *User) ID() UserID                       { return u.id }
+func (u *User) Name() UserName                   { return u.name }
+func (u *User) HashedPassword() HashedPassword   { return u.hashedPassword }
+func (u *User) Role() UserRole                   { return u.role }
+func (u *User) PasswordHistory() PasswordHistory { return u.passwordHistory }
+func (u *User) PasswordChangedAt() time.Time     { return u.passwordChangedAt }
+
+// PasswordExpired reports whether the password is at least maxAge old at now.
+// A zero maxAge means passwords never expire.
+func (u *User) PasswordExpired(maxAge time.Duration, now time.Time) bool {
+	if maxAge <= 0 {
+		return false
+	}
+
+	return !now.Before(u.passwordChangedAt.Add(maxAge))
+}
+
+// SetPasswordHistory attaches the previous password hashes loaded alongside
+// the user. Without it ChangePassword only rejects the current password.
+func (u *User) SetPasswordHistory(history PasswordHistory) {
+	u.passwordHistory = history
+}
+
+// userJSON is the public shape of a User. The hashed password is never part
+// of it, and the id is left out until the user is saved.
+type userJSON struct {
+	ID   UserID   `json:"id,omitempty"`
+	Name UserName `json:"name"`
+	Role UserRole `json:"role"`
+}
+
+func (u *User) MarshalJSON() ([]byte, error) {
+	return json.Marshal(userJSON{ID: u.id, Name: u.name, Role: u.role})
+}
+
+// UnmarshalJSON restores id, name and role only; the result has no hashed
+// password and cannot be used to verify a login.
+func (u *User) UnmarshalJSON(data []byte) error {
+	var raw userJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return errors.WithStack(err)
+	}
+
+	name, err := NewUserNameWithReserved(string(raw.Name), nil)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	role, err := NewUserRole(string(raw.Role))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	*u = User{id: raw.ID, name: name, role: role}
+	return nil
+}
+
+// UserDTO is the plain form of a User handed to transports. It has no
+// password field, so nothing secret can be mapped by mistake. ID is empty
+// until the user is saved.
+type UserDTO struct {
+	ID   string
+	Name string
+	Role string
 }
 
-func (u *User) ID() UserID                     { return u.id }
-func (u *User) Name() UserName                 { return u.name }
-func (u *User) HashedPassword() HashedPassword { return u.hashedPassword }
-func (u *User) Role() UserRole                 { return u.role }
+func (u *User) ToDTO() UserDTO {
+	dto := UserDTO{Name: string(u.name), Role: string(u.role)}
+	if u.id != 0 {
+		dto.ID = u.id.String()
+	}
+
+	return dto
+}
 
 func NewUser(
 	name UserName,
@@ -27,24 +109,108 @@ func NewUser(
 	role UserRole,
 ) (*User, error) {
 	return &User{
-		name:           name,
-		hashedPassword: hashedPassword,
-		role:           role,
+		name:              name,
+		hashedPassword:    hashedPassword,
+		role:              role,
+		passwordChangedAt: time.Now(),
 	}, nil
 }
 
+// NewUserForCreate builds a user that is not saved yet, so its ID is zero.
+// The password is validated against policy before it is hashed.
+func NewUserForCreate(
+	name string,
+	password string,
+	role string,
+	policy PasswordPolicy,
+) (*User, error) {
+	return NewUserForCreateWithReserved(name, password, role, DefaultReservedUserNames, policy)
+}
+
+// NewUserForCreateWithReserved is NewUserForCreate with its own reserved
+// names; nil allows any, which seeding uses to create "admin" itself.
+func NewUserForCreateWithReserved(
+	name string,
+	password string,
+	role string,
+	reserved ReservedUserNames,
+	policy PasswordPolicy,
+) (*User, error) {
+	newName, err := NewUserNameWithReserved(name, reserved)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	newPassword, err := policy.Validate(password)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if err := newPassword.ValidateAgainstUser(newName); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	newRole, err := NewUserRole(role)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	hashedPassword, err := newPassword.Hash()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return NewUser(newName, hashedPassword, newRole)
+}
+
+// ValidateUserInput checks every field of a signup and returns all failures
+// as ValidationErrors, or nil. NewUserForCreate stops at the first one.
+func ValidateUserInput(
+	name string,
+	password string,
+	role string,
+	policy PasswordPolicy,
+) error {
+	var errs ValidationErrors
+
+	newName, nameErr := NewUserName(name)
+	if nameErr != nil {
+		errs = append(errs, nameErr)
+	}
+
+	newPassword, err := policy.Validate(password)
+	if err != nil {
+		errs = append(errs, err)
+	} else if nameErr == nil {
+		if err := newPassword.ValidateAgainstUser(newName); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if _, err := NewUserRole(role); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return errs
+}
+
 func NewUserFromSource(
 	id uint64,
 	name string,
 	hashedPassword string,
 	role string,
+	passwordChangedAt time.Time,
 ) (*User, error) {
 	newID, err := NewUserID(id)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
-	newName, err := NewUserName(name)
+	newName, err := NewUserNameWithReserved(name, nil)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -60,16 +226,92 @@ func NewUserFromSource(
 	}
 
 	return &User{
-		id:             newID,
-		name:           newName,
-		hashedPassword: newHashedPassword,
-		role:           newRole,
+		id:                newID,
+		name:              newName,
+		hashedPassword:    newHashedPassword,
+		role:              newRole,
+		passwordChangedAt: passwordChangedAt,
 	}, nil
 }
 
+// UpgradePasswordHash rehashes pass with bcrypt at PasswordHashCost if the
+// stored hash is legacy or uses another cost. Call it only after pass has
+// been verified. It reports whether the hash changed and must be saved; the
+// password itself is unchanged, so passwordChangedAt is kept.
+func (u *User) UpgradePasswordHash(pass Password) (bool, error) {
+	if isArgon2idHash(u.hashedPassword) {
+		return false, nil
+	}
+
+	needsRehash, err := u.hashedPassword.NeedsRehash(PasswordHashCost)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	if !needsRehash {
+		return false, nil
+	}
+
+	hashed, err := pass.HashWithCost(PasswordHashCost)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	u.hashedPassword = hashed
+
+	return true, nil
+}
+
+// ChangePassword replaces the hash only after current is verified and next
+// passes the password rules and is not in the password history. The replaced
+// hash is pushed onto the history.
+func (u *User) ChangePassword(current Password, next Password) error {
+	return u.ChangePasswordWithClock(current, next, SystemClock{})
+}
+
+func (u *User) ChangePasswordWithClock(current Password, next Password, clock Clock) error {
+	return u.ChangePasswordWithPolicy(current, next, DefaultPasswordPolicy, clock)
+}
+
+func (u *User) ChangePasswordWithPolicy(current Password, next Password, policy PasswordPolicy, clock Clock) error {
+	if err := u.hashedPassword.Verify(current); err != nil {
+		return errors.WithStack(err)
+	}
+
+	validated, err := policy.Validate(string(next))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if validated == current {
+		return errors.WithStack(ErrPasswordUnchanged)
+	}
+
+	if err := validated.ValidateAgainstUser(u.name); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if u.passwordHistory.Contains(validated) {
+		return errors.WithStack(ErrPasswordReused)
+	}
+
+	hashed, err := validated.Hash()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if u.passwordHistory.Size() > 0 {
+		u.passwordHistory = u.passwordHistory.Push(u.hashedPassword)
+	}
+	u.hashedPassword = hashed
+	u.passwordChangedAt = clock.Now()
+	return nil
+}
+
 type UserID uint64
 
-var ErrUserIDZero = errors.New("user id: must not be zero")
+var (
+	ErrUserIDZero    = newFieldError("user_id", "zero", "user id: must not be zero")
+	ErrUserIDInvalid = newFieldError("user_id", "invalid", "user id: must be a positive integer")
+)
 
 func NewUserID(v uint64) (UserID, error) {
 	if v == 0 {
@@ -79,16 +321,61 @@ func NewUserID(v uint64) (UserID, error) {
 	return UserID(v), nil
 }
 
+func NewUserIDFromString(s string) (UserID, error) {
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, errors.Wrap(ErrUserIDInvalid, err.Error())
+	}
+
+	return NewUserID(v)
+}
+
+func (id UserID) String() string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+// MarshalJSON encodes the id as a string so JavaScript clients do not lose
+// precision above 2^53.
+func (id UserID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(id.String())
+}
+
+// UnmarshalJSON accepts both a quoted string and a bare number.
+func (id *UserID) UnmarshalJSON(data []byte) error {
+	raw := string(data)
+	if unquoted, err := strconv.Unquote(raw); err == nil {
+		raw = unquoted
+	}
+
+	v, err := NewUserIDFromString(raw)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	*id = v
+	return nil
+}
+
 type UserName string
 
 const UserNameMaxLength = 32
 
 var (
-	ErrUserNameEmpty   = errors.New("user name: must not be empty")
-	ErrUserNameTooLong = fmt.Errorf("user name: must not be longer than %d characters", UserNameMaxLength)
+	ErrUserNameEmpty        = newFieldError("name", "empty", "user name: must not be empty")
+	ErrUserNameTooLong      = newFieldError("name", "too_long", fmt.Sprintf("user name: must not be longer than %d characters", UserNameMaxLength))
+	ErrUserNameReserved     = newFieldError("name", "reserved", "user name: reserved")
+	ErrUserNameInvalidChars = newFieldError("name", "invalid_chars", "user name: must not contain control, invisible or surrounding whitespace characters")
 )
 
 func NewUserName(v string) (UserName, error) {
+	return NewUserNameWithReserved(v, DefaultReservedUserNames)
+}
+
+// NewUserNameWithReserved skips the reserved name check when reserved is nil,
+// which is how names that are already registered are loaded.
+func NewUserNameWithReserved(v string, reserved ReservedUserNames) (UserName, error) {
+	v = norm.NFC.String(v)
+
 	if v == "" {
 		return "", errors.WithStack(ErrUserNameEmpty)
 	}
@@ -97,14 +384,65 @@ func NewUserName(v string) (UserName, error) {
 		return "", errors.WithStack(ErrUserNameTooLong)
 	}
 
+	if hasInvalidUserNameChars(v) {
+		return "", errors.WithStack(ErrUserNameInvalidChars)
+	}
+
+	if reserved.Contains(v) {
+		return "", errors.WithStack(ErrUserNameReserved)
+	}
+
 	return UserName(v), nil
 }
 
+func (n UserName) EqualFold(other UserName) bool {
+	return n.Canonical() == other.Canonical()
+}
+
+// Canonical returns the case-folded NFC form of the name. The db layer should
+// store it alongside the name and put the unique index on it, so that names
+// differing only in case or encoding cannot both be registered.
+func (n UserName) Canonical() string {
+	return canonicalUserName(string(n))
+}
+
+func canonicalUserName(v string) string {
+	return norm.NFC.String(cases.Fold().String(v))
+}
+
+// LooksLikeEmail is a structural check only: exactly one "@" with text on
+// both sides and a dot inside the domain. Use NewEmail to actually validate.
+func (n UserName) LooksLikeEmail() bool {
+	local, domain, ok := strings.Cut(string(n), "@")
+	if !ok || local == "" || strings.Contains(domain, "@") {
+		return false
+	}
+
+	dot := strings.Index(domain, ".")
+
+	return 0 < dot && dot < len(domain)-1
+}
+
+func hasInvalidUserNameChars(v string) bool {
+	if strings.TrimSpace(v) != v {
+		return true
+	}
+
+	for _, r := range v {
+		if unicode.IsControl(r) || unicode.Is(unicode.Cf, r) {
+			return true
+		}
+	}
+
+	return false
+}
+
 type HashedPassword []byte
 
 var (
-	ErrHashedPasswordEmpty    = errors.New("hashed password: must not be empty")
+	ErrHashedPasswordEmpty    = newFieldError("password", "empty", "hashed password: must not be empty")
 	ErrHashedPasswordNotMatch = errors.New("hashed password: not match")
+	ErrHashedPasswordInvalid  = errors.New("hashed password: invalid hash")
 )
 
 func NewHashedPassword(v string) (HashedPassword, error) {
@@ -116,6 +454,14 @@ func NewHashedPassword(v string) (HashedPassword, error) {
 }
 
 func (v HashedPassword) Verify(pass Password) error {
+	if isArgon2idHash(v) {
+		return verifyArgon2id(v, pass)
+	}
+
+	if isLegacySHA256Hash(v) {
+		return verifyLegacySHA256(v, pass)
+	}
+
 	if err := bcrypt.CompareHashAndPassword(v, []byte(pass)); err != nil {
 		return errors.Wrap(ErrHashedPasswordNotMatch, err.Error())
 	}
@@ -123,6 +469,42 @@ func (v HashedPassword) Verify(pass Password) error {
 	return nil
 }
 
+// IsLegacy reports whether v was imported from the previous system.
+func (v HashedPassword) IsLegacy() bool {
+	return isLegacySHA256Hash(v)
+}
+
+// NeedsRehash is always true for legacy hashes.
+func (v HashedPassword) NeedsRehash(desiredCost int) (bool, error) {
+	if v.IsLegacy() {
+		return true, nil
+	}
+
+	cost, err := bcrypt.Cost(v)
+	if err != nil {
+		return false, errors.Wrap(ErrHashedPasswordInvalid, err.Error())
+	}
+
+	return cost != desiredCost, nil
+}
+
+// DummyHash is a bcrypt hash with PasswordHashCost that matches no real
+// password. Verifying against it when the user does not exist makes a failed
+// login take as long as a wrong password, so timing does not reveal which
+// user names are registered.
+var DummyHash = HashedPassword("$2a$10$mYZxrmrO9/NlXr8PJY9D2OEnyA.i/WHhTitz23/P/RSnxYKbyk97K")
+
+// VerifyUserPassword verifies pass against the user's hash, or against
+// DummyHash when user is nil. A nil user always fails.
+func VerifyUserPassword(user *User, pass Password) error {
+	if user == nil {
+		_ = DummyHash.Verify(pass)
+		return errors.WithStack(ErrHashedPasswordNotMatch)
+	}
+
+	return user.HashedPassword().Verify(pass)
+}
+
 type UserRole string
 
 const (
@@ -130,17 +512,35 @@ const (
 	RoleAdmin UserRole = "admin"
 )
 
-var ErrUserRoleInvalid = errors.New("user role: invalid type")
+var ErrUserRoleInvalid = newFieldError("role", "invalid", "user role: invalid type")
 
 func NewUserRole(v string) (UserRole, error) {
-	switch v {
-	case string(RoleUser):
-		return RoleUser, nil
-	case string(RoleAdmin):
-		return RoleAdmin, nil
+	return DefaultRoleRegistry.Validate(v)
+}
+
+// roleHierarchy lists roles from the least to the most privileged.
+var roleHierarchy = []UserRole{
+	RoleUser,
+	RoleAdmin,
+}
+
+func (r UserRole) AtLeast(min UserRole) bool {
+	rank, minRank := roleRank(r), roleRank(min)
+	if rank < 0 || minRank < 0 {
+		return false
 	}
 
-	return UserRole(""), errors.WithStack(ErrUserRoleInvalid)
+	return rank >= minRank
+}
+
+func roleRank(r UserRole) int {
+	for i, role := range roleHierarchy {
+		if role == r {
+			return i
+		}
+	}
+
+	return -1
 }
 
 type Password string
@@ -149,21 +549,32 @@ const (
 	PasswordMinLength = 8
 	PasswordMaxLength = 16
 	PasswordHashCost  = 10
+
+	PasswordLowEntropyRunLength = 4
+
+	// PasswordMaxBytes is the most bcrypt reads; longer input cannot match.
+	PasswordMaxBytes = 72
 )
 
 var (
-	ErrPasswordEmpty    = errors.New("password: must not be empty")
-	ErrPasswordTooShort = fmt.Errorf(
-		"password: must be at least %d characters",
-		PasswordMinLength,
+	// ErrPassword is wrapped by every password error, so errors.Is(err,
+	// ErrPassword) matches any of them.
+	ErrPassword                  = newFieldError("password", "invalid", "password")
+	ErrPasswordEmpty             = newPasswordError("empty", "must not be empty")
+	ErrPasswordTooShort          = newPasswordError("too_short", "too short")
+	ErrPasswordTooLong           = newPasswordError("too_long", "too long")
+	ErrPasswordDoesNotFollowRule = newPasswordError("rules", "does not follow the rules")
+	ErrPasswordBlacklisted       = newPasswordError("blacklisted", "too common")
+	ErrPasswordLowEntropy        = newPasswordError("low_entropy", "must not contain repeated or sequential characters")
+	ErrPasswordContainsUsername  = newPasswordError("contains_user_name", "must not contain the user name")
+	ErrPasswordUnchanged         = newPasswordError("unchanged", "must differ from the current password")
+	// ErrPasswordHashCostInvalid is a server misconfiguration, not bad user
+	// input, so it matches neither ErrPassword nor ErrValidation.
+	ErrPasswordHashCostInvalid = errors.New(
+		fmt.Sprintf("password hash cost: must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost),
 	)
-	ErrPasswordTooLong = fmt.Errorf(
-		"password: must be at shorter than %d characters",
-		PasswordMaxLength,
-	)
-	ErrPasswordDoesNotFollowRule = errors.New("password: does not follow the rules")
-	PasswordCharcters            = regexp.MustCompile("^[0-9a-zA-Z!-/:-@[-`{-~]+$")
-	PasswordMustIncludes         = []*regexp.Regexp{
+	PasswordCharacters   = regexp.MustCompile("^[0-9a-zA-Z!-/:-@[-`{-~]+$")
+	PasswordMustIncludes = []*regexp.Regexp{
 		regexp.MustCompile("[[:alpha:]]"),
 		regexp.MustCompile("[[:digit:]]"),
 		regexp.MustCompile("[[:punct:]]"),
@@ -171,34 +582,68 @@ var (
 )
 
 func NewPassword(v string) (Password, error) {
+	return DefaultPasswordPolicy.Validate(v)
+}
+
+// NewLoginPassword only checks what any stored hash needs: not empty and at
+// most PasswordMaxBytes. The creation policy must not run at login, or users
+// whose passwords predate a rule could never sign in again.
+func NewLoginPassword(v string) (Password, error) {
+	v = norm.NFC.String(v)
+
 	if v == "" {
 		return "", errors.WithStack(ErrPasswordEmpty)
 	}
 
-	if len([]rune(v)) < PasswordMinLength {
-		return "", errors.WithStack(ErrPasswordTooShort)
+	if len(v) > PasswordMaxBytes {
+		return "", errors.WithStack(ErrPasswordTooLong)
 	}
 
-	if PasswordMaxLength < len([]rune(v)) {
-		return "", errors.WithStack(ErrPasswordTooLong)
+	return Password(v), nil
+}
+
+func NewPasswordWithBlacklist(v string, blacklist PasswordBlacklist) (Password, error) {
+	policy := DefaultPasswordPolicy
+	policy.Blacklist = blacklist
+
+	return policy.Validate(v)
+}
+
+func (v Password) Hash() (HashedPassword, error) {
+	hashed, err := v.HashWithCost(PasswordHashCost)
+	if err != nil {
+		return nil, errors.WithStack(err)
 	}
 
-	if !PasswordCharcters.MatchString(v) {
-		return "", errors.WithStack(ErrPasswordDoesNotFollowRule)
+	return hashed, nil
+}
+
+func (v Password) HashWithCost(cost int) (HashedPassword, error) {
+	if cost < bcrypt.MinCost || bcrypt.MaxCost < cost {
+		return nil, errors.WithStack(ErrPasswordHashCostInvalid)
 	}
-	for _, expected := range PasswordMustIncludes {
-		if expected.FindString(v) == "" {
-			return "", errors.WithStack(ErrPasswordDoesNotFollowRule)
-		}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(v), cost)
+	if err != nil {
+		return nil, errors.WithStack(err)
 	}
 
-	return Password(v), nil
+	return hashed, nil
 }
 
-func (v Password) Hash() HashedPassword {
-	hashed, _ := bcrypt.GenerateFromPassword([]byte(v), PasswordHashCost)
+func (v Password) ValidateAgainstUser(name UserName) error {
+	password := strings.ToLower(string(v))
+	userName := strings.ToLower(string(name))
+
+	if userName == "" {
+		return nil
+	}
+
+	if strings.Contains(password, userName) || strings.Contains(userName, password) {
+		return errors.WithStack(ErrPasswordContainsUsername)
+	}
 
-	return hashed
+	return nil
 }
 
 type UserMetaData struct {
@@ -209,6 +654,23 @@ type UserMetaData struct {
 func (m *UserMetaData) UserAgent() UserAgent { return m.userAgent }
 func (m *UserMetaData) ClientIp() ClientIp   { return m.clientIp }
 
+// Fingerprint identifies a device coarsely enough to survive browser updates
+// and address changes within a subnet: it hashes the browser and OS families,
+// the device type and the anonymized client ip.
+func (m *UserMetaData) Fingerprint() string {
+	parsed := m.userAgent.Parse()
+	source := strings.Join([]string{
+		parsed.BrowserFamily,
+		parsed.OSFamily,
+		parsed.DeviceType,
+		string(m.clientIp.Anonymize()),
+	}, "|")
+
+	sum := sha256.Sum256([]byte(source))
+
+	return hex.EncodeToString(sum[:])
+}
+
 func NewUserMetadata(userAgent UserAgent, clientIp ClientIp) (*UserMetaData, error) {
 	return &UserMetaData{userAgent: userAgent, clientIp: clientIp}, nil
 }
@@ -0,0 +1,120 @@
+package domain
+
+import (
+	"strings"
+)
+
+const UserAgentUnknown = "Unknown"
+
+const (
+	DeviceTypeDesktop = "Desktop"
+	DeviceTypeMobile  = "Mobile"
+	DeviceTypeTablet  = "Tablet"
+	DeviceTypeBot     = "Bot"
+)
+
+type ParsedUserAgent struct {
+	BrowserFamily  string
+	BrowserVersion string
+	OSFamily       string
+	DeviceType     string
+}
+
+// String reads like "Chrome on macOS".
+func (p ParsedUserAgent) String() string {
+	return p.BrowserFamily + " on " + p.OSFamily
+}
+
+type userAgentRule struct {
+	token  string
+	family string
+}
+
+// browserRules are checked in order, so browsers that also send the tokens of
+// the engine they are built on (Edge and Opera send "Chrome/", Chrome sends
+// "Safari/") must come first.
+var browserRules = []userAgentRule{
+	{token: "Edg/", family: "Edge"},
+	{token: "OPR/", family: "Opera"},
+	{token: "SamsungBrowser/", family: "Samsung Internet"},
+	{token: "CriOS/", family: "Chrome"},
+	{token: "Chrome/", family: "Chrome"},
+	{token: "FxiOS/", family: "Firefox"},
+	{token: "Firefox/", family: "Firefox"},
+	{token: "Version/", family: "Safari"},
+	{token: "curl/", family: "curl"},
+	{token: "grpc-go/", family: "grpc-go"},
+	{token: "PostmanRuntime/", family: "Postman"},
+}
+
+var osRules = []userAgentRule{
+	{token: "Windows NT", family: "Windows"},
+	{token: "iPhone", family: "iOS"},
+	{token: "iPad", family: "iOS"},
+	{token: "Android", family: "Android"},
+	{token: "CrOS", family: "ChromeOS"},
+	{token: "Mac OS X", family: "macOS"},
+	{token: "Linux", family: "Linux"},
+}
+
+var botTokens = []string{"bot", "crawler", "spider", "curl/", "grpc-go/", "PostmanRuntime/"}
+
+func (ua UserAgent) Parse() ParsedUserAgent {
+	v := string(ua)
+	parsed := ParsedUserAgent{
+		BrowserFamily:  UserAgentUnknown,
+		BrowserVersion: UserAgentUnknown,
+		OSFamily:       UserAgentUnknown,
+		DeviceType:     UserAgentUnknown,
+	}
+
+	for _, rule := range browserRules {
+		if i := strings.Index(v, rule.token); i >= 0 {
+			parsed.BrowserFamily = rule.family
+			if version := userAgentVersion(v[i+len(rule.token):]); version != "" {
+				parsed.BrowserVersion = version
+			}
+			break
+		}
+	}
+
+	for _, rule := range osRules {
+		if strings.Contains(v, rule.token) {
+			parsed.OSFamily = rule.family
+			break
+		}
+	}
+
+	parsed.DeviceType = userAgentDeviceType(v, parsed.OSFamily)
+
+	return parsed
+}
+
+func userAgentVersion(v string) string {
+	end := strings.IndexAny(v, " ;)")
+	if end < 0 {
+		return v
+	}
+
+	return v[:end]
+}
+
+func userAgentDeviceType(v string, osFamily string) string {
+	lower := strings.ToLower(v)
+	for _, token := range botTokens {
+		if strings.Contains(lower, strings.ToLower(token)) {
+			return DeviceTypeBot
+		}
+	}
+
+	switch {
+	case strings.Contains(v, "iPad") || (osFamily == "Android" && !strings.Contains(v, "Mobile")):
+		return DeviceTypeTablet
+	case strings.Contains(v, "Mobile") || strings.Contains(v, "iPhone"):
+		return DeviceTypeMobile
+	case osFamily == "Windows" || osFamily == "macOS" || osFamily == "Linux" || osFamily == "ChromeOS":
+		return DeviceTypeDesktop
+	}
+
+	return UserAgentUnknown
+}
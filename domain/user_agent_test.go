@@ -0,0 +1,62 @@
+package domain
+
+import "testing"
+
+func TestUserAgentParse(t *testing.T) {
+	tests := []struct {
+		name string
+		ua   UserAgent
+		want ParsedUserAgent
+	}{
+		{
+			name: "chrome on macos",
+			ua:   "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/114.0.5735.198 Safari/537.36",
+			want: ParsedUserAgent{BrowserFamily: "Chrome", BrowserVersion: "114.0.5735.198", OSFamily: "macOS", DeviceType: DeviceTypeDesktop},
+		},
+		{
+			name: "chrome on android phone",
+			ua:   "Mozilla/5.0 (Linux; Android 13; Pixel 7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/114.0.5735.196 Mobile Safari/537.36",
+			want: ParsedUserAgent{BrowserFamily: "Chrome", BrowserVersion: "114.0.5735.196", OSFamily: "Android", DeviceType: DeviceTypeMobile},
+		},
+		{
+			name: "safari on iphone",
+			ua:   "Mozilla/5.0 (iPhone; CPU iPhone OS 16_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/16.5 Mobile/15E148 Safari/604.1",
+			want: ParsedUserAgent{BrowserFamily: "Safari", BrowserVersion: "16.5", OSFamily: "iOS", DeviceType: DeviceTypeMobile},
+		},
+		{
+			name: "safari on ipad",
+			ua:   "Mozilla/5.0 (iPad; CPU OS 16_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/16.5 Mobile/15E148 Safari/604.1",
+			want: ParsedUserAgent{BrowserFamily: "Safari", BrowserVersion: "16.5", OSFamily: "iOS", DeviceType: DeviceTypeTablet},
+		},
+		{
+			name: "edge on windows",
+			ua:   "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/114.0.0.0 Safari/537.36 Edg/114.0.1823.67",
+			want: ParsedUserAgent{BrowserFamily: "Edge", BrowserVersion: "114.0.1823.67", OSFamily: "Windows", DeviceType: DeviceTypeDesktop},
+		},
+		{
+			name: "curl",
+			ua:   "curl/8.1.2",
+			want: ParsedUserAgent{BrowserFamily: "curl", BrowserVersion: "8.1.2", OSFamily: UserAgentUnknown, DeviceType: DeviceTypeBot},
+		},
+		{
+			name: "empty",
+			ua:   "",
+			want: ParsedUserAgent{BrowserFamily: UserAgentUnknown, BrowserVersion: UserAgentUnknown, OSFamily: UserAgentUnknown, DeviceType: UserAgentUnknown},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.ua.Parse(); got != tt.want {
+				t.Fatalf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsedUserAgentString(t *testing.T) {
+	parsed := ParsedUserAgent{BrowserFamily: "Chrome", OSFamily: "macOS"}
+	if got := parsed.String(); got != "Chrome on macOS" {
+		t.Fatalf("String() = %q, want %q", got, "Chrome on macOS")
+	}
+}
@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestUserBuilder(t *testing.T) {
+	admin, err := NewUserBuilder().WithName("alice").WithPassword("Tx7!qLmZ").WithRole(string(RoleAdmin)).Build()
+	if err != nil {
+		t.Fatalf("Build() = %v", err)
+	}
+
+	if admin.Name() != "alice" {
+		t.Fatalf("Name() = %q, want %q", admin.Name(), "alice")
+	}
+	if admin.Role() != RoleAdmin {
+		t.Fatalf("Role() = %q, want %q", admin.Role(), RoleAdmin)
+	}
+	if admin.ID() != 0 {
+		t.Fatalf("ID() = %d, want 0", admin.ID())
+	}
+	if err := admin.HashedPassword().Verify("Tx7!qLmZ"); err != nil {
+		t.Fatalf("HashedPassword().Verify() = %v", err)
+	}
+}
+
+func TestUserBuilderDefaults(t *testing.T) {
+	first, err := NewUserBuilder().Build()
+	if err != nil {
+		t.Fatalf("Build() = %v", err)
+	}
+	second, err := NewUserBuilder().Build()
+	if err != nil {
+		t.Fatalf("Build() = %v", err)
+	}
+
+	if !strings.HasPrefix(string(first.Name()), "user_") {
+		t.Fatalf("Name() = %q, want a user_ prefix", first.Name())
+	}
+	if first.Name() == second.Name() {
+		t.Fatalf("two built users share the name %q", first.Name())
+	}
+	if first.Role() != RoleUser {
+		t.Fatalf("Role() = %q, want %q", first.Role(), RoleUser)
+	}
+}
+
+func TestUserBuilderInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *UserBuilder
+		wantErr error
+	}{
+		{name: "empty name", builder: NewUserBuilder().WithName(""), wantErr: ErrUserNameEmpty},
+		{name: "short password", builder: NewUserBuilder().WithPassword("Tx7!"), wantErr: ErrPasswordTooShort},
+		{name: "invalid role", builder: NewUserBuilder().WithRole("root"), wantErr: ErrUserRoleInvalid},
+		{name: "first error wins", builder: NewUserBuilder().WithName("").WithRole("root"), wantErr: ErrUserNameEmpty},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.builder.Build(); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Build() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	UserNameSuggestionMaxAmount = 20
+
+	// userNameSuggestionMaxSuffix bounds the search when most candidates are
+	// taken.
+	userNameSuggestionMaxSuffix = 1000
+)
+
+var ErrUserNameSuggestionAmountInvalid = errors.New("user name suggestion: amount must be between 1 and 20")
+
+// SuggestUserNames returns up to n names close to desired, trying
+// "<desired><k>" then "<desired>_<k>" for k = 1, 2, ... Desired is shortened
+// so the suffix fits UserNameMaxLength. Every suggestion passes NewUserName
+// and is not taken; fewer than n are returned if the search runs out.
+func SuggestUserNames(desired UserName, taken func(UserName) bool, n int) ([]UserName, error) {
+	if n <= 0 || UserNameSuggestionMaxAmount < n {
+		return nil, errors.WithStack(ErrUserNameSuggestionAmountInvalid)
+	}
+
+	base := []rune(string(desired))
+	seen := map[string]struct{}{desired.Canonical(): {}}
+	suggestions := make([]UserName, 0, n)
+
+	for k := 1; k <= userNameSuggestionMaxSuffix && len(suggestions) < n; k++ {
+		number := strconv.Itoa(k)
+
+		for _, suffix := range []string{number, "_" + number} {
+			if len(suggestions) == n {
+				break
+			}
+
+			candidate, err := NewUserName(withUserNameSuffix(base, suffix))
+			if err != nil {
+				continue
+			}
+
+			if _, ok := seen[candidate.Canonical()]; ok {
+				continue
+			}
+			seen[candidate.Canonical()] = struct{}{}
+
+			if taken(candidate) {
+				continue
+			}
+
+			suggestions = append(suggestions, candidate)
+		}
+	}
+
+	return suggestions, nil
+}
+
+func withUserNameSuffix(base []rune, suffix string) string {
+	if keep := UserNameMaxLength - len(suffix); len(base) > keep {
+		base = base[:keep]
+	}
+
+	return string(base) + suffix
+}
@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSuggestUserNames(t *testing.T) {
+	takenNames := func(names ...string) func(UserName) bool {
+		set := make(map[string]bool, len(names))
+		for _, name := range names {
+			set[UserName(name).Canonical()] = true
+		}
+
+		return func(n UserName) bool { return set[n.Canonical()] }
+	}
+	long := strings.Repeat("a", UserNameMaxLength)
+
+	tests := []struct {
+		name    string
+		desired string
+		taken   func(UserName) bool
+		n       int
+		want    []UserName
+	}{
+		{
+			name:    "nothing taken",
+			desired: "alice",
+			taken:   takenNames(),
+			n:       2,
+			want:    []UserName{"alice1", "alice_1"},
+		},
+		{
+			name:    "base and first suffixes taken",
+			desired: "alice",
+			taken:   takenNames("alice", "alice1", "alice_1", "alice2"),
+			n:       3,
+			want:    []UserName{"alice_2", "alice3", "alice_3"},
+		},
+		{
+			name:    "taken in another case",
+			desired: "Alice",
+			taken:   takenNames("alice", "ALICE1"),
+			n:       2,
+			want:    []UserName{"Alice_1", "Alice2"},
+		},
+		{
+			name:    "suffix fits the max length",
+			desired: long,
+			taken:   takenNames(long),
+			n:       2,
+			want:    []UserName{UserName(long[:UserNameMaxLength-1] + "1"), UserName(long[:UserNameMaxLength-2] + "_1")},
+		},
+		{
+			name:    "everything taken",
+			desired: "alice",
+			taken:   func(UserName) bool { return true },
+			n:       3,
+			want:    []UserName{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			desired, err := NewUserName(tt.desired)
+			if err != nil {
+				t.Fatalf("NewUserName() = %v", err)
+			}
+
+			got, err := SuggestUserNames(desired, tt.taken, tt.n)
+			if err != nil {
+				t.Fatalf("SuggestUserNames() = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("SuggestUserNames() = %q, want %q", got, tt.want)
+			}
+			for _, suggestion := range got {
+				if _, err := NewUserName(string(suggestion)); err != nil {
+					t.Fatalf("NewUserName(%q) = %v, want every suggestion valid", suggestion, err)
+				}
+			}
+		})
+	}
+}
+
+func TestSuggestUserNamesAmountInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		n    int
+	}{
+		{name: "zero", n: 0},
+		{name: "negative", n: -1},
+		{name: "above max", n: UserNameSuggestionMaxAmount + 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := SuggestUserNames("alice", func(UserName) bool { return false }, tt.n)
+			if !errors.Is(err, ErrUserNameSuggestionAmountInvalid) {
+				t.Fatalf("SuggestUserNames(n=%d) = %v, want %v", tt.n, err, ErrUserNameSuggestionAmountInvalid)
+			}
+		})
+	}
+}
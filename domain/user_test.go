@@ -0,0 +1,769 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestVerifyUserPasswordTiming(t *testing.T) {
+	hashed, err := Password("Xq7!kLmQ").HashWithCost(PasswordHashCost)
+	if err != nil {
+		t.Fatalf("HashWithCost() = %v", err)
+	}
+
+	user, err := NewUserFromSource(1, "alice", string(hashed), string(RoleUser), time.Now())
+	if err != nil {
+		t.Fatalf("NewUserFromSource() = %v", err)
+	}
+
+	wrongPassword := medianDuration(t, func() error { return VerifyUserPassword(user, "Wrong#Pass1") })
+	unknownUser := medianDuration(t, func() error { return VerifyUserPassword(nil, "Wrong#Pass1") })
+
+	// bcrypt dominates both paths; a generous tolerance keeps this stable on
+	// a loaded machine while still catching a skipped hash, which is ~1000x
+	// faster.
+	ratio := float64(unknownUser) / float64(wrongPassword)
+	if ratio < 0.3 || 3 < ratio {
+		t.Fatalf("unknown user took %v, wrong password %v; want comparable durations", unknownUser, wrongPassword)
+	}
+}
+
+func medianDuration(t *testing.T, fn func() error) time.Duration {
+	t.Helper()
+
+	const runs = 5
+	durations := make([]time.Duration, 0, runs)
+	for i := 0; i < runs; i++ {
+		start := time.Now()
+		if err := fn(); err == nil {
+			t.Fatal("verification succeeded, want failure")
+		}
+		durations = append(durations, time.Since(start))
+	}
+
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+
+	return durations[runs/2]
+}
+
+func TestPasswordHashWithCost(t *testing.T) {
+	tests := []struct {
+		name    string
+		cost    int
+		wantErr error
+	}{
+		{name: "min cost", cost: bcrypt.MinCost, wantErr: nil},
+		{name: "default cost", cost: PasswordHashCost, wantErr: nil},
+		{name: "below min", cost: bcrypt.MinCost - 1, wantErr: ErrPasswordHashCostInvalid},
+		{name: "above max", cost: bcrypt.MaxCost + 1, wantErr: ErrPasswordHashCostInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pass := Password("Xq7!kLmQ")
+
+			hashed, err := pass.HashWithCost(tt.cost)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("HashWithCost(%d) = %v, want %v", tt.cost, err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if cost, err := bcrypt.Cost(hashed); err != nil || cost != tt.cost {
+				t.Fatalf("bcrypt.Cost() = %d, %v, want %d", cost, err, tt.cost)
+			}
+			if err := hashed.Verify(pass); err != nil {
+				t.Fatalf("Verify() = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestPasswordHashTooLong(t *testing.T) {
+	tests := []struct {
+		name     string
+		password Password
+		wantErr  bool
+	}{
+		{name: "72 bytes", password: Password("Xq7!" + strings.Repeat("a", 68)), wantErr: false},
+		{name: "73 bytes", password: Password("Xq7!" + strings.Repeat("a", 69)), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hashed, err := tt.password.Hash()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Hash() = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && hashed != nil {
+				t.Fatalf("Hash() = %q, want nil hash on error", hashed)
+			}
+		})
+	}
+}
+
+func TestHashedPasswordNeedsRehash(t *testing.T) {
+	hashed, err := Password("Xq7!kLmQ").HashWithCost(bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("HashWithCost() = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		hashed      HashedPassword
+		desiredCost int
+		want        bool
+		wantErr     error
+	}{
+		{name: "same cost", hashed: hashed, desiredCost: bcrypt.MinCost, want: false, wantErr: nil},
+		{name: "higher cost", hashed: hashed, desiredCost: bcrypt.MinCost + 1, want: true, wantErr: nil},
+		{name: "not bcrypt", hashed: HashedPassword("plain"), desiredCost: bcrypt.MinCost, want: false, wantErr: ErrHashedPasswordInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.hashed.NeedsRehash(tt.desiredCost)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NeedsRehash() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("NeedsRehash() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPasswordValidateAgainstUser(t *testing.T) {
+	tests := []struct {
+		name     string
+		userName UserName
+		password Password
+		wantErr  error
+	}{
+		{name: "contains user name", userName: "alice", password: "Alice12345!", wantErr: ErrPasswordContainsUsername},
+		{name: "contained in user name", userName: "xq7!klmqlong", password: "Xq7!kLmQ", wantErr: ErrPasswordContainsUsername},
+		{name: "unrelated", userName: "alice", password: "Zx9!kLmQ", wantErr: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.password.ValidateAgainstUser(tt.userName); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ValidateAgainstUser(%q) = %v, want %v", tt.userName, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewUserForCreate(t *testing.T) {
+	tests := []struct {
+		name     string
+		userName string
+		password string
+		role     string
+		wantErr  error
+	}{
+		{name: "valid", userName: "alice", password: "Tx7!qLmZ", role: string(RoleUser), wantErr: nil},
+		{name: "invalid name", userName: "", password: "Tx7!qLmZ", role: string(RoleUser), wantErr: ErrUserNameEmpty},
+		{name: "short password", userName: "alice", password: "Tx7!", role: string(RoleUser), wantErr: ErrPasswordTooShort},
+		{name: "password without rules", userName: "alice", password: "abcdefghij", role: string(RoleUser), wantErr: ErrPasswordDoesNotFollowRule},
+		{name: "password with user name", userName: "alice", password: "Alice7!q", role: string(RoleUser), wantErr: ErrPasswordContainsUsername},
+		{name: "invalid role", userName: "alice", password: "Tx7!qLmZ", role: "root", wantErr: ErrUserRoleInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, err := NewUserForCreate(tt.userName, tt.password, tt.role, DefaultPasswordPolicy)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewUserForCreate() = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if user != nil {
+					t.Fatalf("NewUserForCreate() = %v, want nil user on error", user)
+				}
+				return
+			}
+
+			if user.ID() != 0 {
+				t.Fatalf("ID() = %d, want 0", user.ID())
+			}
+			if err := user.HashedPassword().Verify(Password(tt.password)); err != nil {
+				t.Fatalf("HashedPassword().Verify() = %v", err)
+			}
+		})
+	}
+}
+
+func TestNewUserNameNormalizesNFC(t *testing.T) {
+	tests := []struct {
+		name       string
+		composed   string
+		decomposed string
+	}{
+		{name: "acute accent", composed: "caf\u00e9", decomposed: "cafe\u0301"},
+		{name: "hangul", composed: "\ud55c", decomposed: "\u1112\u1161\u11ab"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			composed, err := NewUserName(tt.composed)
+			if err != nil {
+				t.Fatalf("NewUserName(%q) = %v", tt.composed, err)
+			}
+			decomposed, err := NewUserName(tt.decomposed)
+			if err != nil {
+				t.Fatalf("NewUserName(%q) = %v", tt.decomposed, err)
+			}
+
+			if composed != decomposed {
+				t.Fatalf("NewUserName(%q) = %q, want %q", tt.decomposed, decomposed, composed)
+			}
+		})
+	}
+}
+
+func TestNewUserNameLengthAfterNormalization(t *testing.T) {
+	// 32 decomposed characters are 64 runes before NFC and 32 after.
+	name := strings.Repeat("e\u0301", UserNameMaxLength)
+	if _, err := NewUserName(name); err != nil {
+		t.Fatalf("NewUserName() = %v, want nil", err)
+	}
+
+	if _, err := NewUserName(name + "e"); !errors.Is(err, ErrUserNameTooLong) {
+		t.Fatalf("NewUserName() = %v, want %v", err, ErrUserNameTooLong)
+	}
+}
+
+func TestNewUserNameInvalidChars(t *testing.T) {
+	tests := []struct {
+		name     string
+		userName string
+		wantErr  error
+	}{
+		{name: "zero width space", userName: "ali\u200bce", wantErr: ErrUserNameInvalidChars},
+		{name: "rtl override", userName: "\u202eecila", wantErr: ErrUserNameInvalidChars},
+		{name: "newline", userName: "alice\nbob", wantErr: ErrUserNameInvalidChars},
+		{name: "leading space", userName: " alice", wantErr: ErrUserNameInvalidChars},
+		{name: "trailing space", userName: "alice ", wantErr: ErrUserNameInvalidChars},
+		{name: "inner space", userName: "alice smith", wantErr: nil},
+		{name: "punctuation", userName: "alice.smith-jr_2", wantErr: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewUserName(tt.userName); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewUserName(%q) = %v, want %v", tt.userName, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewUserNameWithReserved(t *testing.T) {
+	custom := NewReservedUserNames([]string{"staff"})
+
+	tests := []struct {
+		name     string
+		userName string
+		reserved ReservedUserNames
+		wantErr  error
+	}{
+		{name: "reserved any case", userName: "Admin", reserved: DefaultReservedUserNames, wantErr: ErrUserNameReserved},
+		{name: "contains reserved", userName: "admin_fan", reserved: DefaultReservedUserNames, wantErr: nil},
+		{name: "custom list", userName: "STAFF", reserved: custom, wantErr: ErrUserNameReserved},
+		{name: "not in custom list", userName: "admin", reserved: custom, wantErr: nil},
+		{name: "nil list", userName: "admin", reserved: nil, wantErr: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewUserNameWithReserved(tt.userName, tt.reserved); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewUserNameWithReserved(%q) = %v, want %v", tt.userName, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUserNameEqualFold(t *testing.T) {
+	tests := []struct {
+		name string
+		a    UserName
+		b    UserName
+		want bool
+	}{
+		{name: "ascii case", a: "Alice", b: "alice", want: true},
+		{name: "different names", a: "alice", b: "alicia", want: false},
+		{name: "dotless i is not i", a: "KIM", b: "kım", want: false},
+		{name: "dotless i folds to itself", a: "kım", b: "KıM", want: true},
+		{name: "dotted capital i is not i", a: "İstanbul", b: "istanbul", want: false},
+		{name: "dotted capital i folds to i with dot", a: "İstanbul", b: "i\u0307stanbul", want: true},
+		{name: "decomposed encoding", a: "Café", b: "cafe\u0301", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.EqualFold(tt.b); got != tt.want {
+				t.Fatalf("%q.EqualFold(%q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserNameCanonical(t *testing.T) {
+	tests := []struct {
+		name     string
+		userName UserName
+		want     string
+	}{
+		{name: "ascii", userName: "Alice", want: "alice"},
+		{name: "dotless i", userName: "KIM", want: "kim"},
+		{name: "decomposed", userName: "CAFE\u0301", want: "café"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.userName.Canonical(); got != tt.want {
+				t.Fatalf("Canonical() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewUserIDFromString(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    UserID
+		wantErr error
+	}{
+		{name: "valid", s: "42", want: 42, wantErr: nil},
+		{name: "zero", s: "0", want: 0, wantErr: ErrUserIDZero},
+		{name: "negative", s: "-1", want: 0, wantErr: ErrUserIDInvalid},
+		{name: "not numeric", s: "abc", want: 0, wantErr: ErrUserIDInvalid},
+		{name: "overflow", s: "18446744073709551616", want: 0, wantErr: ErrUserIDInvalid},
+		{name: "empty", s: "", want: 0, wantErr: ErrUserIDInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewUserIDFromString(tt.s)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewUserIDFromString(%q) = %v, want %v", tt.s, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("NewUserIDFromString(%q) = %d, want %d", tt.s, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserIDJSON(t *testing.T) {
+	// 2^53 + 1 is the first integer a float64 cannot represent.
+	const aboveFloatPrecision UserID = 1<<53 + 1
+
+	tests := []struct {
+		name    string
+		data    string
+		want    UserID
+		wantErr error
+	}{
+		{name: "quoted above 2^53", data: `"9007199254740993"`, want: aboveFloatPrecision, wantErr: nil},
+		{name: "bare number", data: `42`, want: 42, wantErr: nil},
+		{name: "quoted zero", data: `"0"`, want: 0, wantErr: ErrUserIDZero},
+		{name: "bare zero", data: `0`, want: 0, wantErr: ErrUserIDZero},
+		{name: "not numeric", data: `"abc"`, want: 0, wantErr: ErrUserIDInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got UserID
+			if err := json.Unmarshal([]byte(tt.data), &got); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("json.Unmarshal(%s) = %v, want %v", tt.data, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("json.Unmarshal(%s) = %d, want %d", tt.data, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		data, err := json.Marshal(aboveFloatPrecision)
+		if err != nil {
+			t.Fatalf("json.Marshal() = %v", err)
+		}
+		if string(data) != `"9007199254740993"` {
+			t.Fatalf("json.Marshal() = %s, want a quoted string", data)
+		}
+
+		var got UserID
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("json.Unmarshal() = %v", err)
+		}
+		if got != aboveFloatPrecision {
+			t.Fatalf("round trip = %d, want %d", got, aboveFloatPrecision)
+		}
+		if got.String() != "9007199254740993" {
+			t.Fatalf("String() = %q", got.String())
+		}
+	})
+}
+
+func TestUserMarshalJSON(t *testing.T) {
+	saved := newTestUser(t, RoleAdmin)
+	unsaved, err := NewUser("bob", DummyHash, RoleUser)
+	if err != nil {
+		t.Fatalf("NewUser() = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		user *User
+		want string
+	}{
+		{name: "saved", user: saved, want: `{"id":"1","name":"alice","role":"admin"}`},
+		{name: "unsaved", user: unsaved, want: `{"name":"bob","role":"user"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.user)
+			if err != nil {
+				t.Fatalf("json.Marshal() = %v", err)
+			}
+			if string(data) != tt.want {
+				t.Fatalf("json.Marshal() = %s, want %s", data, tt.want)
+			}
+			if strings.Contains(strings.ToLower(string(data)), "password") || strings.Contains(string(data), string(DummyHash)) {
+				t.Fatalf("json.Marshal() = %s, leaks the password", data)
+			}
+		})
+	}
+}
+
+func TestUserUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantID  UserID
+		wantErr error
+	}{
+		{name: "valid", data: `{"id":"9007199254740993","name":"alice","role":"admin"}`, wantID: 1<<53 + 1, wantErr: nil},
+		{name: "without id", data: `{"name":"alice","role":"user"}`, wantID: 0, wantErr: nil},
+		{name: "invalid name", data: `{"name":"","role":"user"}`, wantErr: ErrUserNameEmpty},
+		{name: "invalid role", data: `{"name":"alice","role":"root"}`, wantErr: ErrUserRoleInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var user User
+			err := json.Unmarshal([]byte(tt.data), &user)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("json.Unmarshal(%s) = %v, want %v", tt.data, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if user.ID() != tt.wantID {
+				t.Fatalf("ID() = %d, want %d", user.ID(), tt.wantID)
+			}
+			if len(user.HashedPassword()) != 0 {
+				t.Fatalf("HashedPassword() = %q, want empty", user.HashedPassword())
+			}
+		})
+	}
+}
+
+// newTestUserWithPassword is newTestUser with a known password, hashed at
+// the minimum bcrypt cost to keep tests fast.
+func newTestUserWithPassword(t *testing.T, password Password, changedAt time.Time) *User {
+	t.Helper()
+
+	hashed, err := password.HashWithCost(bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("HashWithCost() = %v", err)
+	}
+
+	user, err := NewUserFromSource(1, "alice", string(hashed), string(RoleUser), changedAt)
+	if err != nil {
+		t.Fatalf("NewUserFromSource() = %v", err)
+	}
+
+	return user
+}
+
+func TestUserChangePassword(t *testing.T) {
+	const current Password = "Tx7!qLmZ"
+
+	tests := []struct {
+		name    string
+		current Password
+		next    Password
+		wantErr error
+	}{
+		{name: "success", current: current, next: "Zk9#wQpR", wantErr: nil},
+		{name: "wrong current password", current: "Wr0ng!pw", next: "Zk9#wQpR", wantErr: ErrHashedPasswordNotMatch},
+		{name: "weak new password", current: current, next: "Zk9#", wantErr: ErrPasswordTooShort},
+		{name: "new password without rules", current: current, next: "zkwqprtx", wantErr: ErrPasswordDoesNotFollowRule},
+		{name: "unchanged", current: current, next: current, wantErr: ErrPasswordUnchanged},
+		{name: "contains user name", current: current, next: "Alice9#w", wantErr: ErrPasswordContainsUsername},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newFakeClock()
+			user := newTestUserWithPassword(t, current, clock.Now().Add(-time.Hour))
+			before := user.HashedPassword()
+			changedAt := user.PasswordChangedAt()
+
+			err := user.ChangePasswordWithClock(tt.current, tt.next, clock)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ChangePassword() = %v, want %v", err, tt.wantErr)
+			}
+
+			if err != nil {
+				if string(user.HashedPassword()) != string(before) || !user.PasswordChangedAt().Equal(changedAt) {
+					t.Fatal("ChangePassword() changed the user on failure")
+				}
+				return
+			}
+
+			if err := user.HashedPassword().Verify(tt.next); err != nil {
+				t.Fatalf("HashedPassword().Verify(next) = %v", err)
+			}
+			if err := user.HashedPassword().Verify(tt.current); err == nil {
+				t.Fatal("HashedPassword().Verify(current) = nil after the change, want an error")
+			}
+			if !user.PasswordChangedAt().Equal(clock.Now()) {
+				t.Fatalf("PasswordChangedAt() = %v, want %v", user.PasswordChangedAt(), clock.Now())
+			}
+		})
+	}
+}
+
+func TestUserPasswordExpired(t *testing.T) {
+	const maxAge = 90 * 24 * time.Hour
+
+	clock := newFakeClock()
+	user := newTestUserWithPassword(t, "Tx7!qLmZ", clock.Now())
+
+	tests := []struct {
+		name   string
+		maxAge time.Duration
+		age    time.Duration
+		want   bool
+	}{
+		{name: "fresh", maxAge: maxAge, age: 0, want: false},
+		{name: "just before max age", maxAge: maxAge, age: maxAge - time.Second, want: false},
+		{name: "at max age", maxAge: maxAge, age: maxAge, want: true},
+		{name: "past max age", maxAge: maxAge, age: maxAge + time.Hour, want: true},
+		{name: "zero max age never expires", maxAge: 0, age: 10 * maxAge, want: false},
+		{name: "negative max age never expires", maxAge: -time.Hour, age: 10 * maxAge, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := user.PasswordExpired(tt.maxAge, clock.Now().Add(tt.age)); got != tt.want {
+				t.Fatalf("PasswordExpired(%v) at %v = %v, want %v", tt.maxAge, tt.age, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("change restarts the clock", func(t *testing.T) {
+		clock.Advance(maxAge)
+		if !user.PasswordExpired(maxAge, clock.Now()) {
+			t.Fatal("PasswordExpired() before the change = false, want true")
+		}
+
+		if err := user.ChangePasswordWithClock("Tx7!qLmZ", "Zk9#wQpR", clock); err != nil {
+			t.Fatalf("ChangePasswordWithClock() = %v", err)
+		}
+		if user.PasswordExpired(maxAge, clock.Now()) {
+			t.Fatal("PasswordExpired() after the change = true, want false")
+		}
+	})
+}
+
+func TestUserNameLooksLikeEmail(t *testing.T) {
+	tests := []struct {
+		name string
+		v    UserName
+		want bool
+	}{
+		{name: "email", v: "alice@example.com", want: true},
+		{name: "subdomain", v: "alice@mail.example.co.uk", want: true},
+		{name: "no domain", v: "alice@", want: false},
+		{name: "no at", v: "alice", want: false},
+		{name: "no local part", v: "@example.com", want: false},
+		{name: "two ats", v: "alice@bob@example.com", want: false},
+		{name: "no dot in domain", v: "alice@localhost", want: false},
+		{name: "leading dot in domain", v: "alice@.com", want: false},
+		{name: "trailing dot in domain", v: "alice@example.", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.v.LooksLikeEmail(); got != tt.want {
+				t.Fatalf("LooksLikeEmail(%q) = %v, want %v", tt.v, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewPasswordLength(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		wantErr  error
+		wantMsg  string
+	}{
+		{name: "one below min", password: "Xq7!kLm", wantErr: ErrPasswordTooShort, wantMsg: "password: too short (at least 8 characters)"},
+		{name: "min", password: "Xq7!kLmQ", wantErr: nil},
+		{name: "max", password: "Xq7!kLmQ2#vRxT9$", wantErr: nil},
+		{name: "one above max", password: "Xq7!kLmQ2#vRxT9$w", wantErr: ErrPasswordTooLong, wantMsg: "password: too long (at most 16 characters)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPassword(tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewPassword(%q) = %v, want %v", tt.password, err, tt.wantErr)
+			}
+			if err != nil && err.Error() != tt.wantMsg {
+				t.Fatalf("NewPassword(%q) message = %q, want %q", tt.password, err.Error(), tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestPasswordErrorsMatchErrPassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		wantErr  error
+	}{
+		{name: "empty", password: "", wantErr: ErrPasswordEmpty},
+		{name: "too short", password: "Xq7!", wantErr: ErrPasswordTooShort},
+		{name: "too long", password: "Xq7!kLmQ2#vRxT9$w", wantErr: ErrPasswordTooLong},
+		{name: "rules", password: "xqkwlmqz", wantErr: ErrPasswordDoesNotFollowRule},
+		{name: "low entropy", password: "Xq7!aaaa", wantErr: ErrPasswordLowEntropy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPassword(tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewPassword(%q) = %v, want %v", tt.password, err, tt.wantErr)
+			}
+			if !errors.Is(err, ErrPassword) {
+				t.Fatalf("errors.Is(%v, ErrPassword) = false, want true", err)
+			}
+		})
+	}
+
+	t.Run("other field", func(t *testing.T) {
+		if _, err := NewUserName(""); errors.Is(err, ErrPassword) {
+			t.Fatalf("errors.Is(%v, ErrPassword) = true, want false", err)
+		}
+	})
+}
+
+func TestUserMetaDataFingerprint(t *testing.T) {
+	const (
+		chrome114Mac = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/114.0.0.0 Safari/537.36"
+		chrome115Mac = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/115.0.0.0 Safari/537.36"
+		chromeWin    = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/114.0.0.0 Safari/537.36"
+		firefoxMac   = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10.15; rv:109.0) Gecko/20100101 Firefox/115.0"
+	)
+
+	fingerprint := func(t *testing.T, userAgent UserAgent, clientIp ClientIp) string {
+		t.Helper()
+
+		meta, err := NewUserMetadata(userAgent, clientIp)
+		if err != nil {
+			t.Fatalf("NewUserMetadata() = %v", err)
+		}
+
+		return meta.Fingerprint()
+	}
+
+	base := fingerprint(t, chrome114Mac, "203.0.113.42")
+
+	tests := []struct {
+		name      string
+		userAgent UserAgent
+		clientIp  ClientIp
+		wantSame  bool
+	}{
+		{name: "same login", userAgent: chrome114Mac, clientIp: "203.0.113.42", wantSame: true},
+		{name: "browser version bump", userAgent: chrome115Mac, clientIp: "203.0.113.42", wantSame: true},
+		{name: "same subnet", userAgent: chrome114Mac, clientIp: "203.0.113.7", wantSame: true},
+		{name: "different os", userAgent: chromeWin, clientIp: "203.0.113.42", wantSame: false},
+		{name: "different browser", userAgent: firefoxMac, clientIp: "203.0.113.42", wantSame: false},
+		{name: "different subnet", userAgent: chrome114Mac, clientIp: "198.51.100.42", wantSame: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fingerprint(t, tt.userAgent, tt.clientIp); (got == base) != tt.wantSame {
+				t.Fatalf("Fingerprint() equal to the base = %v, want %v", got == base, tt.wantSame)
+			}
+		})
+	}
+
+	t.Run("ipv6 /48", func(t *testing.T) {
+		a := fingerprint(t, chrome114Mac, "2001:db8:1:a::1")
+		b := fingerprint(t, chrome114Mac, "2001:db8:1:ff::2")
+		c := fingerprint(t, chrome114Mac, "2001:db8:2::1")
+		if a != b || a == c {
+			t.Fatalf("Fingerprint() same /48 equal = %v, other /48 equal = %v, want true, false", a == b, a == c)
+		}
+	})
+}
+
+func TestUserToDTO(t *testing.T) {
+	const password = "Tx7!qLmZ"
+
+	saved := newTestUserWithPassword(t, password, time.Now())
+	savedAdmin, err := NewUserFromSource(1234567890123, "bob", string(DummyHash), string(RoleAdmin), time.Now())
+	if err != nil {
+		t.Fatalf("NewUserFromSource() = %v", err)
+	}
+	unsaved, err := NewUserForCreate("carol", password, string(RoleUser), DefaultPasswordPolicy)
+	if err != nil {
+		t.Fatalf("NewUserForCreate() = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		user *User
+		want UserDTO
+	}{
+		{name: "saved user", user: saved, want: UserDTO{ID: "1", Name: "alice", Role: "user"}},
+		{name: "large id", user: savedAdmin, want: UserDTO{ID: "1234567890123", Name: "bob", Role: "admin"}},
+		{name: "not saved yet", user: unsaved, want: UserDTO{ID: "", Name: "carol", Role: "user"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.user.ToDTO()
+			if got != tt.want {
+				t.Fatalf("ToDTO() = %+v, want %+v", got, tt.want)
+			}
+
+			encoded, err := json.Marshal(got)
+			if err != nil {
+				t.Fatalf("json.Marshal() = %v", err)
+			}
+			hashed := string(tt.user.HashedPassword())
+			if strings.Contains(string(encoded), password) || strings.Contains(string(encoded), hashed) {
+				t.Fatalf("json.Marshal(ToDTO()) = %s, want no password or hash", encoded)
+			}
+		})
+	}
+}
@@ -16,8 +16,14 @@ var (
 	ErrDuplicateUserName              = errors.New("duplicate: user name")
 	ErrValidationUserPasswordInvalid  = errors.New("validation: user password: invalid")
 	ErrNotFoundUser                   = errors.New("not found: user")
+	ErrNotFoundSession                = errors.New("not found: session")
 	ErrCreateAccessToken              = errors.New("failed to create access token")
 	ErrCreateRefreshToken             = errors.New("failed to create refresh token")
+	ErrTooManyLoginAttempts           = errors.New("too many login attempts")
+
+	// ErrInvalidCredentials is the only failure Login reports for a known
+	// name, so responses do not reveal which names are registered.
+	ErrInvalidCredentials = errors.New("unauthenticated: invalid user name or password")
 )
 
 // INVALID_ARGUMENT = 3
@@ -64,7 +70,7 @@ func serverError(err error) error {
 	}
 
 	if _, ok := status.FromError(err); !ok {
-		status.New(codes.Internal, err.Error()).Err()
+		return status.New(codes.Internal, err.Error()).Err()
 	}
 
 	return err
@@ -0,0 +1,31 @@
+package gapi
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/azusaanson/invest-api/domain"
+)
+
+func TestServerError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode codes.Code
+	}{
+		{name: "hash cost misconfigured", err: errors.WithStack(domain.ErrPasswordHashCostInvalid), wantCode: codes.Internal},
+		{name: "plain error", err: errors.New("boom"), wantCode: codes.Internal},
+		{name: "status kept", err: status.Error(codes.Unavailable, "db down"), wantCode: codes.Unavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := status.Code(serverError(tt.err)); got != tt.wantCode {
+				t.Fatalf("serverError() code = %v, want %v", got, tt.wantCode)
+			}
+		})
+	}
+}
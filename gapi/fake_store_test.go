@@ -0,0 +1,142 @@
+package gapi
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/azusaanson/invest-api/db/db"
+	"github.com/azusaanson/invest-api/domain"
+)
+
+// fakeStore keeps users and sessions in memory and hands out copies, like rows read
+// from the database. Methods the tests do not need are left to the embedded
+// nil interface and panic if called.
+type fakeStore struct {
+	db.StoreInterface
+
+	mu       sync.Mutex
+	users    map[string]*domain.User
+	sessions map[domain.SessionID]*domain.Session
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		users:    map[string]*domain.User{},
+		sessions: map[domain.SessionID]*domain.Session{},
+	}
+}
+
+func (f *fakeStore) addUser(user *domain.User) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.users[user.Name().Canonical()] = user
+}
+
+func (f *fakeStore) GetUserByName(
+	_ context.Context,
+	name domain.UserName,
+	_ ...db.QueryOption,
+) (*domain.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	user, ok := f.users[name.Canonical()]
+	if !ok {
+		return nil, errors.WithStack(db.ErrUserNotFound)
+	}
+
+	return user, nil
+}
+
+func (f *fakeStore) GetSessionByRefreshToken(
+	_ context.Context,
+	refreshToken domain.RefreshToken,
+) (*domain.Session, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, session := range f.sessions {
+		if string(session.RefreshTokenHash()) == refreshToken.Hash() {
+			return copySession(session, session.IsBlocked(), session.IsRotated())
+		}
+	}
+
+	return nil, errors.WithStack(db.ErrSessionNotFound)
+}
+
+func (f *fakeStore) CreateSession(_ context.Context, session *domain.Session) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	stored, err := copySession(session, session.IsBlocked(), session.IsRotated())
+	if err != nil {
+		return err
+	}
+	f.sessions[session.ID()] = stored
+
+	return nil
+}
+
+func (f *fakeStore) RotateSession(_ context.Context, old *domain.Session, session *domain.Session) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	stored, ok := f.sessions[old.ID()]
+	if !ok || bool(stored.IsRotated()) {
+		return errors.WithStack(domain.ErrRefreshTokenReuse)
+	}
+
+	rotated, err := copySession(stored, stored.IsBlocked(), true)
+	if err != nil {
+		return err
+	}
+	f.sessions[old.ID()] = rotated
+
+	next, err := copySession(session, session.IsBlocked(), session.IsRotated())
+	if err != nil {
+		return err
+	}
+	f.sessions[session.ID()] = next
+
+	return nil
+}
+
+func (f *fakeStore) RevokeAllSessions(_ context.Context, userID domain.UserID) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	revoked := 0
+	for id, session := range f.sessions {
+		if session.UserID() != userID || bool(session.IsBlocked()) || session.IsExpired(time.Now()) {
+			continue
+		}
+
+		blocked, err := copySession(session, true, session.IsRotated())
+		if err != nil {
+			return 0, err
+		}
+		f.sessions[id] = blocked
+		revoked++
+	}
+
+	return revoked, nil
+}
+
+func copySession(session *domain.Session, isBlocked domain.IsBlocked, isRotated domain.IsRotated) (*domain.Session, error) {
+	return domain.NewSessionFromSource(
+		session.ID().String(),
+		uint64(session.UserID()),
+		string(session.RefreshTokenHash()),
+		string(session.UserAgent()),
+		string(session.ClientIp()),
+		bool(isBlocked),
+		bool(isRotated),
+		time.Time(session.ExpiresAt()),
+		time.Time(session.CreatedAt()),
+		time.Time(session.LastSeenAt()),
+	)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/azusaanson/invest-api/domain"
 	"github.com/rs/zerolog/log"
 
 	"google.golang.org/grpc"
@@ -36,3 +37,22 @@ func Logger(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, ha
 
 	return result, err
 }
+
+// auditLogger writes audit events to the application log.
+type auditLogger struct{}
+
+func (auditLogger) Log(ctx context.Context, event domain.AuditEvent) {
+	logger := log.Info()
+	if event.Outcome != domain.AuditOutcomeSuccess {
+		logger = log.Warn()
+	}
+
+	logger.Str("audit_action", string(event.Action)).
+		Uint64("user_id", uint64(event.UserID)).
+		Time("timestamp", event.Timestamp).
+		Str("client_ip", string(event.ClientIp)).
+		Str("user_agent", string(event.UserAgent)).
+		Str("outcome", string(event.Outcome)).
+		Str("reason", event.Reason).
+		Msg("audit")
+}
@@ -4,11 +4,14 @@ import (
 	"context"
 	"time"
 
+	"github.com/azusaanson/invest-api/db/db"
 	"github.com/azusaanson/invest-api/domain"
 	"github.com/azusaanson/invest-api/proto/pb"
 	"github.com/pkg/errors"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -17,72 +20,131 @@ func (server *Server) Login(ctx context.Context, req *pb.LoginRequest) (*pb.Logi
 		return nil, invalidArgumentError(violations)
 	}
 
-	name, _ := domain.NewUserName(req.GetName())
-	password, _ := domain.NewPassword(req.GetPassword())
+	name, _ := domain.NewUserNameWithReserved(req.GetName(), nil)
+	password, _ := domain.NewLoginPassword(req.GetPassword())
 
-	user, err := server.store.GetUserByName(ctx, name)
+	userMetaData, err := server.extractMetadata(ctx)
 	if err != nil {
 		return nil, serverError(err)
 	}
-	if user == nil {
-		return nil, clientError(codes.NotFound, ErrNotFoundUser)
+
+	// Requests without a known address, such as in-process calls, share no
+	// bucket and are not limited.
+	if clientIp := userMetaData.ClientIp(); clientIp != "" && !server.loginLimiter.Allow(clientIp) {
+		return nil, clientError(codes.ResourceExhausted, ErrTooManyLoginAttempts)
+	}
+
+	user, err := server.store.GetUserByName(ctx, name)
+	if err != nil && !errors.Is(err, db.ErrUserNotFound) {
+		return nil, serverError(err)
+	}
+	if user != nil {
+		if err := server.lockoutPolicy.Check(user.ID()); err != nil {
+			if errors.Is(err, domain.ErrAccountLocked) {
+				// Answer like any failed login, after as long, so a lock
+				// does not reveal that the name exists.
+				_ = domain.VerifyUserPassword(nil, password)
+				server.auditLogin(ctx, user.ID(), userMetaData, domain.AuditOutcomeFailure, err.Error())
+				return nil, clientError(codes.Unauthenticated, ErrInvalidCredentials)
+			}
+			return nil, serverError(err)
+		}
+	}
+
+	if err := domain.VerifyUserPassword(user, password); err != nil {
+		if user == nil {
+			server.auditLogin(ctx, 0, userMetaData, domain.AuditOutcomeFailure, ErrNotFoundUser.Error())
+			return nil, clientError(codes.Unauthenticated, ErrInvalidCredentials)
+		}
+		server.auditLogin(ctx, user.ID(), userMetaData, domain.AuditOutcomeFailure, ErrValidationUserPasswordInvalid.Error())
+		if err := server.lockoutPolicy.RecordFailure(user.ID()); err != nil {
+			return nil, serverError(err)
+		}
+		return nil, clientError(codes.Unauthenticated, ErrInvalidCredentials)
+	}
+
+	if err := server.lockoutPolicy.RecordSuccess(user.ID()); err != nil {
+		return nil, serverError(err)
 	}
 
-	if err := user.HashedPassword().Verify(password); err != nil {
-		return nil, clientError(codes.Unauthenticated, ErrValidationUserPasswordInvalid)
+	upgraded, err := user.UpgradePasswordHash(password)
+	if err != nil {
+		return nil, serverError(err)
+	}
+	if upgraded {
+		if err := server.store.UpdateUser(ctx, user); err != nil {
+			return nil, serverError(err)
+		}
 	}
 
 	accessToken, accessPayload, err := server.tokenMaker.CreateToken(
-		user.ID(),
-		server.config.AccessTokenDuration,
+		user,
+		server.authConfig.AccessTokenTTL(),
 	)
 	if err != nil {
 		return nil, serverError(errors.Wrap(ErrCreateAccessToken, err.Error()))
 	}
 
-	refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(
-		user.ID(),
-		server.config.RefreshTokenDuration,
-	)
+	refreshToken, err := domain.GenerateRefreshToken()
 	if err != nil {
 		return nil, serverError(errors.Wrap(ErrCreateRefreshToken, err.Error()))
 	}
 
-	userMetaData, err := server.extractMetadata(ctx)
+	refreshTokenExpiresAt, _ := domain.NewExpiresAt(time.Now().Add(server.authConfig.SessionTTL()))
+
+	sessionID, err := domain.NewSessionID()
 	if err != nil {
 		return nil, serverError(err)
 	}
 
-	session, err := domain.NewSession(refreshPayload.ID, user.ID(), refreshToken, refreshPayload.ExpiresAt, userMetaData)
+	session, err := domain.NewSession(sessionID, user.ID(), refreshToken, refreshTokenExpiresAt, userMetaData)
 	if err != nil {
 		return nil, serverError(err)
 	}
 
-	if err = server.store.CreateSession(ctx, session); err != nil {
+	if err = server.store.CreateSessionWithLimit(ctx, session, server.sessionLimit); err != nil {
 		return nil, serverError(err)
 	}
 
+	server.auditLogin(ctx, user.ID(), userMetaData, domain.AuditOutcomeSuccess, "")
+
+	if user.PasswordExpired(server.config.PasswordMaxAge, time.Now()) {
+		if err := grpc.SetHeader(ctx, metadata.Pairs(passwordExpiredHeader, "true")); err != nil {
+			return nil, serverError(err)
+		}
+	}
+
 	res := &pb.LoginResponse{
 		User:                  toUserResponse(user),
-		SessionId:             session.UUID().ToString(),
+		SessionId:             session.ID().String(),
 		AccessToken:           string(accessToken),
 		RefreshToken:          string(refreshToken),
 		AccessTokenExpiresAt:  timestamppb.New(time.Time(accessPayload.ExpiresAt)),
-		RefreshTokenExpiresAt: timestamppb.New(time.Time(refreshPayload.ExpiresAt)),
+		RefreshTokenExpiresAt: timestamppb.New(time.Time(session.ExpiresAt())),
 	}
 	return res, nil
 }
 
+func (server *Server) auditLogin(
+	ctx context.Context,
+	userID domain.UserID,
+	meta *domain.UserMetaData,
+	outcome domain.AuditOutcome,
+	reason string,
+) {
+	server.auditLogger.Log(ctx, domain.NewAuditEvent(domain.AuditActionLogin, userID, time.Now(), meta, outcome, reason))
+}
+
 func validateLoginRequest(req *pb.LoginRequest) (violations []*errdetails.BadRequest_FieldViolation) {
 	if req.GetName() == "" {
 		violations = append(violations, fieldViolation("name", ErrValidationUserNameRequired))
-	} else if _, err := domain.NewUserName(req.GetName()); err != nil {
+	} else if _, err := domain.NewUserNameWithReserved(req.GetName(), nil); err != nil {
 		violations = append(violations, fieldViolation("name", err))
 	}
 
 	if req.GetPassword() == "" {
 		violations = append(violations, fieldViolation("password", ErrValidationUserPasswordRequired))
-	} else if _, err := domain.NewPassword(req.GetPassword()); err != nil {
+	} else if _, err := domain.NewLoginPassword(req.GetPassword()); err != nil {
 		violations = append(violations, fieldViolation("password", err))
 	}
 
@@ -90,34 +152,38 @@ func validateLoginRequest(req *pb.LoginRequest) (violations []*errdetails.BadReq
 }
 
 func (server *Server) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.CreateUserResponse, error) {
-	if violations := validateCreateUserRequest(req); violations != nil {
+	if violations := validateCreateUserRequest(req, server.passwordPolicy); violations != nil {
 		return nil, invalidArgumentError(violations)
 	}
 
 	name, _ := domain.NewUserName(req.GetName())
-	password, _ := domain.NewPassword(req.GetPassword())
-	role, _ := domain.NewUserRole(req.GetRole())
 
-	userExist, err := server.store.GetUserByName(ctx, name)
-	if err != nil {
-		return nil, serverError(err)
-	}
-	if userExist != nil {
+	_, err := server.store.GetUserByName(ctx, name)
+	if err == nil {
 		return nil, clientError(codes.AlreadyExists, ErrDuplicateUserName)
 	}
+	if !errors.Is(err, db.ErrUserNotFound) {
+		return nil, serverError(err)
+	}
 
-	user, err := domain.NewUser(name, password.Hash(), role)
+	user, err := domain.NewUserForCreate(req.GetName(), req.GetPassword(), req.GetRole(), server.passwordPolicy)
+	if errors.Is(err, domain.ErrValidation) {
+		return nil, clientError(codes.InvalidArgument, err)
+	}
 	if err != nil {
 		return nil, serverError(err)
 	}
 
-	if err = server.store.ExecTx(ctx, func(ctx context.Context) error {
-		if err := server.store.CreateUser(ctx, user); err != nil {
+	if err = server.store.ExecTx(ctx, func(txRepo db.Repositories) error {
+		if _, err := txRepo.CreateUser(ctx, user); err != nil {
 			return errors.WithStack(err)
 		}
 
 		return nil
 	}); err != nil {
+		if errors.Is(err, db.ErrUserNameTaken) {
+			return nil, clientError(codes.AlreadyExists, ErrDuplicateUserName)
+		}
 		return nil, serverError(err)
 	}
 
@@ -127,7 +193,10 @@ func (server *Server) CreateUser(ctx context.Context, req *pb.CreateUserRequest)
 	return res, nil
 }
 
-func validateCreateUserRequest(req *pb.CreateUserRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+func validateCreateUserRequest(
+	req *pb.CreateUserRequest,
+	policy domain.PasswordPolicy,
+) (violations []*errdetails.BadRequest_FieldViolation) {
 	if req.GetName() == "" {
 		violations = append(violations, fieldViolation("name", ErrValidationUserNameRequired))
 	} else if _, err := domain.NewUserName(req.GetName()); err != nil {
@@ -136,8 +205,12 @@ func validateCreateUserRequest(req *pb.CreateUserRequest) (violations []*errdeta
 
 	if req.GetPassword() == "" {
 		violations = append(violations, fieldViolation("password", ErrValidationUserPasswordRequired))
-	} else if _, err := domain.NewPassword(req.GetPassword()); err != nil {
+	} else if password, err := policy.Validate(req.GetPassword()); err != nil {
 		violations = append(violations, fieldViolation("password", err))
+	} else if name, err := domain.NewUserName(req.GetName()); err == nil {
+		if err := password.ValidateAgainstUser(name); err != nil {
+			violations = append(violations, fieldViolation("password", err))
+		}
 	}
 
 	if req.GetRole() == "" {
@@ -150,8 +223,10 @@ func validateCreateUserRequest(req *pb.CreateUserRequest) (violations []*errdeta
 }
 
 func toUserResponse(user *domain.User) *pb.User {
+	dto := user.ToDTO()
+
 	return &pb.User{
-		Name: string(user.Name()),
-		Role: string(user.Role()),
+		Name: dto.Name,
+		Role: dto.Role,
 	}
 }
@@ -0,0 +1,133 @@
+package gapi
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/azusaanson/invest-api/domain"
+	"github.com/azusaanson/invest-api/proto/pb"
+)
+
+func TestCreateUserInvalidInput(t *testing.T) {
+	// The fake store has no user methods, so reaching the store panics.
+	server := &Server{store: newFakeStore(), passwordPolicy: domain.DefaultPasswordPolicy}
+
+	tests := []struct {
+		name string
+		req  *pb.CreateUserRequest
+	}{
+		{name: "short password", req: &pb.CreateUserRequest{Name: "alice", Password: "Tx7!", Role: string(domain.RoleUser)}},
+		{name: "password without rules", req: &pb.CreateUserRequest{Name: "alice", Password: "abcdefghij", Role: string(domain.RoleUser)}},
+		{name: "password with user name", req: &pb.CreateUserRequest{Name: "alice", Password: "Alice7!q", Role: string(domain.RoleUser)}},
+		{name: "invalid role", req: &pb.CreateUserRequest{Name: "alice", Password: "Tx7!qLmZ", Role: "root"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := server.CreateUser(context.Background(), tt.req)
+			if status.Code(err) != codes.InvalidArgument {
+				t.Fatalf("CreateUser() = %v, want %v", err, codes.InvalidArgument)
+			}
+		})
+	}
+}
+
+func newTestLoginServer(t *testing.T) (*Server, *domain.MemoryAuditLogger) {
+	t.Helper()
+
+	lockoutPolicy, err := domain.NewLockoutPolicy(5, time.Minute, time.Hour, domain.NewInMemoryLoginAttemptStore(), domain.SystemClock{})
+	if err != nil {
+		t.Fatalf("NewLockoutPolicy() = %v", err)
+	}
+	loginLimiter, err := domain.NewTokenBucketRateLimiter(100, 100, domain.SystemClock{})
+	if err != nil {
+		t.Fatalf("NewTokenBucketRateLimiter() = %v", err)
+	}
+
+	store := newFakeStore()
+	auditLogger := domain.NewMemoryAuditLogger()
+
+	hashedPassword, err := domain.Password("Tx7!qLmZ").Hash()
+	if err != nil {
+		t.Fatalf("Hash() = %v", err)
+	}
+	user, err := domain.NewUserFromSource(1, "alice", string(hashedPassword), string(domain.RoleUser), time.Now())
+	if err != nil {
+		t.Fatalf("NewUserFromSource() = %v", err)
+	}
+	store.addUser(user)
+
+	server := &Server{
+		store:         store,
+		lockoutPolicy: lockoutPolicy,
+		loginLimiter:  loginLimiter,
+		auditLogger:   auditLogger,
+	}
+
+	return server, auditLogger
+}
+
+func TestLoginAuditFailure(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(userAgentHeader, "Mozilla/5.0"))
+	ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("203.0.113.7"), Port: 51234}})
+
+	tests := []struct {
+		name       string
+		req        *pb.LoginRequest
+		wantUserID domain.UserID
+		wantReason string
+	}{
+		{
+			name:       "wrong password",
+			req:        &pb.LoginRequest{Name: "alice", Password: "Wr0ng!pass"},
+			wantUserID: 1,
+			wantReason: ErrValidationUserPasswordInvalid.Error(),
+		},
+		{
+			name:       "unknown user",
+			req:        &pb.LoginRequest{Name: "mallory", Password: "Tx7!qLmZ"},
+			wantUserID: 0,
+			wantReason: ErrNotFoundUser.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, auditLogger := newTestLoginServer(t)
+
+			_, err := server.Login(ctx, tt.req)
+			if status.Code(err) != codes.Unauthenticated {
+				t.Fatalf("Login() = %v, want %v", err, codes.Unauthenticated)
+			}
+
+			events := auditLogger.Events()
+			if len(events) != 1 {
+				t.Fatalf("Login() logged %d audit events, want 1", len(events))
+			}
+			event := events[0]
+
+			if event.Action != domain.AuditActionLogin || event.Outcome != domain.AuditOutcomeFailure {
+				t.Fatalf("event = %s %s, want %s %s", event.Action, event.Outcome, domain.AuditActionLogin, domain.AuditOutcomeFailure)
+			}
+			if event.UserID != tt.wantUserID {
+				t.Fatalf("event.UserID = %d, want %d", event.UserID, tt.wantUserID)
+			}
+			if event.ClientIp != "203.0.113.7" || event.UserAgent != "Mozilla/5.0" {
+				t.Fatalf("event metadata = %q %q, want %q %q", event.ClientIp, event.UserAgent, "203.0.113.7", "Mozilla/5.0")
+			}
+			if event.Reason != tt.wantReason {
+				t.Fatalf("event.Reason = %q, want %q", event.Reason, tt.wantReason)
+			}
+			if time.Since(event.Timestamp) > time.Minute {
+				t.Fatalf("event.Timestamp = %v, want about now", event.Timestamp)
+			}
+		})
+	}
+}
@@ -3,20 +3,30 @@ package gapi
 import (
 	"context"
 	"fmt"
+	"net/netip"
+	"strings"
 
 	"github.com/azusaanson/invest-api/config"
 	"github.com/azusaanson/invest-api/db/db"
 	"github.com/azusaanson/invest-api/domain"
 	"github.com/azusaanson/invest-api/proto/pb"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
 )
 
 type Server struct {
 	pb.UnimplementedInvestServer
-	config     config.Config
-	store      db.StoreInterface
-	tokenMaker domain.TokenMaker
+	config         config.Config
+	store          db.StoreInterface
+	tokenMaker     *domain.RevocableTokenMaker
+	passwordPolicy domain.PasswordPolicy
+	authConfig     domain.AuthConfig
+	sessionLimit   *domain.SessionLimit
+	lockoutPolicy  *domain.LockoutPolicy
+	loginLimiter   domain.RateLimiter
+	auditLogger    domain.AuditLogger
+	trustedProxies []netip.Prefix
 }
 
 func NewServer(config config.Config, store db.StoreInterface) (*Server, error) {
@@ -25,15 +35,63 @@ func NewServer(config config.Config, store db.StoreInterface) (*Server, error) {
 		return nil, serverError(err)
 	}
 
-	tokenMaker, err := domain.NewPasetoMaker(symmetricKey)
+	baseTokenMaker, err := domain.NewTokenMaker(config.TokenMaker, symmetricKey)
 	if err != nil {
 		return nil, serverError(fmt.Errorf("cannot create token maker: %w", err))
 	}
+	tokenMaker := domain.NewRevocableTokenMaker(baseTokenMaker, domain.NewInMemoryRevocationStore(domain.SystemClock{}))
+
+	authConfig, err := domain.NewAuthConfig(
+		config.AccessTokenDuration,
+		config.RefreshTokenDuration,
+		config.SessionDuration,
+	)
+	if err != nil {
+		return nil, serverError(fmt.Errorf("cannot create auth config: %w", err))
+	}
+
+	sessionLimit, err := domain.NewSessionLimit(config.MaxSessionsPerUser, domain.EvictOldestSessionPolicy{})
+	if err != nil {
+		return nil, serverError(fmt.Errorf("cannot create session limit: %w", err))
+	}
+
+	lockoutPolicy, err := domain.NewLockoutPolicy(
+		config.LockoutThreshold,
+		config.LockoutDuration,
+		config.LockoutMaxDuration,
+		domain.NewInMemoryLoginAttemptStore(),
+		domain.SystemClock{},
+	)
+	if err != nil {
+		return nil, serverError(fmt.Errorf("cannot create lockout policy: %w", err))
+	}
+
+	loginLimiter, err := domain.NewTokenBucketRateLimiter(config.LoginRatePerSecond, config.LoginRateBurst, domain.SystemClock{})
+	if err != nil {
+		return nil, serverError(fmt.Errorf("cannot create login rate limiter: %w", err))
+	}
+
+	passwordPolicy, err := domain.PasswordPolicyForCharset(config.PasswordCharset)
+	if err != nil {
+		return nil, serverError(fmt.Errorf("cannot select password policy: %w", err))
+	}
+
+	trustedProxies, err := parseTrustedProxies(config.TrustedProxies)
+	if err != nil {
+		return nil, serverError(fmt.Errorf("cannot parse trusted proxies: %w", err))
+	}
 
 	server := &Server{
-		config:     config,
-		store:      store,
-		tokenMaker: tokenMaker,
+		config:         config,
+		store:          store,
+		tokenMaker:     tokenMaker,
+		passwordPolicy: passwordPolicy,
+		authConfig:     authConfig,
+		sessionLimit:   sessionLimit,
+		lockoutPolicy:  lockoutPolicy,
+		loginLimiter:   loginLimiter,
+		auditLogger:    auditLogger{},
+		trustedProxies: trustedProxies,
 	}
 
 	return server, nil
@@ -43,11 +101,15 @@ const (
 	grpcGatewayUserAgentHeader = "grpcgateway-user-agent"
 	userAgentHeader            = "user-agent"
 	xForwardedForHeader        = "x-forwarded-for"
+
+	// passwordExpiredHeader is sent on login so the client can force a change.
+	passwordExpiredHeader = "x-password-expired"
 )
 
 func (server *Server) extractMetadata(ctx context.Context) (*domain.UserMetaData, error) {
 	var userAgent domain.UserAgent
-	var clientIp domain.ClientIp
+	var forwardedFor []string
+	var remoteAddr string
 	var err error = nil
 
 	if md, ok := metadata.FromIncomingContext(ctx); ok {
@@ -59,13 +121,19 @@ func (server *Server) extractMetadata(ctx context.Context) (*domain.UserMetaData
 			userAgent, err = domain.NewUserAgent(userAgents[0])
 		}
 
-		if clientIPs := md.Get(xForwardedForHeader); len(clientIPs) > 0 {
-			clientIp, err = domain.NewClientIp(clientIPs[0])
-		}
+		forwardedFor = md.Get(xForwardedForHeader)
 	}
 
 	if p, ok := peer.FromContext(ctx); ok {
-		clientIp, err = domain.NewClientIp(p.Addr.String())
+		remoteAddr = p.Addr.String()
+	}
+
+	var clientIp domain.ClientIp
+	if remoteAddr != "" {
+		clientIp, err = domain.ClientIpFromForwardedFor(strings.Join(forwardedFor, ","), server.trustedProxies, remoteAddr)
+		if err != nil {
+			return nil, clientError(codes.InvalidArgument, err)
+		}
 	}
 
 	userMetadata, err := domain.NewUserMetadata(userAgent, clientIp)
@@ -75,3 +143,20 @@ func (server *Server) extractMetadata(ctx context.Context) (*domain.UserMetaData
 
 	return userMetadata, nil
 }
+
+func parseTrustedProxies(v string) ([]netip.Prefix, error) {
+	prefixes := []netip.Prefix{}
+	for _, entry := range strings.Split(v, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, prefix)
+	}
+
+	return prefixes, nil
+}
@@ -0,0 +1,65 @@
+package gapi
+
+import (
+	"context"
+	"time"
+
+	"github.com/azusaanson/invest-api/db/db"
+	"github.com/azusaanson/invest-api/domain"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+)
+
+// rotateSession exchanges a refresh token for a new session and refresh token.
+// A replayed token blocks every session of its user.
+func (server *Server) rotateSession(ctx context.Context, refreshToken domain.RefreshToken) (*domain.Session, domain.RefreshToken, error) {
+	old, err := server.store.GetSessionByRefreshToken(ctx, refreshToken)
+	if errors.Is(err, db.ErrSessionNotFound) {
+		return nil, "", clientError(codes.Unauthenticated, ErrNotFoundSession)
+	}
+	if err != nil {
+		return nil, "", serverError(err)
+	}
+	// The lookup matched by hash in SQL; verify again in constant time so the
+	// decision does not depend on how the database compares strings.
+	if !refreshToken.VerifyAgainst(string(old.RefreshTokenHash())) {
+		return nil, "", clientError(codes.Unauthenticated, ErrNotFoundSession)
+	}
+
+	session, newRefreshToken, err := domain.RotateSession(old, time.Now(), server.authConfig.RefreshTokenTTL())
+	if err == nil {
+		err = server.store.RotateSession(ctx, old, session)
+	}
+
+	if errors.Is(err, domain.ErrRefreshTokenReuse) {
+		if _, err := server.store.RevokeAllSessions(ctx, old.UserID()); err != nil {
+			return nil, "", serverError(err)
+		}
+		return nil, "", clientError(codes.Unauthenticated, err)
+	}
+	if errors.Is(err, domain.ErrSessionBlocked) || errors.Is(err, domain.ErrSessionExpired) {
+		return nil, "", clientError(codes.Unauthenticated, err)
+	}
+	if err != nil {
+		return nil, "", serverError(err)
+	}
+
+	return session, newRefreshToken, nil
+}
+
+// ListUserSessions backs the "your active sessions" page; currentID is the
+// session of the request, which gets flagged.
+func (server *Server) ListUserSessions(
+	ctx context.Context,
+	userID domain.UserID,
+	currentID domain.SessionID,
+) ([]domain.SessionView, error) {
+	now := time.Now()
+
+	sessions, err := server.store.ListActiveSessionsByUserID(ctx, userID, now)
+	if err != nil {
+		return nil, serverError(err)
+	}
+
+	return domain.NewSessionViews(sessions, currentID, now), nil
+}
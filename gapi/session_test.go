@@ -0,0 +1,87 @@
+package gapi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/azusaanson/invest-api/domain"
+)
+
+func newTestSessionServer(t *testing.T) (*Server, *fakeStore) {
+	t.Helper()
+
+	authConfig, err := domain.NewAuthConfig(15*time.Minute, 24*time.Hour, 0)
+	if err != nil {
+		t.Fatalf("NewAuthConfig() = %v", err)
+	}
+
+	store := newFakeStore()
+
+	return &Server{store: store, authConfig: authConfig}, store
+}
+
+func createTestSession(t *testing.T, store *fakeStore, userID domain.UserID) domain.RefreshToken {
+	t.Helper()
+
+	sessionID, err := domain.NewSessionID()
+	if err != nil {
+		t.Fatalf("NewSessionID() = %v", err)
+	}
+	refreshToken, err := domain.GenerateRefreshToken()
+	if err != nil {
+		t.Fatalf("GenerateRefreshToken() = %v", err)
+	}
+	metaData, err := domain.NewUserMetadata("Mozilla/5.0", "")
+	if err != nil {
+		t.Fatalf("NewUserMetadata() = %v", err)
+	}
+
+	session, err := domain.NewSession(sessionID, userID, refreshToken, domain.ExpiresAt(time.Now().Add(time.Hour)), metaData)
+	if err != nil {
+		t.Fatalf("NewSession() = %v", err)
+	}
+	if err := store.CreateSession(context.Background(), session); err != nil {
+		t.Fatalf("CreateSession() = %v", err)
+	}
+
+	return refreshToken
+}
+
+func TestRotateSessionReplay(t *testing.T) {
+	ctx := context.Background()
+	server, store := newTestSessionServer(t)
+
+	stolen := createTestSession(t, store, 1)
+	otherDevice := createTestSession(t, store, 1)
+	otherUser := createTestSession(t, store, 2)
+
+	_, rotated, err := server.rotateSession(ctx, stolen)
+	if err != nil {
+		t.Fatalf("rotateSession() = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		refreshToken domain.RefreshToken
+		wantCode     codes.Code
+	}{
+		// The first replay blocks the whole chain of the user ...
+		{name: "replayed token", refreshToken: stolen, wantCode: codes.Unauthenticated},
+		// ... so neither the rotated token nor another device can refresh.
+		{name: "rotated token", refreshToken: rotated, wantCode: codes.Unauthenticated},
+		{name: "other device", refreshToken: otherDevice, wantCode: codes.Unauthenticated},
+		{name: "other user", refreshToken: otherUser, wantCode: codes.OK},
+		{name: "unknown token", refreshToken: "unknown", wantCode: codes.Unauthenticated},
+	}
+
+	for _, tt := range tests {
+		_, _, err := server.rotateSession(ctx, tt.refreshToken)
+		if got := status.Code(err); got != tt.wantCode {
+			t.Fatalf("%s: rotateSession() = %v, want code %v", tt.name, err, tt.wantCode)
+		}
+	}
+}
@@ -1,11 +1,13 @@
 package main
 
 import (
+	"context"
 	"net"
 	"os"
 
 	"github.com/azusaanson/invest-api/config"
 	"github.com/azusaanson/invest-api/db/db"
+	"github.com/azusaanson/invest-api/domain"
 	"github.com/azusaanson/invest-api/gapi"
 	"github.com/azusaanson/invest-api/proto/pb"
 	_ "github.com/golang-migrate/migrate/source/file"
@@ -42,7 +44,24 @@ func main() {
 	// add later
 	//runDBMigration(config.MigrationURL, "mysql://"+dbSource)
 
-	store := db.NewStore(conn)
+	store := db.NewStore(conn, db.WithSessionTouchInterval(config.SessionTouchInterval))
+
+	if filled, err := db.BackfillCanonicalNames(context.Background(), conn); err != nil {
+		log.Fatal().Err(err).Msg("cannot backfill canonical user names")
+	} else if filled > 0 {
+		log.Info().Msgf("backfilled canonical names of %d users", filled)
+	}
+
+	if config.AdminName != "" {
+		passwordPolicy, err := domain.PasswordPolicyForCharset(config.PasswordCharset)
+		if err != nil {
+			log.Fatal().Err(err).Msg("cannot select password policy")
+		}
+
+		if _, err := db.EnsureAdmin(context.Background(), store, config.AdminName, config.AdminPassword, passwordPolicy); err != nil {
+			log.Fatal().Err(err).Msg("cannot ensure admin user")
+		}
+	}
 
 	runGrpcServer(config, store)
 }